@@ -0,0 +1,293 @@
+// Package cluster is what `stressy coordinate` and `stressy agent` say to each
+// other: one run, pushed to every agent at once, and what each did, pulled back.
+// It is plain HTTP and JSON, so it runs between pods with nothing but a port
+// open and can be tested with every agent on localhost.
+//
+// An agent answers two requests. POST /run carries a Job and is answered once
+// the run is over, with the Result — a request as long as the run, so an
+// indefinite one is answered only once it is stopped, and a coordinator that
+// goes away takes its run with it rather than leaving a node loaded for nobody.
+// POST /stop stops the run under way, whose /run is then answered as usual.
+//
+// Nothing here authenticates anybody. An agent runs whatever load it is sent,
+// so it belongs on a network only the coordinator can reach.
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// maxJob is the largest /run body an agent reads. A Job is a few hundred bytes;
+// the bound is for whatever else arrives on the port.
+const maxJob = 1 << 20
+
+// ErrStopped is the cause an agent's run is cancelled with where POST /stop
+// ended it.
+var ErrStopped = errors.New("stopped by the coordinator")
+
+// Job is what a coordinator sends every agent: the run, and the instant to
+// start it at. One instant for all of them rather than "now" on each, which
+// would be as far apart as the requests happened to land; the agents' clocks are
+// what it is read against, so they want to agree.
+type Job struct {
+	Cfg     stress.Cfg
+	StartAt time.Time
+
+	// Show is what the agent prints of the run beyond what a run always
+	// prints.
+	Show Show
+}
+
+// Show is the figures a node prints on top of its usual lines: the command's
+// --cpu-time, --throttling, --steal, --thermal, --energy and --verbose. Each
+// changes what is printed of a run and nothing of the run, the Result carrying
+// every figure whatever Show says, so they travel beside the Cfg rather than in
+// it.
+type Show struct {
+	CPUTime, Throttling, Steal, Thermal, Energy, Verbose bool
+}
+
+// Agent is the handler `stressy agent` serves: one run at a time, by Run. The
+// zero value is not ready; Run has to be set.
+type Agent struct {
+	// Run runs job until it is over or ctx is done, waiting for its start
+	// first. An error fails the job, and goes back to the coordinator as the
+	// node's.
+	Run func(ctx context.Context, job Job) (stress.Result, error)
+
+	mu sync.Mutex
+
+	// cancel is the run under way's, and nil where there is none.
+	cancel context.CancelCauseFunc
+}
+
+// Handler is the agent's routes.
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /run", a.run)
+	mux.HandleFunc("POST /stop", a.stop)
+
+	return mux
+}
+
+// Stop stops the run under way, if there is one, with cause, and reports
+// whether there was.
+func (a *Agent) Stop(cause error) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel == nil {
+		return false
+	}
+
+	a.cancel(cause)
+
+	return true
+}
+
+func (a *Agent) run(w http.ResponseWriter, r *http.Request) {
+	var job Job
+
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxJob)).Decode(&job); err != nil {
+		http.Error(w, "want a job as JSON: "+err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// Under the request's context, so a coordinator that is killed, or whose
+	// node goes away, ends the run it asked for.
+	ctx, cancel := context.WithCancelCause(r.Context())
+	defer cancel(nil)
+
+	a.mu.Lock()
+	busy := a.cancel != nil
+
+	if !busy {
+		a.cancel = cancel
+	}
+	a.mu.Unlock()
+
+	// Two coordinators sharing an agent would each measure half a node.
+	if busy {
+		http.Error(w, "agent is already running a job", http.StatusConflict)
+
+		return
+	}
+
+	defer func() {
+		a.mu.Lock()
+		a.cancel = nil
+		a.mu.Unlock()
+	}()
+
+	res, err := a.Run(ctx, job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (a *Agent) stop(w http.ResponseWriter, _ *http.Request) {
+	if !a.Stop(ErrStopped) {
+		http.Error(w, "agent is running no job", http.StatusConflict)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Coordinator pushes one Job to every agent and collects what each did.
+type Coordinator struct {
+	// Agents are the agents' addresses, as host:port or as a URL.
+	Agents []string
+
+	// Client is what requests are made with; nil is http.DefaultClient.
+	Client *http.Client
+}
+
+// NodeResult is what one agent did: its Result, or the error that stands in for
+// one where the agent could not be reached, refused the job or failed it.
+type NodeResult struct {
+	Agent  string
+	Result stress.Result
+	Err    error
+}
+
+// Run sends job to every agent at once and returns what each did, in the order
+// of Agents, once every one has answered. ctx being done stops every agent
+// rather than abandoning them, and Run still waits for their answers: what a
+// stopped run did is the point of stopping it rather than walking away.
+func (c *Coordinator) Run(ctx context.Context, job Job) []NodeResult {
+	results := make([]NodeResult, len(c.Agents))
+
+	var wg sync.WaitGroup
+
+	// done is closed as each agent's /run is answered.
+	done := make([]chan struct{}, len(c.Agents))
+
+	for i, agent := range c.Agents {
+		done[i] = make(chan struct{})
+
+		wg.Go(func() {
+			defer close(done[i])
+
+			res, err := c.runOn(agent, job)
+			results[i] = NodeResult{Agent: agent, Result: res, Err: err}
+		})
+	}
+
+	answered := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(answered)
+	}()
+
+	select {
+	case <-answered:
+	case <-ctx.Done():
+		c.stop(done)
+		<-answered
+	}
+
+	return results
+}
+
+// runOn is one agent's /run, answered with its Result or the reason there is
+// none.
+func (c *Coordinator) runOn(agent string, job Job) (stress.Result, error) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return stress.Result{}, err
+	}
+
+	// Not under Run's context: that being done means stop, which /stop asks
+	// for, and the answer to this request is what the stop is for.
+	resp, err := c.client().Post(url(agent, "/run"), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return stress.Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxJob))
+
+		return stress.Result{}, fmt.Errorf("agent answered %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var res stress.Result
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return stress.Result{}, fmt.Errorf("agent answered with no result: %w", err)
+	}
+
+	return res, nil
+}
+
+// stopRetry is how long a coordinator waits before asking an agent to stop
+// again.
+const stopRetry = 100 * time.Millisecond
+
+// stop asks every agent to stop, all at once, and asks again until each has
+// taken the stop or answered its /run, whichever is first. An agent turns a
+// stop away while it has no run, which is also what it has where the stop
+// reaches it before the /run sent ahead of it does; taken as a no, that run
+// would go on unstopped, and with no --timeout, forever.
+func (c *Coordinator) stop(done []chan struct{}) {
+	var wg sync.WaitGroup
+
+	for i, agent := range c.Agents {
+		wg.Go(func() {
+			for {
+				resp, err := c.client().Post(url(agent, "/stop"), "", nil)
+				if err == nil {
+					resp.Body.Close()
+
+					if resp.StatusCode == http.StatusAccepted {
+						return
+					}
+				}
+
+				select {
+				case <-done[i]:
+					return
+				case <-time.After(stopRetry):
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+func (c *Coordinator) client() *http.Client {
+	if c.Client == nil {
+		return http.DefaultClient
+	}
+
+	return c.Client
+}
+
+// url is the address of path on agent, which is host:port where no scheme is
+// given.
+func url(agent, path string) string {
+	if !strings.Contains(agent, "://") {
+		agent = "http://" + agent
+	}
+
+	return strings.TrimSuffix(agent, "/") + path
+}
@@ -0,0 +1,205 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// newAgent serves an Agent that runs job with run, for as long as the test.
+func newAgent(t *testing.T, run func(context.Context, Job) (stress.Result, error)) (*Agent, string) {
+	t.Helper()
+
+	a := &Agent{Run: run}
+
+	srv := httptest.NewServer(a.Handler())
+	t.Cleanup(srv.Close)
+
+	return a, srv.URL
+}
+
+// TestCoordinatorCollectsEveryAgent: one job reaches every agent whole, and
+// each agent's Result, or its error, comes back in the order the agents were
+// given.
+func TestCoordinatorCollectsEveryAgent(t *testing.T) {
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	var (
+		mu   sync.Mutex
+		jobs []Job
+	)
+
+	counting := func(count uint64) func(context.Context, Job) (stress.Result, error) {
+		return func(_ context.Context, job Job) (stress.Result, error) {
+			mu.Lock()
+			jobs = append(jobs, job)
+			mu.Unlock()
+
+			return stress.Result{Count: count, Groups: []stress.GroupResult{{Stressor: job.Cfg.Stressor, Count: count}}}, nil
+		}
+	}
+
+	_, first := newAgent(t, counting(10))
+	_, second := newAgent(t, counting(20))
+	_, failing := newAgent(t, func(context.Context, Job) (stress.Result, error) {
+		return stress.Result{}, errors.New("no such stressor")
+	})
+
+	c := &Coordinator{Agents: []string{first, failing, strings.TrimPrefix(second, "http://")}}
+
+	nodes := c.Run(context.Background(), Job{Cfg: stress.Cfg{Workers: 2, Timeout: time.Second, Stressor: "cache"}, StartAt: start})
+
+	if len(nodes) != 3 {
+		t.Fatalf("Run() returned %d nodes, want 3", len(nodes))
+	}
+
+	if nodes[0].Err != nil || nodes[0].Result.Count != 10 {
+		t.Errorf("nodes[0] = %+v, want a Count of 10", nodes[0])
+	}
+
+	if nodes[1].Err == nil || !strings.Contains(nodes[1].Err.Error(), "no such stressor") {
+		t.Errorf("nodes[1].Err = %v, want the agent's error", nodes[1].Err)
+	}
+
+	// An address with no scheme is host:port.
+	if nodes[2].Err != nil || nodes[2].Result.Count != 20 || nodes[2].Agent != c.Agents[2] {
+		t.Errorf("nodes[2] = %+v, want a Count of 20 from %s", nodes[2], c.Agents[2])
+	}
+
+	for _, job := range jobs {
+		if job.Cfg.Workers != 2 || job.Cfg.Timeout != time.Second || job.Cfg.Stressor != "cache" || !job.StartAt.Equal(start) {
+			t.Errorf("an agent was sent %+v, want the job as given", job)
+		}
+	}
+}
+
+// TestAgentRunsOneJobAtATime: a second job while one runs is refused rather
+// than run beside it.
+func TestAgentRunsOneJobAtATime(t *testing.T) {
+	started := make(chan struct{})
+
+	a, addr := newAgent(t, func(ctx context.Context, _ Job) (stress.Result, error) {
+		close(started)
+		<-ctx.Done()
+
+		return stress.Result{Reason: stress.StopCanceled}, nil
+	})
+
+	c := &Coordinator{Agents: []string{addr}}
+
+	first := make(chan []NodeResult, 1)
+	go func() { first <- c.Run(context.Background(), Job{}) }()
+
+	<-started
+
+	if n := c.Run(context.Background(), Job{}); n[0].Err == nil || !strings.Contains(n[0].Err.Error(), "409") {
+		t.Errorf("a second job's error = %v, want a 409", n[0].Err)
+	}
+
+	if !a.Stop(ErrStopped) {
+		t.Fatal("Stop() = false with a job running, want true")
+	}
+
+	if n := <-first; n[0].Err != nil || n[0].Result.Reason != stress.StopCanceled {
+		t.Errorf("the stopped job = %+v, want its Result", n[0])
+	}
+
+	if a.Stop(ErrStopped) {
+		t.Error("Stop() = true with no job running, want false")
+	}
+}
+
+// TestCoordinatorStopsTheAgents: a coordinator whose context is done stops
+// every agent, with ErrStopped, and still collects what each did.
+func TestCoordinatorStopsTheAgents(t *testing.T) {
+	var started sync.WaitGroup
+
+	started.Add(2)
+
+	stopping := func(ctx context.Context, _ Job) (stress.Result, error) {
+		started.Done()
+		<-ctx.Done()
+
+		if !errors.Is(context.Cause(ctx), ErrStopped) {
+			return stress.Result{}, context.Cause(ctx)
+		}
+
+		return stress.Result{Reason: stress.StopCanceled, Count: 1}, nil
+	}
+
+	_, first := newAgent(t, stopping)
+	_, second := newAgent(t, stopping)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		started.Wait()
+		cancel()
+	}()
+
+	for _, n := range (&Coordinator{Agents: []string{first, second}}).Run(ctx, Job{}) {
+		if n.Err != nil || n.Result.Count != 1 {
+			t.Errorf("%s = %+v, want the Result of a run stopped by the coordinator", n.Agent, n)
+		}
+	}
+}
+
+// TestCoordinatorStopsARunNotYetBegun: a stop that reaches an agent before the
+// /run sent ahead of it is turned away, and the coordinator asks again until
+// the run it stops is there to be stopped.
+func TestCoordinatorStopsARunNotYetBegun(t *testing.T) {
+	a := &Agent{Run: func(ctx context.Context, _ Job) (stress.Result, error) {
+		<-ctx.Done()
+
+		return stress.Result{Reason: stress.StopCanceled, Count: 1}, nil
+	}}
+
+	// /run held back, so the stop arrives first.
+	agent := a.Handler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/run" {
+			time.Sleep(3 * stopRetry)
+		}
+
+		agent.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	nodes := make(chan []NodeResult, 1)
+	go func() { nodes <- (&Coordinator{Agents: []string{srv.URL}}).Run(ctx, Job{}) }()
+
+	select {
+	case n := <-nodes:
+		if n[0].Err != nil || n[0].Result.Count != 1 {
+			t.Errorf("%s = %+v, want the Result of a run stopped by the coordinator", n[0].Agent, n[0])
+		}
+	case <-time.After(5 * time.Second):
+		a.Stop(ErrStopped)
+		t.Fatal("Run() had not returned 5s after its context was done, want the agent stopped once its run began")
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.5:7070":         "http://10.0.0.5:7070/run",
+		"http://10.0.0.5:7070":  "http://10.0.0.5:7070/run",
+		"http://10.0.0.5:7070/": "http://10.0.0.5:7070/run",
+		"https://agent.example": "https://agent.example/run",
+	}
+
+	for agent, want := range tests {
+		if got := url(agent, "/run"); got != want {
+			t.Errorf("url(%q) = %q, want %q", agent, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package stressy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// burstClause is what --burst adds to the startup line: ", in bursts of 10s on
+// and 50s off", with how much each period may be off by where it has jitter,
+// or "" for a steady run.
+func (c Cfg) burstClause() string {
+	b := c.Burst
+	if b == (stress.Burst{}) {
+		return ""
+	}
+
+	clause := fmt.Sprintf(", in bursts of %s on and %s off", b.On, b.Off)
+	if b.Jitter > 0 {
+		clause += fmt.Sprintf(", each give or take %s", b.Jitter)
+	}
+
+	return clause
+}
+
+// turnMessage is the line a run with --burst prints as it turns off or on. An
+// off period holds the workers as a pause does, once they have finished the
+// unit each is on, and says so in the pause's words.
+func (c Cfg) turnMessage(ev stress.BurstEvent) string {
+	at := ev.Elapsed.Round(time.Millisecond)
+
+	if ev.On {
+		return fmt.Sprintf("Burst on at %s elapsed; the workers go on", at)
+	}
+
+	return fmt.Sprintf("Burst off at %s elapsed; every worker holds once it has finished the %s it is on", at, c.step())
+}
+
+// burstMessage is the line --burst adds under the summary: the time the run was
+// on, and the rate over it beside the overall one the summary gives, which the
+// off periods bring down. A mix gives each group's, in its own units.
+func burstMessage(r stress.Result) string {
+	b := r.Burst
+
+	rates := make([]string, len(r.Groups))
+	for i, g := range r.Groups {
+		s := describe(g.Stressor)
+		on := fmt.Sprintf("%.1f %s/s on against %.1f overall", rate(g.Count, b.OnTime), s.Units, g.Rate)
+
+		if len(r.Groups) > 1 {
+			on = g.Stressor + " " + on
+		}
+
+		rates[i] = on
+	}
+
+	return fmt.Sprintf(
+		"Bursts: %d on %s, %s on; %s",
+		b.Periods, plural(b.Periods, "period", "periods"), b.OnTime.Round(time.Millisecond), strings.Join(rates, ", "),
+	)
+}
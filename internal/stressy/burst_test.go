@@ -0,0 +1,78 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestBurstMessages(t *testing.T) {
+	burst := stress.Burst{On: 10 * time.Second, Off: 50 * time.Second}
+	jittered := stress.Burst{On: 10 * time.Second, Off: 50 * time.Second, Jitter: 5 * time.Second}
+	stats := &stress.BurstStats{Burst: burst, Periods: 5, OnTime: 50123 * time.Millisecond}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "startup", got: Cfg{Cfg: stress.Cfg{Timeout: 5 * time.Minute, Burst: burst}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 5m0s, in bursts of 10s on and 50s off"},
+		{name: "startup, jittered", got: Cfg{Cfg: stress.Cfg{Burst: jittered}}.startupMessage([]stress.GroupResult{group("bcrypt", 1)}), want: "Starting CPU stress test with 1 worker indefinitely, in bursts of 10s on and 50s off, each give or take 5s"},
+		{name: "startup, a mix", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute, Burst: burst, Mix: []stress.Group{{Stressor: "bcrypt", Workers: 2}, {Stressor: "gc", Workers: 1}}}}.startupMessage([]stress.GroupResult{group("bcrypt", 2), group("gc", 1)}), want: "Starting mixed stress test with 3 workers for 1m0s: 2 bcrypt, 1 gc, in bursts of 10s on and 50s off"},
+		{name: "off", got: Cfg{}.turnMessage(stress.BurstEvent{Elapsed: 10001234 * time.Microsecond}), want: "Burst off at 10.001s elapsed; every worker holds once it has finished the hash it is on"},
+		{name: "on", got: Cfg{}.turnMessage(stress.BurstEvent{On: true, Elapsed: time.Minute}), want: "Burst on at 1m0s elapsed; the workers go on"},
+		{
+			name: "summary",
+			got:  burstMessage(stress.Result{Groups: []stress.GroupResult{{Stressor: "bcrypt", Count: 500, Rate: 2.0}}, Burst: stats}),
+			want: "Bursts: 5 on periods, 50.123s on; 10.0 hashes/s on against 2.0 overall",
+		},
+		{
+			name: "summary, a mix",
+			got:  burstMessage(stress.Result{Groups: []stress.GroupResult{{Stressor: "bcrypt", Count: 500, Rate: 2.0}, {Stressor: "syscall", Count: 1002460, Rate: 4000}}, Burst: stats}),
+			want: "Bursts: 5 on periods, 50.123s on; bcrypt 10.0 hashes/s on against 2.0 overall, syscall 20000.0 calls/s on against 4000.0 overall",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunInBursts runs --burst from end to end: every turn is announced in
+// order, between the startup line and the shutdown, and the summary ends with
+// the on periods.
+func TestRunInBursts(t *testing.T) {
+	var out bytes.Buffer
+
+	c := Cfg{
+		Cfg: stress.Cfg{
+			Workers: 1, Timeout: 250 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"},
+			Burst: stress.Burst{On: 100 * time.Millisecond, Off: 100 * time.Millisecond},
+		},
+		Out: &out,
+	}
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	want := []string{"Starting contention stress test", "Burst off at ", "Burst on at ", "Timer expired"}
+	for i, w := range want {
+		if i >= len(lines) || !strings.HasPrefix(lines[i], w) {
+			t.Fatalf("Run() printed:\n%s\nwant line %d to start %q", out.String(), i, w)
+		}
+	}
+
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "Bursts: 2 on periods, ") {
+		t.Errorf("the last line = %q, want the on periods", last)
+	}
+}
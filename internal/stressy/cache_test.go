@@ -0,0 +1,61 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// TestRunReportsEveryWorkingSet runs two working sets through Run: a line each,
+// named as they were typed and carrying a latency.
+func TestRunReportsEveryWorkingSet(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 100 * time.Millisecond, Stressor: "cache", WorkingSets: []int{32 << 10, 1 << 20}}, Out: &buf}
+
+	if err := cfg.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"Starting cache stress test with 1 worker for 100ms, working sets 32KiB, 1MiB\n",
+		"waiting for every worker to finish the walk it is on...\n",
+		"\nWorking set 32KiB: ",
+		"\nWorking set 1MiB: ",
+		" ns/access)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Run() printed:\n%s\nwant it to contain %q", out, want)
+		}
+	}
+}
+
+// TestVariantMessageCarriesLatency covers the arithmetic: two workers doing a
+// million accesses in a second between them is two microseconds an access each.
+func TestVariantMessageCarriesLatency(t *testing.T) {
+	g := stress.GroupResult{Stressor: "cache", Workers: 2}
+
+	tests := []struct {
+		name  string
+		count uint64
+		spent time.Duration
+		want  string
+	}{
+		{name: "an access every two microseconds a worker", count: 1000000, spent: time.Second, want: "Working set 64MiB: 1000000 accesses in 1s (1000000.0 accesses/s, 2000.0 ns/access)"},
+		// No access is no latency to quote, rather than a division by zero.
+		{name: "nothing walked", count: 0, spent: 0, want: "Working set 64MiB: 0 accesses in 0s (0.0 accesses/s, 0.0 ns/access)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := variantMessage(g, stress.VariantResult{Name: "64MiB", Count: tt.count, Spent: tt.spent}); got != tt.want {
+				t.Errorf("variantMessage(%d, %s) = %q, want %q", tt.count, tt.spent, got, tt.want)
+			}
+		})
+	}
+}
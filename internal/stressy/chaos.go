@@ -0,0 +1,34 @@
+package stressy
+
+import (
+	"fmt"
+	"time"
+)
+
+// chaosClause is what --chaos adds to the startup line: the seed first, which
+// is what a run is replayed from, then the bounds it draws within, or "" for a
+// run without chaos.
+func (c Cfg) chaosClause() string {
+	ch := c.Chaos
+	if ch.MaxEvery == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		", in chaos from seed %d: %s workers at %s duty, changing every %s",
+		ch.Seed,
+		spanWords(fmt.Sprint(ch.MinWorkers), fmt.Sprint(ch.MaxWorkers)),
+		spanWords(fmt.Sprintf("%.0f%%", ch.MinDuty*100), fmt.Sprintf("%.0f%%", ch.MaxDuty*100)),
+		spanWords(ch.MinEvery.Round(time.Millisecond).String(), ch.MaxEvery.Round(time.Millisecond).String()),
+	)
+}
+
+// spanWords is a range as the startup line says it: "1 to 4", or "4" where it
+// is one value.
+func spanWords(low, high string) string {
+	if low == high {
+		return low
+	}
+
+	return low + " to " + high
+}
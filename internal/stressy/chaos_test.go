@@ -0,0 +1,120 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestChaosMessages(t *testing.T) {
+	tests := []struct {
+		name  string
+		chaos stress.Chaos
+		want  string
+	}{
+		{name: "none", want: "Starting CPU stress test with 4 workers for 1m0s"},
+		{
+			name:  "ranges",
+			chaos: stress.Chaos{MinWorkers: 1, MaxWorkers: 4, MinDuty: 0.2, MaxDuty: 1, MinEvery: 5 * time.Second, MaxEvery: 30 * time.Second, Seed: 42},
+			want:  "Starting CPU stress test with 4 workers for 1m0s, in chaos from seed 42: 1 to 4 workers at 20% to 100% duty, changing every 5s to 30s",
+		},
+		{
+			name:  "one value each",
+			chaos: stress.Chaos{MinWorkers: 4, MaxWorkers: 4, MinDuty: 0.5, MaxDuty: 0.5, MinEvery: time.Second, MaxEvery: time.Second},
+			want:  "Starting CPU stress test with 4 workers for 1m0s, in chaos from seed 0: 4 workers at 50% duty, changing every 1s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Cfg{Cfg: stress.Cfg{Timeout: time.Minute, Chaos: tt.chaos}}
+
+			if got := c.startupMessage([]stress.GroupResult{group("bcrypt", 4)}); got != tt.want {
+				t.Errorf("startupMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestChaosIsSeeded covers the seed the command gives a --chaos run: the one
+// typed, in either order, or one picked at random where none was, and none
+// at all without --chaos.
+func TestChaosIsSeeded(t *testing.T) {
+	const bounds = "workers=1-2,duty=50%-100%,every=1s-5s"
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantSeed uint64
+		random   bool
+	}{
+		{name: "typed after", args: []string{"--chaos", bounds, "--seed", "42"}, wantSeed: 42},
+		{name: "typed before", args: []string{"--seed", "42", "--chaos", bounds}, wantSeed: 42},
+		// 0 is a seed like any other, once typed.
+		{name: "typed as 0", args: []string{"--chaos", bounds, "--seed", "0"}, wantSeed: 0},
+		{name: "picked", args: []string{"--chaos", bounds}, random: true},
+		{name: "no chaos", args: []string{"-t", "1m"}, wantSeed: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seeds := map[uint64]bool{}
+
+			// Twice, so a seed picked at random is seen to be picked afresh.
+			for range 2 {
+				var cfg Cfg
+				cmd := newTestCmd(t, &cfg)
+
+				if err := cmd.execute(append([]string{"-w", "2", "-t", "1m"}, tt.args...)); err != nil {
+					t.Fatalf("execute(%q) error = %v, want nil", tt.args, err)
+				}
+
+				seeds[cfg.Chaos.Seed] = true
+
+				if !tt.random && cfg.Chaos.Seed != tt.wantSeed {
+					t.Errorf("execute(%q) seed = %d, want %d", tt.args, cfg.Chaos.Seed, tt.wantSeed)
+				}
+			}
+
+			if tt.random && len(seeds) != 2 {
+				t.Errorf("execute(%q) twice picked seeds %v, want two different ones", tt.args, seeds)
+			}
+		})
+	}
+}
+
+// TestRunInChaos runs --chaos from end to end: the startup line gives the
+// seed, and every change is announced before the shutdown.
+func TestRunInChaos(t *testing.T) {
+	var out bytes.Buffer
+
+	c := Cfg{
+		Cfg: stress.Cfg{
+			Workers: 2, Timeout: 300 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"},
+			Chaos: stress.Chaos{MaxWorkers: 2, MaxDuty: 1, MinEvery: 100 * time.Millisecond, MaxEvery: 100 * time.Millisecond, Seed: 7},
+		},
+		Out: &out,
+	}
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	want := []string{
+		"Starting contention stress test with 2 workers for 300ms, mode atomic, in chaos from seed 7: 0 to 2 workers at 0% to 100% duty, changing every 100ms",
+		"Segment 1 of 3 at 0s elapsed: ",
+		"Segment 2 of 3 at ",
+		"Segment 3 of 3 at ",
+		"Timer expired",
+	}
+	for i, w := range want {
+		if i >= len(lines) || !strings.HasPrefix(lines[i], w) {
+			t.Fatalf("Run() printed:\n%s\nwant line %d to start %q", out.String(), i, w)
+		}
+	}
+}
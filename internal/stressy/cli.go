@@ -5,16 +5,21 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"os"
 	"strings"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+	"github.com/felipeneuwald/stressy/stress"
 )
 
 // name is what stressy calls itself in the lines it prints about itself.
 const name = "stressy"
 
-// useLine is the one line under `Usage:`. stressy has no subcommands and takes
-// no operands, so the whole of its grammar is its flags.
-const useLine = "  " + name + " [flags]"
+// useLine is what goes under `Usage:` for a bare stressy. It takes no
+// operands, so the whole of its grammar is its flags, and the two subcommands
+// are named under it because --help is where an operator finds out they exist.
+const useLine = "  " + name + " [flags]\n" + agentUseLine + "\n" + coordinateUseLine
 
 // helpWidth is the column the blocks usage renders are wrapped and written to
 // fit. Fixed rather than measured: asking the terminal how wide it is means
@@ -50,6 +55,9 @@ const examplesBlock = `  # One worker until interrupted
   # A progress line every 30 seconds; a run prints nothing without one
   stressy -t 30m -r 30s
 
+  # Every node loading over one window, however far apart each was started
+  stressy -w 4 --start-at 2026-01-02T15:00:00Z --until 2026-01-02T15:10:00Z
+
   # In a container, as many workers as the limit pays for
   docker run --rm --cpus 2 ghcr.io/felipeneuwald/stressy:latest -w 2 -t 30s`
 
@@ -58,7 +66,7 @@ const examplesBlock = `  # One worker until interrupted
 // what leaves `--help` unable to disagree with the flags the binary actually has.
 type setting struct {
 	long        string
-	short       string
+	short       string // empty for a flag with no one-letter spelling
 	placeholder string // the type printed after the name; empty for a bool
 	usage       string
 	def         string // the default as of registration; empty where none prints
@@ -85,6 +93,18 @@ type command struct {
 	fs    *flag.FlagSet
 	flags []setting
 
+	// use, about and examples are the lines --help prints for this command:
+	// what goes under `Usage:`, the description above it and the examples
+	// under it. One set per command, so `stressy agent --help` describes the
+	// agent rather than the run.
+	use      string
+	about    string
+	examples string
+
+	// check is what dispatch holds a parsed command line to before it runs:
+	// the values it carries, for range, and how they go together.
+	check func() error
+
 	// version is what `--version` prints, resolved by newCmd. A field rather
 	// than a package-level variable Main overwrites: build info was read at
 	// init and read again to throw that first result away, and what one command
@@ -110,13 +130,18 @@ type command struct {
 // included.
 func newCmd(cfg *Cfg, injected string) *command {
 	c := &command{
-		cfg:     cfg,
-		fs:      flag.NewFlagSet(name, flag.ContinueOnError),
-		version: resolveVersion(injected, buildInfo()),
-		stdout:  os.Stdout,
-		stderr:  os.Stderr,
+		cfg:      cfg,
+		fs:       flag.NewFlagSet(name, flag.ContinueOnError),
+		use:      useLine,
+		about:    description,
+		examples: examplesBlock,
+		version:  resolveVersion(injected, buildInfo()),
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
 	}
 
+	c.check = c.checkRun
+
 	// The run prints through the command's stdout rather than through a seam of
 	// its own. The two agreed only because both defaulted to os.Stdout, so
 	// redirecting the command left the run still printing to the terminal, and
@@ -148,11 +173,35 @@ func newCmd(cfg *Cfg, injected string) *command {
 	// Var rather than DurationVar for the same reason: the stock parser rejects
 	// a bad duration as a bare "parse error". See durationValue.
 	timeout := newDurationValue(&cfg.Timeout)
+	count := newCountValue(&cfg.Count)
+	targetRate := newRateValue(&cfg.TargetRate)
+	burst := newBurstValue(&cfg.Burst)
+	nice := newNiceValue(&cfg.Nice)
+	sched := newSchedValue(&cfg.Sched)
+	maxTemp := newTempValue(&cfg.MaxTemp)
+	chaos := newChaosValue(&cfg.Chaos)
+	seed := newSeedValue(&cfg.Chaos.Seed)
+	replay := newPathValue(&cfg.Replay)
+	replaySpeed := newSpeedValue(&cfg.ReplaySpeed)
 
 	// The same durationValue as --timeout, so both spell a duration alike.
 	// The usage text is ASCII, like every other string this program prints.
 	report := newDurationValue(&cfg.Report)
 
+	// Named rather than left to "" in the Cfg, so the help line says which load
+	// a bare `stressy` puts on.
+	stressor := newStressorValue(describe("").Name, &cfg.Stressor)
+	modes := newListValue(&cfg.Modes)
+	workingSets := newSizesValue(&cfg.WorkingSets)
+	allocRate := newSizeValue(&cfg.AllocRate, "unlimited", "/s")
+	heapTarget := newSizeValue(&cfg.HeapTarget, "auto", "")
+	latencyProbe := newDurationValue(&cfg.LatencyProbe)
+	stallTimeout := newDurationValue(&cfg.StallTimeout)
+	mix := newMixValue(&cfg.Mix)
+	startAt := newTimeValue(&cfg.StartAt)
+	until := newTimeValue(&cfg.Until)
+	tiers := sizesValue(describe("cache").WorkingSets)
+
 	// Alphabetical, which is the order the Flags block prints them in; nothing
 	// sorts this at render time, so `sort` stays out of the build graph.
 	//
@@ -167,10 +216,102 @@ func newCmd(cfg *Cfg, injected string) *command {
 	// What the texts do say is what the value 0 means, which the parenthesis
 	// does not.
 	c.flags = []setting{
+		{
+			long: "alloc-rate", placeholder: allocRate.Type(), def: allocRate.String(),
+			usage: "how fast the gc stressor's workers allocate between them, as a size a second such as 256MiB/s",
+			value: allocRate,
+		},
+		{
+			long: "burst", placeholder: burst.Type(),
+			usage: "run the workers in bursts, as on and off periods such as on=10s,off=50s, holding every one of them through each off period, " +
+				"with jitter=5s to lengthen or shorten every period by up to that much; each turn is announced, and the summary gives the rate while on beside the overall one",
+			value: burst,
+		},
+		{
+			long: "chaos", placeholder: chaos.Type(),
+			usage: "change how many workers run and how much of the time at random, within workers=, duty= and every= ranges such as workers=1-4,duty=20%-100%,every=5s-30s; " +
+				"each change is announced, and the startup line gives the seed that replays them; needs --timeout or --until",
+			value: chaos,
+		},
+		{
+			long: "count", placeholder: count.Type(), def: count.String(),
+			usage: "how many units to do between the workers before the run ends, such as 1000 hashes, for the time the machine takes over a fixed amount of work; " +
+				"--timeout or --until, where given, is a cap, and 0 does not count",
+			value: count,
+		},
+		{
+			long:  "cpu-time",
+			usage: "add the CPU time the process was given to the progress lines and the summary, with the cores it came to on average and what share of the workers those are, which a CPU quota holds down",
+			value: newBoolValue(&cfg.CPUTime),
+		},
+		{
+			long: "energy",
+			usage: "add the energy the CPU packages drew, from RAPL under /sys/class/powercap, to the summary, in joules, average watts and units per joule; " +
+				"a machine without RAPL, or a user it will not let read it, adds nothing",
+			value: newBoolValue(&cfg.Energy),
+		},
+		{
+			long: "heap-target", placeholder: heapTarget.Type(), def: heapTarget.String(),
+			usage: "the live heap the gc stressor holds while it allocates, as a size such as 256MiB; auto holds " +
+				units.FormatSize(stress.DefaultHeapTarget),
+			value: heapTarget,
+		},
 		{
 			long: "help", short: "h", usage: "help for " + name,
 			value: newBoolValue(&c.wantHelp),
 		},
+		{
+			long:  "latency-locked",
+			usage: "pin the latency probe to an OS thread of its own, so a wakeup never waits for the Go runtime to find it one",
+			value: newBoolValue(&cfg.LatencyLocked),
+		},
+		{
+			long: "latency-probe", placeholder: latencyProbe.Type(), def: latencyProbe.String(),
+			usage: "how often a goroutine beside the workers sleeps and wakes to measure how late it was woken, as a duration such as 1ms, no shorter than " +
+				units.FormatDuration(stress.LatencyFloor) + "; min, avg, p99 and max go in every progress line and the summary, and 0 runs no probe",
+			value: latencyProbe,
+		},
+		{
+			long: "max-temp", placeholder: maxTemp.Type(),
+			usage: "end the run, with a line and an exit code of its own, once any thermal zone under /sys reaches this temperature, " +
+				"such as 90C, read every second; a machine with no thermal zone to read refuses it",
+			value: maxTemp,
+		},
+		{
+			long: "mix", placeholder: mix.Type(),
+			usage: "several stressors at once, each with workers of its own, as stressor:workers pairs such as bcrypt:4,cache:2,syscall:1; " +
+				"each is summarised on a line of its own, and a mix takes the place of --stressor and --workers",
+			value: mix,
+		},
+		{
+			long: "mode", short: "m", placeholder: modes.Type(),
+			usage: "the modes of the stressor to run, comma-separated, taking turns a second at a time and reported each on a line of its own; contention has " +
+				strings.Join(describe("contention").Modes, ", ") + ", syscall has " + strings.Join(describe("syscall").Modes, ", ") + ", and empty runs them all",
+			value: modes,
+		},
+		{
+			long: "nice", placeholder: nice.Type(), def: nice.String(),
+			usage: "the nice value, from -20 to 19, every worker's thread runs at, on Linux, such as 19 for a filler load that gives way to anything else; " +
+				"below 0 needs CAP_SYS_NICE, and 0 leaves the workers at the process's",
+			value: nice,
+		},
+		{
+			long: "rate", placeholder: targetRate.Type(), def: targetRate.String(),
+			usage: "start units on a schedule of their own at a rate such as 50/s, 300/m or 10/h, rather than each as soon as a worker is free, so a node that cannot keep up builds a backlog; " +
+				"a unit is a hash, or a batch or walk for the other stressors, and 0 runs closed loop",
+			value: targetRate,
+		},
+		{
+			long: "replay", placeholder: replay.Type(),
+			usage: "follow a trace of CPU utilisation, a CSV of time and percent of the workers or cores per row such as 2026-01-02T15:04:00Z,42.5%, " +
+				"by how many workers run and how much of the time; the run lasts as long as the trace, and the summary compares the load asked for with the load offered in each sample",
+			value: replay,
+		},
+		{
+			long: "replay-speed", placeholder: replaySpeed.Type(), def: replaySpeed.String(),
+			usage: "how many times faster than it was recorded to replay the trace, such as 10x for a day in 2h24m",
+			value: replaySpeed,
+		},
 		{
 			long: "report", short: "r", placeholder: report.Type(), def: report.String(),
 			// Both bounds are named here because both reject a command line, and
@@ -179,31 +320,119 @@ func newCmd(cfg *Cfg, injected string) *command {
 				reportFloor.String() + " and, on a bounded run, no longer than --timeout; 0 prints none",
 			value: report,
 		},
+		{
+			long: "sched", placeholder: sched.Type(),
+			usage: "the scheduling policy every worker's thread runs under, on Linux, one of " + strings.Join(stress.Scheds(), ", ") +
+				"; idle runs the workers only when nothing else wants the CPU",
+			value: sched,
+		},
+		{
+			long: "seed", placeholder: seed.Type(),
+			usage: "the seed --chaos draws its changes from, to replay a run's from the seed its startup line gave; without it, one is picked at random",
+			value: seed,
+		},
+		{
+			long:  "steal",
+			usage: "add the share of the machine's CPU time a hypervisor stole and I/O waited on, from /proc/stat, to the progress lines, each since the line before, and to the summary",
+			value: newBoolValue(&cfg.Steal),
+		},
+		{
+			long: "stall-timeout", placeholder: stallTimeout.Type(), def: stallTimeout.String(),
+			usage: "end the run, with a line naming the worker and an exit code of its own, once any worker has been on one unit this long, as a duration such as 30s, no shorter than " +
+				stallFloor.String() + "; 0 watches for none",
+			value: stallTimeout,
+		},
+		{
+			long: "start-at", placeholder: startAt.Type(),
+			usage: "when to start, as an RFC 3339 time such as 2026-01-02T15:04:05Z, waiting until then with a countdown line; " +
+				"nodes given the same instant start together however far apart they were started",
+			value: startAt,
+		},
+		{
+			long: "stressor", short: "s", placeholder: stressor.Type(), def: stressor.String(),
+			usage: "the load the workers put on, one of " + stressorNames() +
+				"; bcrypt hashes, contention has the workers fight over shared memory, cache walks a working set, gc churns the heap, and syscall calls into the kernel",
+			value: stressor,
+		},
+		{
+			long:  "thermal",
+			usage: "add the lowest, average and highest CPU frequency and the hottest thermal zone, sampled from /sys on every progress line, to the progress lines and the summary",
+			value: newBoolValue(&cfg.Thermal),
+		},
+		{
+			long:  "throttling",
+			usage: "add what the cgroup's CPU quota throttled the run by and the machine's CPU pressure over it to the summary, from cpu.stat and /proc/pressure/cpu",
+			value: newBoolValue(&cfg.Throttling),
+		},
 		{
 			long: "timeout", short: "t", placeholder: timeout.Type(), def: timeout.String(),
 			usage: "how long to run the stress test, as a duration such as 30s or 5m; 0 runs until interrupted",
 			value: timeout,
 		},
+		{
+			long: "until", placeholder: until.Type(),
+			usage: "when to stop, as an RFC 3339 time such as 2026-01-02T15:10:00Z, in place of --timeout; nodes given the same instant stop together",
+			value: until,
+		},
+		{
+			long:  "verbose",
+			usage: "add what the machine is under the startup line: its CPU model, cores and threads, kernel, Go, GOMAXPROCS and cgroup limits, and for coordinate, each node's under the table",
+			value: newBoolValue(&cfg.Verbose),
+		},
 		{
 			long: "version", short: "v", usage: "version for " + name,
 			value: newBoolValue(&c.wantVersion),
 		},
+		{
+			long: "working-set", placeholder: workingSets.Type(), def: workingSets.String(),
+			usage: "the sizes the cache stressor walks a ring of, comma-separated, such as 32KiB,1MiB,64MiB, each reported with its latency; auto walks " +
+				tiers.String(),
+			value: workingSets,
+		},
 		{
 			long: "workers", short: "w", placeholder: workers.Type(), def: workers.String(),
-			usage: "number of parallel workers for CPU stress testing; nothing is inferred from the machine, so raise it to load more than one CPU",
+			usage: "number of parallel workers for CPU stress testing; nothing is inferred from the machine, so raise it to load more than a CPU",
 			value: workers,
 		},
 	}
 
-	// Twice over one Value, because the flag package knows no shorthands and
-	// draws no distinction between one dash and two: this is what makes `-w 4`,
-	// `--workers 4`, `-workers 4` and `--w 4` all reach the same setting.
+	c.register()
+
+	return c
+}
+
+// register puts every row of the flag table on the command's flag set.
+//
+// Twice over one Value, because the flag package knows no shorthands and draws
+// no distinction between one dash and two: this is what makes `-w 4`,
+// `--workers 4`, `-workers 4` and `--w 4` all reach the same setting. A flag an
+// operator types rarely enough has no shorthand, rather than one nobody could
+// guess.
+func (c *command) register() {
 	for _, s := range c.flags {
 		c.fs.Var(s.value, s.long, s.usage)
-		c.fs.Var(s.value, s.short, s.usage)
+
+		if s.short != "" {
+			c.fs.Var(s.value, s.short, s.usage)
+		}
 	}
+}
 
-	return c
+// route picks the command args are for: a subcommand where the first of them
+// names one, and the run otherwise. It returns the command and the args it is
+// to parse, which are the rest. Only the first argument is looked at, so a
+// flag's value spelled like a subcommand is never taken for one.
+func route(args []string, injected string) (*command, []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "agent":
+			return newAgentCmd(&Cfg{}, injected), args[1:]
+		case "coordinate":
+			return newCoordinateCmd(&Cfg{}, injected), args[1:]
+		}
+	}
+
+	return newCmd(&Cfg{}, injected), args
 }
 
 // execute runs the command line and returns what went wrong, having already
@@ -217,9 +446,13 @@ func (c *command) execute(args []string) error {
 
 	// A signal-shortened run is reported by its exit code, not as an error: Run
 	// has already printed the shutdown line, and printing this would report the
-	// same shutdown twice.
-	var sigErr *SignalError
-	if errors.As(err, &sigErr) {
+	// same shutdown twice. So is a run its --max-temp or --stall-timeout ended.
+	var (
+		sigErr  *SignalError
+		haltErr *HaltError
+	)
+
+	if errors.As(err, &sigErr) || errors.As(err, &haltErr) {
 		return err
 	}
 
@@ -259,11 +492,11 @@ func (c *command) dispatch(args []string) error {
 	// than with the help it asked for; a command line stressy cannot read is
 	// not one it can obey a flag from.
 	if rest := c.fs.Args(); len(rest) > 0 {
-		return &usageError{fmt.Errorf("unexpected argument %q: stressy takes flags only", rest[0])}
+		return &usageError{fmt.Errorf("unexpected argument %q: %s takes flags only", rest[0], c.fs.Name())}
 	}
 
 	if c.wantHelp {
-		writef(c.stdout, "%s\n\n%s", description, c.usage(withExamples))
+		writef(c.stdout, "%s\n\n%s", c.about, c.usage(withExamples))
 
 		return nil
 	}
@@ -274,15 +507,70 @@ func (c *command) dispatch(args []string) error {
 		return nil
 	}
 
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	return c.run(c.cfg)
+}
+
+// checkRun is the check of a command line that configures a run.
+func (c *command) checkRun() error {
+	// A mix names its stressors and their workers, so a --stressor or a
+	// --workers typed beside one is a run the operator will not get. Caught
+	// here rather than in validate, which cannot tell a typed -w 1 from the
+	// default.
+	if len(c.cfg.Mix) > 0 {
+		var clash string
+
+		c.fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "s", "stressor", "w", "workers":
+				clash = f.Name
+			}
+		})
+
+		if clash != "" {
+			return fmt.Errorf("mix gives every stressor its own workers, so it takes no -%s beside it", clash)
+		}
+	}
+
+	if err := c.seedChaos(); err != nil {
+		return err
+	}
+
+	// Read here, before the range checks, so a trace a run cannot keep is
+	// turned away with them, and one that cannot be read before anything runs.
+	if err := c.cfg.loadReplay(); err != nil {
+		return err
+	}
+
 	// A value the parser accepted can still be out of range. Deliberately not a
 	// usageError: the flag list answers nothing about `-w 0`, and #17a is that a
 	// runtime error prints one line. Run re-applies the same rules for callers
 	// that never come through this command.
-	if err := c.cfg.validate(); err != nil {
-		return err
+	return c.cfg.validate()
+}
+
+// seedChaos picks the seed a --chaos run without a --seed draws from, here
+// rather than in Run so the startup line and, for stressy coordinate, every
+// agent have the one picked. A --seed without --chaos is a run that would not
+// change, and is turned away.
+func (c *command) seedChaos() error {
+	var seeded bool
+
+	c.fs.Visit(func(f *flag.Flag) { seeded = seeded || f.Name == "seed" })
+
+	switch chaos := c.cfg.Chaos; {
+	case chaos == (stress.Chaos{Seed: chaos.Seed}):
+		if seeded {
+			return errors.New("seed is what --chaos draws its changes from, so it takes a --chaos beside it")
+		}
+	case !seeded:
+		c.cfg.Chaos.Seed = rand.Uint64()
 	}
 
-	return c.run(c.cfg)
+	return nil
 }
 
 // writef prints to one of the command's two streams, or to Cfg.Out, and drops
@@ -311,11 +599,11 @@ func (c *command) usage(examples bool) string {
 	var b strings.Builder
 
 	b.WriteString("Usage:\n")
-	b.WriteString(useLine)
+	b.WriteString(c.use)
 
 	if examples {
 		b.WriteString("\n\nExamples:\n")
-		b.WriteString(examplesBlock)
+		b.WriteString(c.examples)
 	}
 
 	b.WriteString("\n\nFlags:\n")
@@ -336,7 +624,13 @@ func (c *command) writeFlags(b *strings.Builder) {
 	var width int
 
 	for i, s := range c.flags {
-		prefixes[i] = "  -" + s.short + ", --" + s.long
+		// A row with no shorthand keeps its long name in the column the others
+		// have theirs in, so the long names still read as one list.
+		prefixes[i] = "      --" + s.long
+		if s.short != "" {
+			prefixes[i] = "  -" + s.short + ", --" + s.long
+		}
+
 		if s.placeholder != "" {
 			prefixes[i] += " " + s.placeholder
 		}
@@ -347,8 +641,9 @@ func (c *command) writeFlags(b *strings.Builder) {
 	for i, s := range c.flags {
 		usage := s.usage
 
-		// --help and --version have no default worth printing; the other three
-		// print theirs even when it is the zero value, `(default 0s)` included.
+		// --help, --version, --mode and --latency-locked have no default worth
+		// printing; the others print theirs even when it is the zero value,
+		// `(default 0s)` included.
 		// Wrapped with the description rather than after it, so a default at the
 		// end of a full line moves down instead of hanging past the margin.
 		if s.def != "" {
@@ -408,11 +703,38 @@ func wrapText(text string, width int) []string {
 	return lines
 }
 
+// execChild is the one argument stressy is run with as the exec mode's process,
+// which Main returns from before it reads anything else. Not a flag: nothing
+// lists it, and nobody has a reason to type it.
+const execChild = "--exec-child"
+
+// selfExec is the stress.Cfg.Exec a run of the command has: this binary, as
+// execChild, so the exec mode costs a process starting up and not a program,
+// and needs nothing a FROM scratch image does not have. nil, which is true(1)
+// from the PATH, where the OS cannot say where the binary is.
+func selfExec() []string {
+	self, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+
+	return []string{self, execChild}
+}
+
 // Main runs stressy and returns the code the process is to exit with. injected
 // is the version stamped into release binaries, which the root main.go declares
 // because .goreleaser.yaml stamps it as `main.injected`.
 func Main(injected string) int {
-	err := newCmd(&Cfg{}, injected).execute(os.Args[1:])
+	// The syscall stressor's exec mode, run as selfExec has it: a process
+	// that starts, and nothing more.
+	if len(os.Args) == 2 && os.Args[1] == execChild {
+		return 0
+	}
+
+	cmd, args := route(os.Args[1:], injected)
+	cmd.cfg.Exec = selfExec()
+
+	err := cmd.execute(args)
 	if err == nil {
 		return 0
 	}
@@ -425,6 +747,14 @@ func Main(injected string) int {
 		return sig.ExitCode()
 	}
 
+	// A run a limit of its own ended exits with that limit's code, which is
+	// what tells a lab's scheduler the board got too hot rather than that the
+	// run failed. execute has silenced this one too.
+	var halt *HaltError
+	if errors.As(err, &halt) {
+		return halt.ExitCode()
+	}
+
 	// execute has already printed the error.
 	return 1
 }
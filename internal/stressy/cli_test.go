@@ -2,6 +2,7 @@ package stressy
 
 import (
 	"bytes"
+	"cmp"
 	"errors"
 	"io"
 	"slices"
@@ -9,6 +10,8 @@ import (
 	"testing"
 	"time"
 	"unicode/utf8"
+
+	"github.com/felipeneuwald/stressy/stress"
 )
 
 // newTestCmd builds the real command with the stress test stubbed out and its
@@ -75,6 +78,35 @@ func TestFlagRegistration(t *testing.T) {
 		{name: "timeout", shorthand: "t", placeholder: "duration", def: "0s", wantUsage: []string{"duration", "5m"}},
 		// Both bounds are stated where a command line is typed from (#114, #115).
 		{name: "report", shorthand: "r", placeholder: "duration", def: "0s", wantUsage: []string{"duration", "5m", "no shorter than 1s", "no longer than --timeout"}},
+		// Every stressor, named where a command line is typed from.
+		{name: "stressor", shorthand: "s", placeholder: "name", def: "bcrypt", wantUsage: []string{"bcrypt, contention, cache, gc, syscall"}},
+		{name: "mode", shorthand: "m", placeholder: "list", wantUsage: []string{"atomic, unpadded, padded, mutex", "getpid", "exec", "empty runs them all"}},
+		{name: "working-set", placeholder: "sizes", def: "auto", wantUsage: []string{"32KiB,1MiB,64MiB", "auto walks 16KiB,512KiB,8MiB,128MiB"}},
+		{name: "alloc-rate", placeholder: "rate", def: "unlimited", wantUsage: []string{"gc stressor", "256MiB/s"}},
+		{name: "heap-target", placeholder: "size", def: "auto", wantUsage: []string{"gc stressor", "auto holds 64MiB"}},
+		// The floor is said in ASCII, as every line stressy prints is.
+		{name: "latency-probe", placeholder: "duration", def: "0s", wantUsage: []string{"no shorter than 100us", "p99", "0 runs no probe"}},
+		{name: "latency-locked", wantUsage: []string{"OS thread"}},
+		{name: "verbose", wantUsage: []string{"CPU model", "GOMAXPROCS", "cgroup limits", "each node's"}},
+		{name: "energy", wantUsage: []string{"RAPL", "/sys/class/powercap", "joules", "adds nothing"}},
+		{name: "cpu-time", wantUsage: []string{"CPU time the process was given", "CPU quota"}},
+		{name: "throttling", wantUsage: []string{"cgroup's CPU quota", "CPU pressure", "cpu.stat"}},
+		{name: "steal", wantUsage: []string{"hypervisor stole", "/proc/stat", "since the line before"}},
+		{name: "thermal", wantUsage: []string{"CPU frequency", "thermal zone", "/sys"}},
+		{name: "stall-timeout", placeholder: "duration", def: "0s", wantUsage: []string{"naming the worker", "exit code of its own", "30s", "no shorter than 1s", "0 watches for none"}},
+		{name: "max-temp", placeholder: "temp", wantUsage: []string{"exit code of its own", "90C", "thermal zone"}},
+		{name: "mix", placeholder: "groups", wantUsage: []string{"bcrypt:4,cache:2", "takes the place of --stressor and --workers"}},
+		{name: "start-at", placeholder: "time", wantUsage: []string{"RFC 3339", "countdown"}},
+		{name: "until", placeholder: "time", wantUsage: []string{"RFC 3339", "in place of --timeout"}},
+		{name: "burst", placeholder: "periods", wantUsage: []string{"on=10s,off=50s", "jitter=5s", "rate while on"}},
+		{name: "replay", placeholder: "file", wantUsage: []string{"2026-01-02T15:04:00Z,42.5%", "as long as the trace", "asked for with the load offered"}},
+		{name: "replay-speed", placeholder: "speed", def: "1x", wantUsage: []string{"10x"}},
+		{name: "rate", placeholder: "rate", def: "0", wantUsage: []string{"50/s, 300/m or 10/h", "backlog", "0 runs closed loop"}},
+		{name: "chaos", placeholder: "bounds", wantUsage: []string{"workers=1-4,duty=20%-100%,every=5s-30s", "the seed that replays them", "needs --timeout or --until"}},
+		{name: "seed", placeholder: "int", wantUsage: []string{"--chaos", "picked at random"}},
+		{name: "nice", placeholder: "int", def: "0", wantUsage: []string{"-20 to 19", "CAP_SYS_NICE", "0 leaves the workers"}},
+		{name: "sched", placeholder: "policy", wantUsage: []string{"other, batch, idle", "nothing else wants the CPU"}},
+		{name: "count", placeholder: "int", def: "0", wantUsage: []string{"fixed amount of work", "is a cap", "0 does not count"}},
 	}
 
 	var cfg Cfg
@@ -142,6 +174,11 @@ func TestBothSpellingsShareOneValue(t *testing.T) {
 	cmd := newTestCmd(t, &cfg)
 
 	for _, s := range cmd.flags {
+		// A flag with no shorthand has one spelling, and nothing to disagree with.
+		if s.short == "" {
+			continue
+		}
+
 		long, short := cmd.fs.Lookup(s.long), cmd.fs.Lookup(s.short)
 		if long == nil || short == nil {
 			t.Fatalf("--%s is registered as %v and -%s as %v, want both", s.long, long, s.short, short)
@@ -161,6 +198,19 @@ func TestConfigResolution(t *testing.T) {
 		wantWorkers int
 		wantTimeout time.Duration
 		wantReport  time.Duration
+		// wantStressor is what --stressor leaves in the Cfg, bcrypt unless given.
+		wantStressor string
+		wantModes    []string
+		// wantRate and wantTarget are what the gc flags leave, in bytes.
+		wantRate   int
+		wantTarget int
+		// wantProbe and wantLocked are what the latency probe's flags leave.
+		wantProbe  time.Duration
+		wantLocked bool
+		wantMix    []stress.Group
+		// wantStartAt and wantUntil are the window's instants, zero unless given.
+		wantStartAt time.Time
+		wantUntil   time.Time
 	}{
 		{
 			name:        "flags",
@@ -177,6 +227,44 @@ func TestConfigResolution(t *testing.T) {
 			wantTimeout: 30 * time.Second,
 			wantReport:  15 * time.Second,
 		},
+		{
+			name:         "a stressor and its modes",
+			args:         []string{"-s", "contention", "--mode", "padded,unpadded"},
+			wantWorkers:  1,
+			wantStressor: "contention",
+			wantModes:    []string{"padded", "unpadded"},
+		},
+		{
+			name:         "the gc stressor's rate and heap",
+			args:         []string{"-s", "gc", "--alloc-rate", "512MiB/s", "--heap-target", "1GiB"},
+			wantWorkers:  1,
+			wantStressor: "gc",
+			wantRate:     512 << 20,
+			wantTarget:   1 << 30,
+		},
+		{
+			name:        "a locked latency probe",
+			args:        []string{"--latency-probe", "500us", "--latency-locked"},
+			wantWorkers: 1,
+			wantProbe:   500 * time.Microsecond,
+			wantLocked:  true,
+		},
+		{
+			// Workers is left at its default: a mix gives each group its own.
+			name:        "a mix",
+			args:        []string{"--mix", "bcrypt:4,cache:2", "--working-set", "1MiB"},
+			wantWorkers: 1,
+			wantMix:     []stress.Group{{Stressor: "bcrypt", Workers: 4}, {Stressor: "cache", Workers: 2}},
+		},
+		{
+			// Far enough out that validate has no past to reject.
+			name:        "a window",
+			args:        []string{"--start-at", "2999-01-02T15:00:00Z", "--until", "2999-01-02T16:10:00+01:00", "-r", "1m"},
+			wantWorkers: 1,
+			wantReport:  time.Minute,
+			wantStartAt: time.Date(2999, 1, 2, 15, 0, 0, 0, time.UTC),
+			wantUntil:   time.Date(2999, 1, 2, 15, 10, 0, 0, time.UTC),
+		},
 		{
 			name:        "only the flags given are set",
 			args:        []string{"-w", "8"},
@@ -209,6 +297,26 @@ func TestConfigResolution(t *testing.T) {
 			if cfg.Report != tt.wantReport {
 				t.Errorf("Report = %s, want %s", cfg.Report, tt.wantReport)
 			}
+
+			wantStressor := cmp.Or(tt.wantStressor, "bcrypt")
+			if cfg.Stressor != wantStressor {
+				t.Errorf("Stressor = %q, want %q", cfg.Stressor, wantStressor)
+			}
+			if !slices.Equal(cfg.Modes, tt.wantModes) {
+				t.Errorf("Modes = %q, want %q", cfg.Modes, tt.wantModes)
+			}
+			if cfg.AllocRate != tt.wantRate || cfg.HeapTarget != tt.wantTarget {
+				t.Errorf("AllocRate, HeapTarget = %d, %d; want %d, %d", cfg.AllocRate, cfg.HeapTarget, tt.wantRate, tt.wantTarget)
+			}
+			if cfg.LatencyProbe != tt.wantProbe || cfg.LatencyLocked != tt.wantLocked {
+				t.Errorf("LatencyProbe, LatencyLocked = %s, %t; want %s, %t", cfg.LatencyProbe, cfg.LatencyLocked, tt.wantProbe, tt.wantLocked)
+			}
+			if !slices.Equal(cfg.Mix, tt.wantMix) {
+				t.Errorf("Mix = %v, want %v", cfg.Mix, tt.wantMix)
+			}
+			if !cfg.StartAt.Equal(tt.wantStartAt) || !cfg.Until.Equal(tt.wantUntil) {
+				t.Errorf("StartAt, Until = %s, %s; want %s, %s", cfg.StartAt, cfg.Until, tt.wantStartAt, tt.wantUntil)
+			}
 		})
 	}
 }
@@ -231,6 +339,23 @@ func TestMalformedFlagValueIsRejected(t *testing.T) {
 		{name: "workers", flag: "-w", other: []string{"-t", "100ms"}, value: "abc", want: "want a whole number"},
 		{name: "workers, a float", flag: "-w", other: []string{"-t", "100ms"}, value: "2.0", want: "want a whole number"},
 		{name: "workers, past what an int holds", flag: "-w", other: []string{"-t", "100ms"}, value: "99999999999999999999", want: "out of range"},
+		{name: "count", flag: "-count", other: []string{"-w", "1"}, value: "1e3", want: "want a whole number of units such as 1000"},
+		{name: "count, negative", flag: "-count", other: []string{"-w", "1"}, value: "-5", want: "want a whole number of units such as 1000"},
+		{name: "burst, no off period", flag: "-burst", other: []string{"-w", "1"}, value: "on=10s", want: "want on= and off= durations, and optionally jitter="},
+		{name: "burst, an unknown key", flag: "-burst", other: []string{"-w", "1"}, value: "on=10s,off=50s,every=1m", want: "want on= and off= durations"},
+		{name: "burst, a key twice", flag: "-burst", other: []string{"-w", "1"}, value: "on=10s,on=5s,off=50s", want: "want on= and off= durations"},
+		{name: "burst, a bare number", flag: "-burst", other: []string{"-w", "1"}, value: "on=10,off=50", want: "want on= and off= durations"},
+		{name: "chaos, no duty", flag: "-chaos", other: []string{"-w", "1"}, value: "workers=1,every=5s", want: "want workers=, duty= and every= ranges"},
+		{name: "chaos, a duty of no percent", flag: "-chaos", other: []string{"-w", "1"}, value: "workers=1,duty=0.5,every=5s", want: "want workers=, duty= and every= ranges"},
+		{name: "chaos, an unknown key", flag: "-chaos", other: []string{"-w", "1"}, value: "workers=1,duty=50%,every=5s,jitter=1s", want: "want workers=, duty= and every= ranges"},
+		{name: "seed, negative", flag: "-seed", other: []string{"-w", "1"}, value: "-42", want: "want a whole number such as 42"},
+		{name: "nice, a word", flag: "-nice", other: []string{"-w", "1"}, value: "low", want: "want a whole number from -20 to 19"},
+		{name: "sched, real-time", flag: "-sched", other: []string{"-w", "1"}, value: "fifo", want: "want one of other, batch, idle"},
+		{name: "replay-speed, none", flag: "-replay-speed", other: []string{"-w", "1"}, value: "-2x", want: "greater than 0, such as 10x or 0.5x"},
+		{name: "replay-speed, a word", flag: "-replay-speed", other: []string{"-w", "1"}, value: "quick", want: "greater than 0, such as 10x or 0.5x"},
+		{name: "rate, per day", flag: "-rate", other: []string{"-w", "1"}, value: "50/d", want: "want a number of units a second, minute or hour"},
+		{name: "rate, negative", flag: "-rate", other: []string{"-w", "1"}, value: "-5/s", want: "want a number of units a second, minute or hour"},
+		{name: "rate, infinite", flag: "-rate", other: []string{"-w", "1"}, value: "Inf", want: "want a number of units a second, minute or hour"},
 	}
 
 	for _, tt := range tests {
@@ -280,7 +405,27 @@ func TestOutOfRangeValueIsRejected(t *testing.T) {
 		// #114: a run that formats rather than hashes is not one anybody asked for.
 		{name: "report under the floor", args: []string{"-w", "1", "-t", "1s", "-r", "1ns"}, want: "report must be 0 (off) or 1s or greater"},
 		// #115: a run whose ticker never fires, which is `-r 1s` mistyped.
+		{name: "stall timeout under the floor", args: []string{"--stall-timeout", "100ms"}, want: "stall timeout must be 0 (off) or 1s or greater"},
 		{name: "report longer than the timeout", args: []string{"-w", "1", "-t", "3s", "-r", "1m"}, want: "report 1m0s is longer than timeout 3s, so no progress line would print"},
+		// Typed, even at the default: a -w 1 beside a mix is a run that does
+		// not do what it says.
+		{name: "workers beside a mix", args: []string{"--mix", "bcrypt:2", "-w", "1"}, want: "mix gives every stressor its own workers, so it takes no -w beside it"},
+		{name: "a stressor beside a mix", args: []string{"--stressor", "cache", "--mix", "bcrypt:2"}, want: "mix gives every stressor its own workers, so it takes no -stressor beside it"},
+		{name: "a group with no workers", args: []string{"--mix", "bcrypt:2,cache:0"}, want: "mix cache: workers must be 1 or greater"},
+		{name: "a burst jittered past a period", args: []string{"--burst", "on=10s,off=50s,jitter=10s"}, want: "burst jitter must be 0 or greater and shorter than both 10s on and 50s off"},
+		{name: "a burst at a rate", args: []string{"--burst", "on=10s,off=50s", "--rate", "50/s"}, want: "target rate and burst both say when units start; give one of them"},
+		{name: "a seed without chaos", args: []string{"-t", "1m", "--seed", "42"}, want: "seed is what --chaos draws its changes from, so it takes a --chaos beside it"},
+		{name: "chaos without an end", args: []string{"--chaos", "workers=1,duty=50%,every=5s"}, want: "chaos needs a timeout, for the changes it draws to have an end"},
+		{name: "chaos past the workers", args: []string{"-t", "1m", "--chaos", "workers=1-2,duty=50%,every=5s"}, want: "chaos workers must be from 0 to the run's 1, the fewer first"},
+		{name: "a rate past the ceiling", args: []string{"--rate", "2e9/s"}, want: "target rate must be 1000000000 a second or lower"},
+		{name: "a rate beside a mix", args: []string{"--rate", "50/s", "--mix", "bcrypt:2,cache:1"}, want: "target rate is in one stressor's units, and a mix has several; give a target rate to a run of one stressor"},
+		// A start gone by is a node out of step with the rest, which is what the flag is for.
+		{name: "a start in the past", args: []string{"--start-at", "2020-01-02T15:00:00Z"}, want: "start at 2020-01-02T15:00:00Z is in the past"},
+		{name: "an end in the past", args: []string{"--until", "2020-01-02T15:00:00Z"}, want: "until 2020-01-02T15:00:00Z is in the past"},
+		{name: "an end before the start", args: []string{"--start-at", "2999-01-02T15:00:00Z", "--until", "2999-01-02T14:00:00Z"}, want: "until 2999-01-02T14:00:00Z is not after start at 2999-01-02T15:00:00Z, so the run would have no time"},
+		{name: "an end and a timeout", args: []string{"--until", "2999-01-02T15:00:00Z", "-t", "5m"}, want: "until and timeout both say when the run ends; give one of them"},
+		// #115 for a window: ten minutes of run is no room for an hourly line.
+		{name: "report longer than the window", args: []string{"--start-at", "2999-01-02T15:00:00Z", "--until", "2999-01-02T15:10:00Z", "-r", "1h"}, want: "report 1h0m0s is longer than timeout 10m0s, so no progress line would print"},
 	}
 
 	for _, tt := range tests {
@@ -586,18 +731,25 @@ func TestFlagDescriptionsWrapUnderTheirColumn(t *testing.T) {
 
 	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
 
-	// The column the first row's description starts at, read off the render
+	// The column the --help row's description starts at, read off the render
 	// rather than recomputed from the table, which would only restate the code.
-	column := strings.Index(lines[0], "help for")
+	column := -1
+	for _, line := range lines {
+		if column = strings.Index(line, "help for"); column >= 0 {
+			break
+		}
+	}
+
 	if column < 0 {
-		t.Fatalf("the flag table opens with %q, want the --help row", lines[0])
+		t.Fatalf("the flag table has no --help row:\n%s", b.String())
 	}
 
 	var wrapped int
 
 	for _, line := range lines {
-		// A row of its own starts with the two-space indent and a shorthand.
-		if strings.HasPrefix(line, "  -") {
+		// A row of its own starts with the two-space indent and a shorthand,
+		// or, with none, with its long name under the others'.
+		if strings.HasPrefix(line, "  -") || strings.HasPrefix(line, "      --") {
 			continue
 		}
 
@@ -693,8 +845,8 @@ func TestTheDefaultIsPrintedOnce(t *testing.T) {
 
 	// A table whose rows all lost their defaults would leave this asserting
 	// nothing, quietly.
-	if checked != 3 {
-		t.Errorf("the flag table has %d rows carrying a default, want the 3 that print one", checked)
+	if checked != 13 {
+		t.Errorf("the flag table has %d rows carrying a default, want the 13 that print one", checked)
 	}
 }
 
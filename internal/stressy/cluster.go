@@ -0,0 +1,565 @@
+package stressy
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/felipeneuwald/stressy/internal/cluster"
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// agentUseLine and coordinateUseLine are what goes under `Usage:` for the two
+// subcommands, and under a bare stressy's line, which names them.
+const (
+	agentUseLine      = "  " + name + " agent --listen address"
+	coordinateUseLine = "  " + name + " coordinate --agents list [flags]"
+)
+
+// agentDescription is what `stressy agent --help` prints above its usage block.
+// The warning is in it rather than left to the README for description's reason:
+// --help is the documentation the image ships.
+const agentDescription = `Stressy agent waits for a coordinator to send it a run, runs it, and sends back
+what it did, one run at a time. It prints what a run prints.
+
+Nothing is authenticated: an agent runs any load it is sent, so listen only on
+a network the coordinator alone can reach.`
+
+const agentExamples = `  # An agent on one interface
+  stressy agent --listen 10.0.0.5:7070
+
+  # In a DaemonSet, on the pod's own address
+  stressy agent --listen $(POD_IP):7070`
+
+// coordinateDescription is what `stressy coordinate --help` prints above its
+// usage block.
+const coordinateDescription = `Stressy coordinate sends one run to every agent, starts them all at the same
+instant, and prints what each did and what they did between them.
+
+Every flag but --agents is the run's, as a bare stressy takes it, and is sent to
+every agent. Each agent prints what a bare stressy would; the figures that
+--cpu-time, --energy, --steal, --thermal and --throttling add, the coordinator
+prints a line of per node under its table. Where --max-temp or --stall-timeout
+ends a node's run, the coordinator exits with the code a bare stressy would.`
+
+const coordinateExamples = `  # Four workers a node for five minutes on two nodes
+  stressy coordinate --agents 10.0.0.5:7070,10.0.0.6:7070 -w 4 -t 5m
+
+  # Every agent on this machine, for a try of the whole thing
+  stressy coordinate --agents localhost:7070,localhost:7071 -t 30s`
+
+// startLead is how far ahead of now a coordinator starts a run given no
+// --start-at: long enough for the job to reach every agent on one network, so
+// none of them is sent an instant that has already gone by, and short enough
+// that nobody waits on it.
+const startLead = 2 * time.Second
+
+// agentHeaderTimeout bounds how long an agent waits for a request's headers:
+// what is on its port that is not a coordinator does not get to hold a
+// connection open forever. The body, a job, is read under no deadline, and the
+// answer is written as long after it as the run lasts.
+const agentHeaderTimeout = 10 * time.Second
+
+// newAgentCmd builds `stressy agent`. cfg holds nothing the agent uses but the
+// Exec Main gives it — each run it is sent is configured by the job — and is
+// there for the command's run seam.
+func newAgentCmd(cfg *Cfg, injected string) *command {
+	c := &command{
+		cfg:      cfg,
+		fs:       flag.NewFlagSet(name+" agent", flag.ContinueOnError),
+		use:      agentUseLine,
+		about:    agentDescription,
+		examples: agentExamples,
+		version:  resolveVersion(injected, buildInfo()),
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+	}
+
+	c.fs.SetOutput(io.Discard)
+
+	var listen string
+
+	// Required rather than defaulted: a default would be every interface, and
+	// an agent with no authentication on every interface is a machine anyone
+	// on the network can load.
+	c.check = func() error {
+		if listen == "" {
+			return errors.New("listen is required: an agent runs any load it is sent, so it takes no address nobody chose")
+		}
+
+		return nil
+	}
+
+	c.run = func(cfg *Cfg) error {
+		return agent{Listen: listen, Out: c.stdout, Exec: cfg.Exec}.serve()
+	}
+
+	c.flags = []setting{
+		{
+			long: "help", short: "h", usage: "help for " + name + " agent",
+			value: newBoolValue(&c.wantHelp),
+		},
+		{
+			long: "listen", short: "l", placeholder: "address",
+			usage: "where to listen for the coordinator, as a host and port such as 10.0.0.5:7070; plain HTTP with no authentication, so on a private network only",
+			value: newAddressValue(&listen),
+		},
+		{
+			long: "version", short: "v", usage: "version for " + name,
+			value: newBoolValue(&c.wantVersion),
+		},
+	}
+
+	c.register()
+
+	return c
+}
+
+// newCoordinateCmd builds `stressy coordinate`: the run's flags, every one of
+// them, and --agents. Built over the run's command rather than beside it, so a
+// flag added to the run is one the coordinator sends without being told to.
+func newCoordinateCmd(cfg *Cfg, injected string) *command {
+	c := newCmd(cfg, injected)
+
+	c.fs = flag.NewFlagSet(name+" coordinate", flag.ContinueOnError)
+	c.fs.SetOutput(io.Discard)
+	c.use, c.about, c.examples = coordinateUseLine, coordinateDescription, coordinateExamples
+
+	var agents []string
+
+	c.check = func() error {
+		if len(agents) == 0 {
+			return errors.New("agents is required: give every agent's address, comma-separated")
+		}
+
+		// The second request to one agent is refused while the first runs, so a
+		// repeat is a node that fails for nothing.
+		for i, a := range agents {
+			if slices.Contains(agents[:i], a) {
+				return fmt.Errorf("agent %s is listed twice", a)
+			}
+		}
+
+		return c.checkRun()
+	}
+
+	c.run = func(cfg *Cfg) error {
+		cfg.Out = c.stdout
+
+		return coordinator{Cfg: *cfg, Agents: agents}.run()
+	}
+
+	for i := range c.flags {
+		if c.flags[i].long == "help" {
+			c.flags[i].usage = "help for " + name + " coordinate"
+		}
+	}
+
+	// First, where alphabetical order puts it.
+	c.flags = append([]setting{{
+		long: "agents", short: "a", placeholder: "list",
+		usage: "the agents to run on, as host:port pairs such as 10.0.0.5:7070,10.0.0.6:7070, each started with `stressy agent --listen`",
+		value: newListValue(&agents),
+	}}, c.flags...)
+
+	c.register()
+
+	return c
+}
+
+// agent is `stressy agent`: a server that runs what a coordinator sends it, and
+// prints every run it makes to Out as a bare stressy would. Exec is the exec
+// mode's command on this node, a Job carrying none.
+type agent struct {
+	Listen string
+	Out    io.Writer
+	Exec   []string
+}
+
+// serve listens until a shutdown signal, and returns a *SignalError for it. A
+// run under way is stopped by the signal and drains, and its Result still goes
+// back to the coordinator that asked for it, before serve returns.
+func (a agent) serve() error {
+	// Registered before the listener for Run's reason: from here on a signal is
+	// a shutdown, reported as one, rather than the end of the process.
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, shutdownSignals...)
+
+	defer signal.Stop(received)
+
+	ln, err := net.Listen("tcp", a.Listen)
+	if err != nil {
+		return err
+	}
+
+	handler := &cluster.Agent{Run: a.runJob}
+	srv := &http.Server{Handler: handler.Handler(), ReadHeaderTimeout: agentHeaderTimeout}
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+
+	writef(a.Out, "Agent listening on %s\n", ln.Addr())
+
+	select {
+	case err := <-served:
+		return err
+	case sig := <-received:
+		// #122: a second signal ends the process rather than waiting on a drain.
+		signal.Stop(received)
+
+		// A run under way prints its own shutdown line, through its observer.
+		if !handler.Stop(&SignalError{Signal: sig}) {
+			writef(a.Out, "Received %s, shutting down; no run was under way\n", signalName(sig))
+		}
+
+		// Waits for the run's answer to be written: what the run did is the
+		// coordinator's table, and a node shut down is still a node in it.
+		_ = srv.Shutdown(context.Background())
+
+		return &SignalError{Signal: sig}
+	}
+}
+
+// runJob is one job, run as Cfg.Run would run it, printing the same lines: the
+// countdown to the job's start, the startup line and the rest. Checked as the
+// command line is, because the job is one; a job that fails the check is the
+// coordinator's error and the agent goes on serving.
+func (a agent) runJob(ctx context.Context, job cluster.Job) (stress.Result, error) {
+	cfg := Cfg{
+		Cfg: job.Cfg, Out: a.Out,
+		CPUTime: job.Show.CPUTime, Throttling: job.Show.Throttling, Steal: job.Show.Steal,
+		Thermal: job.Show.Thermal, Energy: job.Show.Energy, Verbose: job.Show.Verbose,
+	}
+
+	cfg.Exec = a.Exec
+
+	if err := cfg.validate(); err != nil {
+		writef(a.Out, "Refused a run: %v\n", err)
+
+		return stress.Result{}, err
+	}
+
+	// An instant already gone by starts the run at once: a node whose clock
+	// runs ahead of the coordinator's is late, and late is still a run.
+	if left := time.Until(job.StartAt); left > 0 {
+		writef(a.Out, "%s\n", countdownMessage(job.StartAt, left))
+
+		timer := time.NewTimer(left)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			writef(a.Out, "%s before the start; no worker was started\n", stoppedBy(context.Cause(ctx)))
+
+			return stress.Result{}, errors.New("stopped before the start")
+		}
+	}
+
+	cfg.Observer = textObserver{cfg}
+
+	return cfg.Cfg.RunContext(ctx)
+}
+
+// stoppedBy says what stopped an agent's run where nothing ran out: a signal to
+// the agent, the coordinator asking, or the coordinator going away, which
+// closes the request the run was answering.
+func stoppedBy(cause error) string {
+	var signalled *SignalError
+
+	switch {
+	case errors.As(cause, &signalled):
+		return "Received " + signalName(signalled.Signal)
+	case errors.Is(cause, cluster.ErrStopped):
+		return "Stopped by the coordinator"
+	default:
+		return "Lost the coordinator"
+	}
+}
+
+// coordinator is `stressy coordinate`: one run, on every one of Agents.
+type coordinator struct {
+	Cfg
+
+	Agents []string
+}
+
+// run sends the run to every agent, waits for them all, and prints the table of
+// what each did. A shutdown signal stops every agent rather than leaving them
+// loaded; the table is printed all the same, of what they did until then, and
+// run returns a *SignalError for it. Otherwise an agent that failed is an error,
+// once the table has said which.
+func (c coordinator) run() error {
+	if c.Out == nil {
+		c.Out = os.Stdout
+	}
+
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, shutdownSignals...)
+
+	defer signal.Stop(received)
+
+	// One instant for every agent, which is the point: each starts on its own
+	// clock, not whenever the job happened to reach it.
+	if c.StartAt.IsZero() {
+		c.StartAt = time.Now().Add(startLead)
+
+		// validate held Until to now, not to the start the lead puts it off
+		// to, and an Until inside the lead would be every node's run of a
+		// nanosecond.
+		if !c.Until.IsZero() && !c.Until.After(c.StartAt) {
+			return fmt.Errorf("until %s is within the %s the agents are given to start, so the run would have no time; give a later until or a start at", c.Until.Format(time.RFC3339), startLead)
+		}
+	}
+
+	job := cluster.Job{Cfg: c.Cfg.Cfg, StartAt: c.StartAt, Show: c.show()}
+	if !c.Until.IsZero() {
+		job.Cfg.Timeout = c.untilTimeout(time.Now())
+	}
+
+	writef(c.Out, "%s\n", c.coordinatingMessage())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	answered := make(chan struct{})
+	decided := make(chan os.Signal, 1)
+
+	go func() {
+		var sig os.Signal
+
+		select {
+		case sig = <-received:
+		case <-answered:
+			// #117: a signal landing as the last answer does is the one the
+			// operator sent.
+			select {
+			case sig = <-received:
+			default:
+			}
+		}
+
+		// #122, and before the line, for its reason.
+		signal.Stop(received)
+
+		if sig != nil {
+			writef(c.Out, "Received %s, stopping every agent; %s\n", signalName(sig), fmt.Sprintf(drainNotice, c.step()))
+			cancel()
+		}
+
+		decided <- sig
+	}()
+
+	nodes := (&cluster.Coordinator{Agents: c.Agents}).Run(ctx, job)
+	close(answered)
+
+	sig := <-decided
+
+	writef(c.Out, "%s", c.clusterTable(nodes))
+
+	if sig != nil {
+		return &SignalError{Signal: sig}
+	}
+
+	// Ahead of a failed agent: the table names those, and that a node got too
+	// hot or stalled is what a lab's scheduler is asking the code for.
+	if halt := worstHalt(nodes); halt != nil {
+		return &HaltError{Cause: halt}
+	}
+
+	var failed int
+
+	for _, n := range nodes {
+		if n.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d agents failed", failed, len(nodes))
+	}
+
+	return nil
+}
+
+// show is what a Job carries of c beside its stress.Cfg: the flags that add to
+// what a run prints.
+func (c Cfg) show() cluster.Show {
+	return cluster.Show{
+		CPUTime: c.CPUTime, Throttling: c.Throttling, Steal: c.Steal,
+		Thermal: c.Thermal, Energy: c.Energy, Verbose: c.Verbose,
+	}
+}
+
+// nodeHalt is the cause of the HaltError a coordinator returns: an agent whose
+// run a limit of its own ended, and which limit, as its Result says. The zone or
+// the worker is in the agent's own log, the Result carrying no cause.
+type nodeHalt struct {
+	Agent  string
+	Reason stress.StopReason
+}
+
+func (e *nodeHalt) Error() string {
+	return fmt.Sprintf("agent %s ended its run on its %s limit", e.Agent, e.Reason)
+}
+
+// exitCode is the code the agent's own run would have exited with.
+func (e *nodeHalt) exitCode() int {
+	if e.Reason == stress.StopStall {
+		return exitStall
+	}
+
+	return exitTemp
+}
+
+// worstHalt is the node a limit ended whose exit code is highest, a stall over
+// a temperature, or nil where no node's run was ended by one.
+func worstHalt(nodes []cluster.NodeResult) *nodeHalt {
+	var worst *nodeHalt
+
+	for _, n := range nodes {
+		if n.Err != nil || (n.Result.Reason != stress.StopTemp && n.Result.Reason != stress.StopStall) {
+			continue
+		}
+
+		halt := &nodeHalt{Agent: n.Agent, Reason: n.Result.Reason}
+		if worst == nil || halt.exitCode() > worst.exitCode() {
+			worst = halt
+		}
+	}
+
+	return worst
+}
+
+// coordinatingMessage is the line a coordinator prints as it sends the run: how
+// many agents, for how long, and from when.
+func (c coordinator) coordinatingMessage() string {
+	return fmt.Sprintf("Coordinating %d %s %s, starting at %s", len(c.Agents), plural(len(c.Agents), "agent", "agents"), c.lengthClause(), c.StartAt.Format(time.RFC3339))
+}
+
+// clusterTable is what a coordinator prints once every agent has answered: a row
+// per node and group, a line for each node that failed or a limit ended, a total
+// per group across the nodes that did not fail — the rates added up, the slowest
+// node, and the spread between the slowest and the fastest as a share of the
+// fastest — and then each node's figureLines and Host line, where the flags ask
+// for them. Spread is what says a cluster is even: 0% is every node alike, and a
+// node with a noisy neighbour, a throttled CPU or an older part is what moves it.
+func (c Cfg) clusterTable(nodes []cluster.NodeResult) string {
+	var b strings.Builder
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Node\tStressor\tWorkers\tCount\tElapsed\tRate")
+
+	var groups int
+
+	for _, n := range nodes {
+		if n.Err != nil {
+			continue
+		}
+
+		for _, g := range n.Result.Groups {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%.1f %s/s\n",
+				n.Agent, g.Stressor, g.Workers, g.Count, n.Result.Elapsed.Round(time.Millisecond), g.Rate, describe(g.Stressor).Units)
+		}
+
+		groups = max(groups, len(n.Result.Groups))
+	}
+
+	_ = tw.Flush()
+
+	for _, n := range nodes {
+		switch {
+		case n.Err != nil:
+			writef(&b, "Failed %s: %v\n", n.Agent, n.Err)
+		case n.Result.Reason == stress.StopTemp:
+			writef(&b, "Halted %s: a thermal zone reached --max-temp %s\n", n.Agent, newTempValue(&c.MaxTemp))
+		case n.Result.Reason == stress.StopStall:
+			writef(&b, "Halted %s: a worker was on one unit past --stall-timeout %s\n", n.Agent, c.StallTimeout)
+		}
+	}
+
+	for i := range groups {
+		writef(&b, "%s\n", totalMessage(nodes, i))
+	}
+
+	// The agent's name goes in after the figure's, as the Host lines have it:
+	// "Steal 10.0.0.5:7070: 0.4% of ...".
+	for _, n := range nodes {
+		if n.Err != nil {
+			continue
+		}
+
+		for _, line := range c.figureLines(n.Result) {
+			figure, rest, _ := strings.Cut(line, ": ")
+			writef(&b, "%s %s: %s\n", figure, n.Agent, rest)
+		}
+	}
+
+	if c.Verbose {
+		for _, n := range nodes {
+			if n.Err == nil {
+				writef(&b, "Host %s: %s\n", n.Agent, nodeHost(n.Result.Host))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// nodeHost is what hostLines says of a node, on one line under the
+// coordinator's table, where a block a node would be as long as the table.
+func nodeHost(h stress.Host) string {
+	return cpusClause(h) + "; " + runtimeClause(h) + "; cgroup: " + limitsClause(h)
+}
+
+// totalMessage is the total line for the i-th group across every node that ran
+// it.
+func totalMessage(nodes []cluster.NodeResult, i int) string {
+	var (
+		g                 stress.GroupResult
+		count             uint64
+		total, fast, slow float64
+		workers, ran      int
+		slowest           string
+	)
+
+	for _, n := range nodes {
+		if n.Err != nil || i >= len(n.Result.Groups) {
+			continue
+		}
+
+		g = n.Result.Groups[i]
+		count += g.Count
+		total += g.Rate
+		workers += g.Workers
+
+		if ran == 0 || g.Rate < slow {
+			slow, slowest = g.Rate, n.Agent
+		}
+
+		fast = max(fast, g.Rate)
+		ran++
+	}
+
+	var spread float64
+	if fast > 0 {
+		spread = (fast - slow) / fast * 100
+	}
+
+	s := describe(g.Stressor)
+
+	return fmt.Sprintf("Total %s: %d %s at %.1f %s/s from %d %s on %d %s; slowest %s at %.1f %s/s, spread %.1f%%",
+		g.Stressor, count, plural(count, s.Unit, s.Units), total, s.Units,
+		workers, plural(workers, "worker", "workers"), ran, plural(ran, "node", "nodes"),
+		slowest, slow, s.Units, spread)
+}
@@ -0,0 +1,270 @@
+package stressy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/internal/cluster"
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// TestCoordinateOnLocalhost runs the whole of a cluster run on one machine: two
+// agents, a coordinator sending them a run to start together, and the table it
+// prints of what they did, with each node's Steal and Host under it, which are
+// there only if they came back in the node's answer. The agents print Steal too,
+// which they do only if the Job carried --steal to them.
+func TestCoordinateOnLocalhost(t *testing.T) {
+	var (
+		logs   [2]bytes.Buffer
+		agents []string
+	)
+
+	for i := range logs {
+		srv := httptest.NewServer((&cluster.Agent{Run: agent{Out: &logs[i]}.runJob}).Handler())
+		t.Cleanup(srv.Close)
+
+		agents = append(agents, srv.Listener.Addr().String())
+	}
+
+	var out bytes.Buffer
+
+	c := coordinator{
+		Cfg: Cfg{
+			Cfg:     stress.Cfg{Workers: 1, Timeout: 50 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}},
+			Out:     &out,
+			StartAt: time.Now().Add(100 * time.Millisecond),
+			Steal:   true,
+			Verbose: true,
+		},
+		Agents: agents,
+	}
+
+	if err := c.run(); err != nil {
+		t.Fatalf("run() error = %v, want nil; printed:\n%s", err, out.String())
+	}
+
+	want := append([]string{"Coordinating 2 agents for 50ms, starting at ", "Node  "}, agents...)
+	want = append(want, "Total contention: ", "from 2 workers on 2 nodes; slowest ")
+
+	for _, a := range agents {
+		want = append(want, "Steal "+a+": ", "Host "+a+": ")
+	}
+
+	want = append(want, "; "+runtime.GOOS+"/"+runtime.GOARCH+", ")
+
+	for _, w := range want {
+		if !strings.Contains(out.String(), w) {
+			t.Errorf("the coordinator printed:\n%s\nwant %q in it", out.String(), w)
+		}
+	}
+
+	for i := range logs {
+		for _, w := range []string{"Waiting to start at ", "Starting contention stress test with 1 worker for 50ms", "Timer expired", "Computed ", "Steal: "} {
+			if !strings.Contains(logs[i].String(), w) {
+				t.Errorf("agent %d printed:\n%s\nwant %q in it", i, logs[i].String(), w)
+			}
+		}
+	}
+}
+
+func TestClusterTable(t *testing.T) {
+	nodes := []cluster.NodeResult{
+		{Agent: "node-a:7070", Result: stress.Result{Elapsed: 10 * time.Second, Groups: []stress.GroupResult{{Stressor: "bcrypt", Workers: 2, Count: 100, Rate: 10}}}},
+		{Agent: "node-b:7070", Result: stress.Result{Elapsed: 10001 * time.Millisecond, Groups: []stress.GroupResult{{Stressor: "bcrypt", Workers: 2, Count: 80, Rate: 8}}}},
+		{Agent: "node-c:7070", Err: errors.New("connection refused")},
+	}
+
+	want := "" +
+		"Node         Stressor  Workers  Count  Elapsed  Rate\n" +
+		"node-a:7070  bcrypt    2        100    10s      10.0 hashes/s\n" +
+		"node-b:7070  bcrypt    2        80     10.001s  8.0 hashes/s\n" +
+		"Failed node-c:7070: connection refused\n" +
+		"Total bcrypt: 180 hashes at 18.0 hashes/s from 4 workers on 2 nodes; slowest node-b:7070 at 8.0 hashes/s, spread 20.0%\n"
+
+	if got := (Cfg{}).clusterTable(nodes); got != want {
+		t.Errorf("clusterTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestClusterTableHalts: a node a limit ended says which, and the coordinator
+// exits with the worst of their codes, a stall over a temperature.
+func TestClusterTableHalts(t *testing.T) {
+	groups := []stress.GroupResult{{Stressor: "bcrypt", Workers: 1, Count: 10, Rate: 1}}
+	nodes := []cluster.NodeResult{
+		{Agent: "node-a:7070", Result: stress.Result{Reason: stress.StopTemp, Groups: groups}},
+		{Agent: "node-b:7070", Result: stress.Result{Reason: stress.StopStall, Groups: groups}},
+		{Agent: "node-c:7070", Result: stress.Result{Reason: stress.StopTimeout, Groups: groups}},
+	}
+
+	c := Cfg{Cfg: stress.Cfg{MaxTemp: 90, StallTimeout: 30 * time.Second}}
+	table := c.clusterTable(nodes)
+
+	for _, w := range []string{
+		"Halted node-a:7070: a thermal zone reached --max-temp 90C\n",
+		"Halted node-b:7070: a worker was on one unit past --stall-timeout 30s\n",
+	} {
+		if !strings.Contains(table, w) {
+			t.Errorf("clusterTable() =\n%s\nwant %q in it", table, w)
+		}
+	}
+
+	if strings.Contains(table, "Halted node-c") {
+		t.Errorf("clusterTable() =\n%s\nwant no Halted line for a node the timer ended", table)
+	}
+
+	tests := []struct {
+		nodes []cluster.NodeResult
+		want  int
+	}{
+		{nodes: nodes, want: exitStall},
+		{nodes: nodes[:1], want: exitTemp},
+		{nodes: nodes[2:], want: 0},
+	}
+
+	for _, tt := range tests {
+		halt := worstHalt(tt.nodes)
+
+		var got int
+		if halt != nil {
+			got = (&HaltError{Cause: halt}).ExitCode()
+		}
+
+		if got != tt.want {
+			t.Errorf("the exit code of %d nodes = %d, want %d", len(tt.nodes), got, tt.want)
+		}
+	}
+}
+
+func TestStoppedBy(t *testing.T) {
+	tests := []struct {
+		cause error
+		want  string
+	}{
+		{cause: &SignalError{Signal: syscall.SIGTERM}, want: "Received SIGTERM"},
+		{cause: cluster.ErrStopped, want: "Stopped by the coordinator"},
+		// The request the run answers was closed from the other end.
+		{cause: errors.New("context canceled"), want: "Lost the coordinator"},
+	}
+
+	for _, tt := range tests {
+		if got := stoppedBy(tt.cause); got != tt.want {
+			t.Errorf("stoppedBy(%v) = %q, want %q", tt.cause, got, tt.want)
+		}
+	}
+}
+
+// TestRoute: the first argument, and only the first, picks a subcommand.
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantName string
+		wantArgs []string
+	}{
+		{args: nil, wantName: "stressy", wantArgs: nil},
+		{args: []string{"-w", "4"}, wantName: "stressy", wantArgs: []string{"-w", "4"}},
+		{args: []string{"agent", "--listen", ":7070"}, wantName: "stressy agent", wantArgs: []string{"--listen", ":7070"}},
+		{args: []string{"coordinate", "-a", "x:1"}, wantName: "stressy coordinate", wantArgs: []string{"-a", "x:1"}},
+		// A flag's value is not a subcommand, and a bare stressy rejects it.
+		{args: []string{"-s", "agent"}, wantName: "stressy", wantArgs: []string{"-s", "agent"}},
+	}
+
+	for _, tt := range tests {
+		cmd, args := route(tt.args, "")
+
+		if cmd.fs.Name() != tt.wantName || !slices.Equal(args, tt.wantArgs) {
+			t.Errorf("route(%q) = %s, %q; want %s, %q", tt.args, cmd.fs.Name(), args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
+
+// TestSubcommandsCheckTheirFlags covers what each subcommand turns away before
+// it runs, and that a coordinator holds the run it sends to the run's rules.
+func TestSubcommandsCheckTheirFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantErr   string
+		wantUsage bool
+	}{
+		{name: "agent with no address", args: []string{"agent"}, wantErr: "listen is required"},
+		{name: "agent with no port", args: []string{"agent", "--listen", "10.0.0.5"}, wantErr: "host and port", wantUsage: true},
+		{name: "agent with an operand", args: []string{"agent", "--listen", ":7070", "now"}, wantErr: "stressy agent takes flags only", wantUsage: true},
+		{name: "coordinate with no agents", args: []string{"coordinate", "-t", "1m"}, wantErr: "agents is required"},
+		{name: "coordinate with an agent twice", args: []string{"coordinate", "-a", "x:1,y:1,x:1"}, wantErr: "agent x:1 is listed twice"},
+		{name: "coordinate with a bad run", args: []string{"coordinate", "-a", "x:1", "-w", "0"}, wantErr: "workers"},
+		{name: "coordinate with an agent flag", args: []string{"coordinate", "-a", "x:1", "--listen", ":7070"}, wantErr: "listen", wantUsage: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, args := route(tt.args, "")
+
+			var stderr bytes.Buffer
+			cmd.stdout, cmd.stderr = io.Discard, &stderr
+
+			var ran bool
+			cmd.run = func(*Cfg) error { ran = true; return nil }
+
+			err := cmd.execute(args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("execute(%q) error = %v, want one naming %q", tt.args, err, tt.wantErr)
+			}
+
+			if ran {
+				t.Errorf("execute(%q) ran, want the command line turned away first", tt.args)
+			}
+
+			if got := strings.Contains(stderr.String(), "Flags:"); got != tt.wantUsage {
+				t.Errorf("execute(%q) printed the flag list = %t, want %t", tt.args, got, tt.wantUsage)
+			}
+		})
+	}
+}
+
+// TestCoordinateUntilInsideTheLead: an --until that comes before the start a
+// coordinator puts off by startLead is turned away before any agent is sent a
+// run of no time.
+func TestCoordinateUntilInsideTheLead(t *testing.T) {
+	var out bytes.Buffer
+
+	c := coordinator{Cfg: Cfg{Cfg: stress.Cfg{Workers: 1}, Until: time.Now().Add(startLead / 2), Out: &out}, Agents: []string{"127.0.0.1:1"}}
+
+	err := c.run()
+	if err == nil || !strings.Contains(err.Error(), "the agents are given to start") {
+		t.Fatalf("run() error = %v, want the until turned away for the lead", err)
+	}
+
+	if out.Len() > 0 {
+		t.Errorf("run() printed %q, want nothing sent or said", out.String())
+	}
+}
+
+// TestCoordinateSendsTheRun: every run flag reaches the coordinator's run, and
+// --agents with it.
+func TestCoordinateSendsTheRun(t *testing.T) {
+	cmd, args := route([]string{"coordinate", "--agents", "x:1, y:2", "-w", "4", "-t", "5m", "-s", "cache"}, "")
+	cmd.stdout, cmd.stderr = io.Discard, io.Discard
+
+	var got Cfg
+	cmd.run = func(cfg *Cfg) error { got = *cfg; return nil }
+
+	if err := cmd.execute(args); err != nil {
+		t.Fatalf("execute() error = %v, want nil", err)
+	}
+
+	if got.Workers != 4 || got.Timeout != 5*time.Minute || got.Stressor != "cache" {
+		t.Errorf("the run = %+v, want 4 cache workers for 5m", got.Cfg)
+	}
+
+	if row := cmd.flags[0]; row.long != "agents" || row.value.String() != "x:1,y:2" {
+		t.Errorf("the first flag is --%s = %q, want --agents = %q", row.long, row.value.String(), "x:1,y:2")
+	}
+}
@@ -0,0 +1,66 @@
+package stressy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// TestContentionLines covers the lines of a contention run, which is the one
+// whose modes each get a line of the summary: the startup line names them, and
+// the summary has them in the order the run took them. That every mode gets a
+// phase with work in it is the stress package's to test, and it does.
+func TestContentionLines(t *testing.T) {
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 2, Timeout: 4 * time.Second, Stressor: "contention"}}
+
+	g := group("contention", 2, "atomic", "unpadded", "padded", "mutex")
+	for i := range g.Variants {
+		g.Variants[i].Count, g.Variants[i].Spent = 1000*uint64(i+1), time.Second
+		g.Count += g.Variants[i].Count
+	}
+
+	r := stress.Result{Reason: stress.StopTimeout, Elapsed: 4 * time.Second, Count: g.Count, Groups: []stress.GroupResult{g}}
+
+	got := append([]string{cfg.startupMessage([]stress.GroupResult{g}), cfg.shutdownMessage(nil)}, cfg.summaryLines(r)...)
+
+	want := []string{
+		"Starting contention stress test with 2 workers for 4s, modes atomic, unpadded, padded, mutex",
+		"Timer expired, shutting down; waiting for every worker to finish the batch it is on...",
+		"Computed 10000 ops in 4s (2500.0 ops/s, 2 workers)",
+		"Mode atomic: 1000 ops in 1s (1000.0 ops/s)",
+		"Mode unpadded: 2000 ops in 1s (2000.0 ops/s)",
+		"Mode padded: 3000 ops in 1s (3000.0 ops/s)",
+		"Mode mutex: 4000 ops in 1s (4000.0 ops/s)",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("the run's lines are:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestVariantMessage covers the line each mode gets: its rate is over the time
+// that mode had the workers, not over the run.
+func TestVariantMessage(t *testing.T) {
+	g := stress.GroupResult{Stressor: "contention", Workers: 4}
+
+	tests := []struct {
+		name  string
+		count uint64
+		spent time.Duration
+		want  string
+	}{
+		{name: "a mode with work done", count: 8192000, spent: 2 * time.Second, want: "Mode mutex: 8192000 ops in 2s (4096000.0 ops/s)"},
+		{name: "a mode that never had a turn", count: 0, spent: 0, want: "Mode mutex: 0 ops in 0s (0.0 ops/s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := variantMessage(g, stress.VariantResult{Name: "mutex", Count: tt.count, Spent: tt.spent}); got != tt.want {
+				t.Errorf("variantMessage(%d, %s) = %q, want %q", tt.count, tt.spent, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestCountMessages(t *testing.T) {
+	until := time.Date(2026, 1, 2, 15, 10, 0, 0, time.UTC)
+	mix := []stress.Group{{Stressor: "cache", Workers: 2}, {Stressor: "bcrypt", Workers: 1}}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "startup, a count", got: Cfg{Cfg: stress.Cfg{Count: 1000}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1000 hashes"},
+		{name: "startup, one unit", got: Cfg{Cfg: stress.Cfg{Count: 1}}.startupMessage([]stress.GroupResult{group("bcrypt", 1)}), want: "Starting CPU stress test with 1 worker for 1 hash"},
+		{name: "startup, a count and a cap", got: Cfg{Cfg: stress.Cfg{Count: 1000, Timeout: 5 * time.Minute}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1000 hashes or 5m0s, whichever is first"},
+		{name: "startup, a count and an end", got: Cfg{Cfg: stress.Cfg{Count: 1000}, Until: until}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1000 hashes or until 2026-01-02T15:10:00Z, whichever is first"},
+		// A count ends the run, so there is nothing to say about stopping it.
+		{name: "no hint", got: Cfg{Cfg: stress.Cfg{Workers: 1, Count: 1000}}.hintMessage(), want: ""},
+		{name: "shutdown", got: Cfg{Cfg: stress.Cfg{Count: 1000}}.countMessage(), want: "All 1000 hashes handed out, shutting down; waiting for every worker to finish the hash it is on..."},
+		{name: "completion", got: Cfg{Cfg: stress.Cfg{Count: 1000}}.completionMessage(stress.Result{Reason: stress.StopCount, Count: 1000, Elapsed: 45123456 * time.Microsecond}), want: "Time to completion: 45.123s for 1000 hashes"},
+		// The time it spent paused was no work's.
+		{name: "completion, paused", got: Cfg{Cfg: stress.Cfg{Count: 1000}}.completionMessage(stress.Result{Reason: stress.StopCount, Count: 1000, Elapsed: 50 * time.Second, Paused: 5 * time.Second}), want: "Time to completion: 45s for 1000 hashes"},
+		{name: "completion, capped", got: Cfg{Cfg: stress.Cfg{Count: 1000, Timeout: time.Minute}}.completionMessage(stress.Result{Reason: stress.StopTimeout, Count: 812, Elapsed: time.Minute}), want: "Time to completion: none; 812 of 1000 hashes were done when the run ended"},
+		// The stressor's units, not bcrypt's.
+		{name: "completion, cache", got: Cfg{Cfg: stress.Cfg{Stressor: "cache", Count: 1000}}.completionMessage(stress.Result{Reason: stress.StopCount, Count: 1000, Elapsed: time.Second}), want: "Time to completion: 1s for 1000 accesses"},
+		// A mix counts in no one stressor's units, whatever Cfg.Stressor was
+		// left at.
+		{name: "startup, a mix", got: Cfg{Cfg: stress.Cfg{Count: 1000, Mix: mix}}.lengthClause(), want: "for 1000 units"},
+		{name: "completion, a mix", got: Cfg{Cfg: stress.Cfg{Count: 1000, Mix: mix}}.completionMessage(stress.Result{Reason: stress.StopCount, Count: 1000, Elapsed: time.Second}), want: "Time to completion: 1s for 1000 units"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunStopsAtItsCount runs a count from end to end: the startup line, the
+// count's shutdown line, and the summary with the time to completion under it.
+func TestRunStopsAtItsCount(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 2, Count: 3}, Out: &out}).Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	want := []string{
+		"Starting CPU stress test with 2 workers for 3 hashes",
+		"All 3 hashes handed out, shutting down; waiting for every worker to finish the hash it is on...",
+		"Computed 3 hashes in ",
+		"Time to completion: ",
+	}
+
+	if len(lines) != len(want) {
+		t.Fatalf("Run() printed:\n%s\nwant %d lines", out.String(), len(want))
+	}
+
+	for i := range want {
+		if !strings.HasPrefix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to start %q", i, lines[i], want[i])
+		}
+	}
+
+	if !strings.HasSuffix(lines[3], " for 3 hashes") {
+		t.Errorf("line 3 = %q, want the time for 3 hashes", lines[3])
+	}
+}
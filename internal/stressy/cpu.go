@@ -0,0 +1,40 @@
+package stressy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// cpuClause is what --cpu-time adds to every progress line: the cores the
+// process has been given on average so far, and what share of the workers that
+// is.
+func cpuClause(r stress.Result) string {
+	return fmt.Sprintf("cpu %.2f cores, %s", r.CPU.Cores, efficiency(r))
+}
+
+// cpuMessage is the line --cpu-time adds under the summary: the CPU time the
+// process was given, split as the OS keeps it, and the same over the run as
+// the progress lines have it. Well short of every worker is a run a CPU quota
+// or a busy machine held back, however long it ran.
+func cpuMessage(r stress.Result) string {
+	c := r.CPU
+
+	return fmt.Sprintf(
+		"CPU: %s, %s user and %s system; %.2f cores on average, %s",
+		(c.User + c.System).Round(time.Millisecond), c.User.Round(time.Millisecond), c.System.Round(time.Millisecond),
+		c.Cores, efficiency(r),
+	)
+}
+
+// efficiency is the cores given against the workers asking: "98% of 2
+// workers".
+func efficiency(r stress.Result) string {
+	var workers int
+	for _, g := range r.Groups {
+		workers += g.Workers
+	}
+
+	return fmt.Sprintf("%.0f%% of %d %s", r.CPU.Efficiency*100, workers, plural(workers, "worker", "workers"))
+}
@@ -0,0 +1,70 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestCPUMessages(t *testing.T) {
+	r := stress.Result{
+		Elapsed: 2 * time.Second,
+		Groups:  []stress.GroupResult{group("bcrypt", 2)},
+		CPU:     &stress.CPUStats{User: 1912345 * time.Microsecond, System: 87654 * time.Microsecond, Cores: 1, Efficiency: 0.5},
+	}
+
+	one := r
+	one.Groups = []stress.GroupResult{group("bcrypt", 1)}
+	one.CPU = &stress.CPUStats{User: 980 * time.Millisecond, Cores: 0.98, Efficiency: 0.98}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "clause", got: cpuClause(r), want: "cpu 1.00 cores, 50% of 2 workers"},
+		{name: "clause, one worker", got: cpuClause(one), want: "cpu 0.98 cores, 98% of 1 worker"},
+		{name: "summary", got: cpuMessage(r), want: "CPU: 2s, 1.912s user and 88ms system; 1.00 cores on average, 50% of 2 workers"},
+		{name: "summary, no system time", got: cpuMessage(one), want: "CPU: 980ms, 980ms user and 0s system; 0.98 cores on average, 98% of 1 worker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunPrintsItsCPUTime runs --cpu-time from end to end: the summary ends
+// with the CPU line, and without the flag the output has none of it (#70).
+func TestRunPrintsItsCPUTime(t *testing.T) {
+	for _, on := range []bool{false, true} {
+		var out bytes.Buffer
+
+		c := Cfg{
+			Cfg:     stress.Cfg{Workers: 1, Timeout: 100 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}},
+			Out:     &out,
+			CPUTime: on,
+		}
+
+		if err := c.Run(); err != nil {
+			t.Fatalf("Run() error = %v, want nil", err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		last := lines[len(lines)-1]
+
+		if got := strings.HasPrefix(last, "CPU: ") && strings.HasSuffix(last, "of 1 worker"); got != on {
+			t.Errorf("CPUTime %t printed:\n%s\nwant a last CPU line %t", on, out.String(), on)
+		}
+
+		if !on && strings.Contains(out.String(), "cores") {
+			t.Errorf("CPUTime off printed:\n%s\nwant no CPU figures", out.String())
+		}
+	}
+}
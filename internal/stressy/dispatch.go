@@ -0,0 +1,89 @@
+package stressy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// rateClause is what --rate adds to the startup line: ", open loop at 50.0
+// hashes/s", or "" for a closed-loop run. The unit is the stressor's step, which
+// is what one slot of the schedule starts: a hash, or a whole batch or walk.
+func (c Cfg) rateClause() string {
+	if c.TargetRate == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(", open loop at %s %s/s", formatRate(c.TargetRate), c.steps())
+}
+
+// dispatchClause is what --rate adds to every progress line: the rate units
+// were started at against the one they were issued at, the backlog as it
+// stands, and how late units started, over the run so far.
+func (c Cfg) dispatchClause(d stress.DispatchStats, elapsed, paused time.Duration) string {
+	return fmt.Sprintf(
+		"%s of %s %s/s started, backlog %d, start delay %s",
+		formatRate(rate(d.Started, elapsed-paused)), formatRate(d.Target), c.steps(), d.Backlog, dispatchFigures(d),
+	)
+}
+
+// dispatchMessage is the line --rate adds under the summary. The backlog at the
+// end is what the run left unstarted; the one at most says how far behind the
+// workers got, which a run that caught up again has no other trace of.
+func (c Cfg) dispatchMessage(d stress.DispatchStats, elapsed, paused time.Duration) string {
+	steps := c.steps()
+
+	return fmt.Sprintf(
+		"Open loop: %d %s issued at %s/s, %d started at %s/s; backlog %d at the end and %d at most; start delay %s",
+		d.Issued, steps, formatRate(d.Target),
+		d.Started, formatRate(rate(d.Started, elapsed-paused)),
+		d.Backlog, d.MaxBacklog, dispatchFigures(d),
+	)
+}
+
+// dispatchFigures is the four start-delay figures, in milliseconds to the
+// microsecond as latencyFigures has them, or what stands in for them before
+// the first unit.
+func dispatchFigures(d stress.DispatchStats) string {
+	if d.Started == 0 {
+		return "not measured yet"
+	}
+
+	return fmt.Sprintf(
+		"%.3fms min, %.3fms avg, %.3fms p99, %.3fms max",
+		d.Min.Seconds()*1000,
+		d.Avg.Seconds()*1000,
+		d.P99.Seconds()*1000,
+		d.Max.Seconds()*1000,
+	)
+}
+
+// steps is the plural of the run's step — "hashes", "batches", "walks", and
+// "batches or execs" for a syscall run with exec — which is what a rate is
+// counted in.
+func (c Cfg) steps() string {
+	steps := strings.Split(c.step(), " or ")
+
+	for i, step := range steps {
+		if strings.HasSuffix(step, "sh") || strings.HasSuffix(step, "ch") {
+			steps[i] = step + "es"
+		} else {
+			steps[i] = step + "s"
+		}
+	}
+
+	return strings.Join(steps, " or ")
+}
+
+// formatRate prints a rate to a decimal place, as every other rate here is,
+// except one under a unit a second, which a place would round to nothing: an
+// hourly rate of 10 is 0.0028/s.
+func formatRate(r float64) string {
+	if r > 0 && r < 1 {
+		return fmt.Sprintf("%.4f", r)
+	}
+
+	return fmt.Sprintf("%.1f", r)
+}
@@ -0,0 +1,79 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestDispatchMessages(t *testing.T) {
+	d := stress.DispatchStats{
+		Target: 50, Issued: 501, Started: 498, Backlog: 3, MaxBacklog: 7,
+		Min: 10 * time.Microsecond, Avg: 250 * time.Microsecond, P99: 2 * time.Millisecond, Max: 4500 * time.Microsecond,
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "startup", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute, TargetRate: 50}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1m0s, open loop at 50.0 hashes/s"},
+		// A slot starts a whole batch, so the rate is in batches.
+		{name: "startup, a batch at a time", got: Cfg{Cfg: stress.Cfg{Stressor: "syscall", Modes: []string{"getpid"}, TargetRate: 1000}}.startupMessage([]stress.GroupResult{group("syscall", 1, "getpid")}), want: "Starting syscall stress test with 1 worker indefinitely, mode getpid, open loop at 1000.0 batches/s"},
+		// An hourly rate, which a decimal place would print as nothing.
+		{name: "startup, under one a second", got: Cfg{Cfg: stress.Cfg{Timeout: time.Hour, TargetRate: 10.0 / 3600}}.startupMessage([]stress.GroupResult{group("bcrypt", 1)}), want: "Starting CPU stress test with 1 worker for 1h0m0s, open loop at 0.0028 hashes/s"},
+		{name: "closed loop", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1m0s"},
+		{
+			name: "progress",
+			got:  Cfg{Cfg: stress.Cfg{TargetRate: 50}}.progressLine(stress.Result{Count: 498, Elapsed: 10 * time.Second, Groups: []stress.GroupResult{group("bcrypt", 4)}, Dispatch: &d}),
+			want: "10s elapsed, 498 hashes, 49.8 hashes/s; 49.8 of 50.0 hashes/s started, backlog 3, start delay 0.010ms min, 0.250ms avg, 2.000ms p99, 4.500ms max",
+		},
+		{
+			name: "progress, nothing started",
+			got:  Cfg{Cfg: stress.Cfg{TargetRate: 50}}.progressLine(stress.Result{Elapsed: time.Second, Groups: []stress.GroupResult{group("bcrypt", 4)}, Dispatch: &stress.DispatchStats{Target: 50}}),
+			want: "1s elapsed, 0 hashes, 0.0 hashes/s; 0.0 of 50.0 hashes/s started, backlog 0, start delay not measured yet",
+		},
+		// The time paused was no slot's, as it was no hash's.
+		{
+			name: "summary",
+			got:  Cfg{Cfg: stress.Cfg{TargetRate: 50}}.dispatchMessage(d, 12*time.Second, 2*time.Second),
+			want: "Open loop: 501 hashes issued at 50.0/s, 498 started at 49.8/s; backlog 3 at the end and 7 at most; start delay 0.010ms min, 0.250ms avg, 2.000ms p99, 4.500ms max",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunAtARate runs --rate from end to end: the startup line names the rate
+// and the summary ends with what the schedule saw.
+func TestRunAtARate(t *testing.T) {
+	var out bytes.Buffer
+
+	c := Cfg{
+		Cfg: stress.Cfg{Workers: 1, Timeout: 200 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, TargetRate: 100},
+		Out: &out,
+	}
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	if !strings.HasSuffix(lines[0], ", open loop at 100.0 batches/s") {
+		t.Errorf("line 0 = %q, want the rate named", lines[0])
+	}
+
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "Open loop: ") || !strings.Contains(last, " batches issued at 100.0/s, ") {
+		t.Errorf("the last line = %q, want the schedule's", last)
+	}
+}
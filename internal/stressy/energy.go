@@ -0,0 +1,32 @@
+package stressy
+
+import (
+	"fmt"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// energyMessage is the line --energy adds under the summary: the joules the CPU
+// packages drew over the run, the watts that averages, and the units done a
+// joule, "Energy: 24125.3 J, 40.2 W on average, 0.942 hashes per joule". A
+// mix's groups share the packages, so a mix has no units a joule.
+func (c Cfg) energyMessage(r stress.Result) string {
+	e := r.Energy
+
+	line := fmt.Sprintf("Energy: %.1f J, %.1f W on average", e.Joules, e.Watts)
+	if len(c.Mix) > 0 || e.Joules == 0 {
+		return line
+	}
+
+	return fmt.Sprintf("%s, %s %s per joule", line, perJoule(float64(r.Count)/e.Joules), describe(c.Stressor).Units)
+}
+
+// perJoule is a figure a joule to three significant digits, "0.942", and
+// whole past 100, where a cache run's millions would otherwise be exponents.
+func perJoule(v float64) string {
+	if v >= 100 {
+		return fmt.Sprintf("%.0f", v)
+	}
+
+	return fmt.Sprintf("%.3g", v)
+}
@@ -0,0 +1,73 @@
+package stressy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestEnergyMessage(t *testing.T) {
+	bcrypt := stress.Result{
+		Count: 22722, Elapsed: 10 * time.Minute, Groups: []stress.GroupResult{group("bcrypt", 8)},
+		Energy: &stress.EnergyStats{Zones: 1, Joules: 24125.34, Watts: 40.21},
+	}
+
+	cache := stress.Result{
+		Count: 3_000_000_000, Elapsed: 10 * time.Second, Groups: []stress.GroupResult{group("cache", 2)},
+		Energy: &stress.EnergyStats{Zones: 2, Joules: 300, Watts: 30},
+	}
+
+	idle := stress.Result{Elapsed: time.Second, Groups: []stress.GroupResult{group("bcrypt", 1)}, Energy: &stress.EnergyStats{Zones: 1}}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "bcrypt", got: Cfg{Cfg: stress.Cfg{Stressor: "bcrypt"}}.energyMessage(bcrypt), want: "Energy: 24125.3 J, 40.2 W on average, 0.942 hashes per joule"},
+		{name: "millions a joule", got: Cfg{Cfg: stress.Cfg{Stressor: "cache"}}.energyMessage(cache), want: "Energy: 300.0 J, 30.0 W on average, 10000000 accesses per joule"},
+		{name: "mix", got: Cfg{Cfg: stress.Cfg{Mix: []stress.Group{{Stressor: "bcrypt", Workers: 4}}}}.energyMessage(bcrypt), want: "Energy: 24125.3 J, 40.2 W on average"},
+		{name: "no energy counted", got: Cfg{Cfg: stress.Cfg{Stressor: "bcrypt"}}.energyMessage(idle), want: "Energy: 0.0 J, 0.0 W on average"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEnergySummary holds --energy to #70 and to the request's silence: the
+// line is there with the flag and a counter, and not without either.
+func TestEnergySummary(t *testing.T) {
+	r := stress.Result{Count: 8, Elapsed: 2 * time.Second, Groups: []stress.GroupResult{group("bcrypt", 2)}, Energy: &stress.EnergyStats{Zones: 1, Joules: 80, Watts: 40}}
+
+	has := func(lines []string) bool {
+		for _, l := range lines {
+			if strings.HasPrefix(l, "Energy: ") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	on := Cfg{Cfg: stress.Cfg{Stressor: "bcrypt", Workers: 2}, Energy: true}
+
+	if !has(on.summaryLines(r)) {
+		t.Errorf("summaryLines() with --energy = %q, want an Energy line", on.summaryLines(r))
+	}
+
+	if off := (Cfg{Cfg: stress.Cfg{Stressor: "bcrypt", Workers: 2}}); has(off.summaryLines(r)) {
+		t.Errorf("summaryLines() without --energy = %q, want no Energy line (#70)", off.summaryLines(r))
+	}
+
+	r.Energy = nil
+	if has(on.summaryLines(r)) {
+		t.Errorf("summaryLines() with no counter = %q, want no Energy line", on.summaryLines(r))
+	}
+}
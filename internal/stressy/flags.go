@@ -2,8 +2,16 @@ package stressy
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+	"github.com/felipeneuwald/stressy/stress"
 )
 
 // durationValue adapts time.Duration to the flag.Value interface. Stock
@@ -42,6 +50,186 @@ func (d *durationValue) Type() string { return "duration" }
 
 func (d *durationValue) String() string { return time.Duration(*d).String() }
 
+// timeValue adapts an instant to the flag.Value interface, for --start-at and
+// --until: RFC 3339, which is what `date -u +%Y-%m-%dT%H:%M:%SZ` prints on every
+// node alike, rather than a local time whose zone each of them reads its own
+// way. Whether the instant is still to come is validate's to say, as a range
+// always is.
+type timeValue time.Time
+
+// newTimeValue leaves p as it is; the zero time is no instant at all.
+func newTimeValue(p *time.Time) *timeValue { return (*timeValue)(p) }
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return errors.New("want an RFC 3339 time such as 2026-01-02T15:04:05Z")
+	}
+
+	*v = timeValue(t)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--until time`.
+func (v *timeValue) Type() string { return "time" }
+
+// String is "" for the zero time, so the Flags block prints no default for
+// either flag rather than the year 1.
+func (v *timeValue) String() string {
+	if time.Time(*v).IsZero() {
+		return ""
+	}
+
+	return time.Time(*v).Format(time.RFC3339)
+}
+
+// addressValue adapts `stressy agent --listen` to the flag.Value interface: a
+// host and port as net.Listen takes them, checked for shape here so a typo is a
+// usage error rather than a failure to listen.
+type addressValue string
+
+// newAddressValue leaves p as it is; no address is the default, because there
+// is no address an agent nobody chose one for should listen on.
+func newAddressValue(p *string) *addressValue { return (*addressValue)(p) }
+
+func (v *addressValue) Set(s string) error {
+	if _, _, err := net.SplitHostPort(s); err != nil {
+		return errors.New("want a host and port such as 10.0.0.5:7070, or :7070 for every interface")
+	}
+
+	*v = addressValue(s)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--listen address`.
+func (v *addressValue) Type() string { return "address" }
+
+func (v *addressValue) String() string { return string(*v) }
+
+// pathValue adapts --replay to the flag.Value interface: a file to read, which
+// is read once the command line is parsed, so a file that is not there is a
+// runtime error rather than a usage one.
+type pathValue string
+
+// newPathValue leaves p as it is; no path is no replay.
+func newPathValue(p *string) *pathValue { return (*pathValue)(p) }
+
+func (v *pathValue) Set(s string) error {
+	if s == "" {
+		return errors.New("want the path of a file such as trace.csv")
+	}
+
+	*v = pathValue(s)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--replay file`.
+func (v *pathValue) Type() string { return "file" }
+
+func (v *pathValue) String() string { return string(*v) }
+
+// speedValue adapts --replay-speed to the flag.Value interface: how many times
+// faster than it was recorded a trace is replayed, as 10x or 10.
+type speedValue float64
+
+// newSpeedValue writes the default through p: 1x, the trace as it was.
+func newSpeedValue(p *float64) *speedValue {
+	*p = 1
+
+	return (*speedValue)(p)
+}
+
+func (v *speedValue) Set(s string) error {
+	n, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || !(n > 0) || math.IsInf(n, 0) {
+		return errors.New("want how many times faster than recorded, greater than 0, such as 10x or 0.5x")
+	}
+
+	*v = speedValue(n)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--replay-speed speed`.
+func (v *speedValue) Type() string { return "speed" }
+
+func (v *speedValue) String() string {
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64) + "x"
+}
+
+// countValue adapts --count to the flag.Value interface: a whole number of the
+// stressor's units, and 0 for none. How large one may be is Validate's to say.
+type countValue uint64
+
+// newCountValue leaves p as it is; 0 is a run with no count.
+func newCountValue(p *uint64) *countValue { return (*countValue)(p) }
+
+func (v *countValue) Set(s string) error {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return errors.New("want a whole number of units such as 1000")
+	}
+
+	*v = countValue(n)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--count int`.
+func (v *countValue) Type() string { return "int" }
+
+func (v *countValue) String() string { return strconv.FormatUint(uint64(*v), 10) }
+
+// rateValue adapts --rate to the flag.Value interface: units a second, a
+// minute or an hour, held as units a second, the only way the engine takes it.
+type rateValue float64
+
+// newRateValue leaves p as it is; 0 is a closed-loop run.
+func newRateValue(p *float64) *rateValue { return (*rateValue)(p) }
+
+// Set takes "/s" as read where no period is given, as --alloc-rate does. The
+// ceiling is the engine's to enforce, so it is named once; a rate that is not
+// one at all is turned away here.
+func (v *rateValue) Set(s string) error {
+	per := 1.0
+
+	periods := []struct {
+		suffix  string
+		seconds float64
+	}{{"/s", 1}, {"/m", 60}, {"/h", 3600}}
+
+	for _, p := range periods {
+		if trimmed, ok := strings.CutSuffix(s, p.suffix); ok {
+			s, per = trimmed, p.seconds
+
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return errors.New("want a number of units a second, minute or hour such as 50/s, 300/m or 10/h")
+	}
+
+	*v = rateValue(n / per)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--rate rate`.
+func (v *rateValue) Type() string { return "rate" }
+
+func (v *rateValue) String() string {
+	if *v == 0 {
+		return "0"
+	}
+
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64) + "/s"
+}
+
 // workersValue adapts the worker count to the flag.Value interface. Stock
 // IntVar reports `strconv.ParseInt: parsing "abc"` at an operator who may not
 // write Go. Its message is the guidance alone, for durationValue's reason.
@@ -139,3 +327,510 @@ func (b *boolValue) Set(s string) error {
 func (b *boolValue) IsBoolFlag() bool { return true }
 
 func (b *boolValue) String() string { return strconv.FormatBool(bool(*b)) }
+
+// stressorValue adapts the stressor name to the flag.Value interface, so a name
+// there is no stressor for is refused by the parser — with the flag list under
+// it, which names every one there is — rather than at validation.
+type stressorValue string
+
+// newStressorValue writes the default through p, as newWorkersValue does: the
+// `(default bcrypt)` in the help line is read off it.
+func newStressorValue(val string, p *string) *stressorValue {
+	*p = val
+
+	return (*stressorValue)(p)
+}
+
+func (s *stressorValue) Set(v string) error {
+	if !knownStressor(v) {
+		return errors.New("want one of " + stressorNames())
+	}
+
+	*s = stressorValue(v)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--stressor name`.
+func (s *stressorValue) Type() string { return "name" }
+
+func (s *stressorValue) String() string { return string(*s) }
+
+// listValue adapts a comma-separated list to the flag.Value interface. Which
+// items are valid is left to Cfg.validate, where the rest of the command line
+// is known: whether a mode exists depends on the stressor, and --stressor may
+// come after --mode.
+type listValue []string
+
+// newListValue leaves p as it is; every list defaults to empty, which is what
+// means "all of them" to the one field it is used for.
+func newListValue(p *[]string) *listValue { return (*listValue)(p) }
+
+// Set replaces rather than appends, so `--mode atomic --mode mutex` means what
+// the last one says, as every other flag does when it is given twice.
+func (l *listValue) Set(s string) error {
+	items := strings.Split(s, ",")
+
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+
+		if items[i] == "" {
+			return errors.New("want a comma-separated list such as atomic,mutex")
+		}
+	}
+
+	*l = items
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--mode list`.
+func (l *listValue) Type() string { return "list" }
+
+func (l *listValue) String() string { return strings.Join(*l, ",") }
+
+// wantSize is the guidance every rejected --working-set ends in.
+const wantSize = "want auto or sizes such as 32KiB,1MiB,64MiB"
+
+// sizesValue adapts --working-set to the flag.Value interface: "auto", which
+// leaves the list empty and so the tiers to the stressor, or sizes separated by
+// commas.
+type sizesValue []int
+
+// newSizesValue leaves p as it is; empty is auto.
+func newSizesValue(p *[]int) *sizesValue { return (*sizesValue)(p) }
+
+func (v *sizesValue) Set(s string) error {
+	if s == "auto" {
+		*v = nil
+
+		return nil
+	}
+
+	var sizes []int
+
+	for item := range strings.SplitSeq(s, ",") {
+		n, err := units.ParseSize(strings.TrimSpace(item))
+		if err != nil {
+			return errors.New(wantSize)
+		}
+
+		sizes = append(sizes, n)
+	}
+
+	*v = sizes
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--working-set sizes`.
+func (v *sizesValue) Type() string { return "sizes" }
+
+func (v *sizesValue) String() string {
+	if len(*v) == 0 {
+		return "auto"
+	}
+
+	names := make([]string, len(*v))
+	for i, n := range *v {
+		names[i] = units.FormatSize(n)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// sizeValue adapts one size to the flag.Value interface, for --heap-target and
+// --alloc-rate: zero, the word that stands for 0 on either, or a size as
+// units.ParseSize reads one, followed by per where the size is a rate.
+type sizeValue struct {
+	p    *int
+	zero string // "auto" or "unlimited": what 0 is called here and in the help
+	per  string // "/s" for a rate, which Set takes off and String puts back
+}
+
+// newSizeValue leaves p as it is; 0 is the zero word, which is every default.
+func newSizeValue(p *int, zero, per string) *sizeValue {
+	return &sizeValue{p: p, zero: zero, per: per}
+}
+
+// Set takes the rate's "/s" off if it is there rather than insisting on it:
+// `--alloc-rate 512MiB` means a second as plainly as `512MiB/s` does.
+func (v *sizeValue) Set(s string) error {
+	if s == v.zero {
+		*v.p = 0
+
+		return nil
+	}
+
+	if v.per != "" {
+		s = strings.TrimSuffix(s, v.per)
+	}
+
+	n, err := units.ParseSize(s)
+	if err != nil {
+		return errors.New("want " + v.zero + " or a " + v.Type() + " such as 64MiB" + v.per)
+	}
+
+	*v.p = n
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints: `--heap-target size`, or
+// `--alloc-rate rate`.
+func (v *sizeValue) Type() string {
+	if v.per != "" {
+		return "rate"
+	}
+
+	return "size"
+}
+
+func (v *sizeValue) String() string {
+	if v.p == nil || *v.p == 0 {
+		return v.zero
+	}
+
+	return units.FormatSize(*v.p) + v.per
+}
+
+// mixValue adapts --mix to the flag.Value interface: stressor:workers pairs
+// separated by commas. A stressor there is none of is caught here, as
+// --stressor catches one; a worker count out of range is validate's, as it is
+// for --workers.
+type mixValue []stress.Group
+
+// newMixValue leaves p as it is; empty is no mix.
+func newMixValue(p *[]stress.Group) *mixValue { return (*mixValue)(p) }
+
+// wantMix is the guidance every rejected --mix ends in.
+const wantMix = "want stressor:workers pairs such as bcrypt:4,cache:2"
+
+// Set replaces rather than appends, as listValue's does.
+func (v *mixValue) Set(s string) error {
+	var groups []stress.Group
+
+	for item := range strings.SplitSeq(s, ",") {
+		stressor, workers, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return errors.New(wantMix)
+		}
+
+		if !knownStressor(stressor) {
+			return errors.New("want stressors from " + stressorNames() + ", as stressor:workers pairs such as bcrypt:4,cache:2")
+		}
+
+		n, err := strconv.Atoi(workers)
+		if err != nil {
+			return errors.New(wantMix)
+		}
+
+		groups = append(groups, stress.Group{Stressor: stressor, Workers: n})
+	}
+
+	*v = groups
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--mix groups`.
+func (v *mixValue) Type() string { return "groups" }
+
+func (v *mixValue) String() string {
+	pairs := make([]string, len(*v))
+	for i, g := range *v {
+		pairs[i] = g.Stressor + ":" + strconv.Itoa(g.Workers)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// burstValue adapts --burst to the flag.Value interface: on= and off=
+// durations, and optionally jitter=, separated by commas, in any order. A
+// period out of range is validate's, as a timeout is.
+type burstValue stress.Burst
+
+// newBurstValue leaves p as it is; the zero Burst is a steady run.
+func newBurstValue(p *stress.Burst) *burstValue { return (*burstValue)(p) }
+
+// wantBurst is the guidance every rejected --burst ends in.
+const wantBurst = "want on= and off= durations, and optionally jitter=, such as on=15s,off=45s,jitter=5s"
+
+// Set replaces rather than adds to, as mixValue's does, and takes both periods
+// or neither: a burst with no off period is a steady run said the long way.
+func (v *burstValue) Set(s string) error {
+	var (
+		b    stress.Burst
+		seen = map[string]bool{}
+	)
+
+	for item := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || seen[key] {
+			return errors.New(wantBurst)
+		}
+
+		seen[key] = true
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New(wantBurst)
+		}
+
+		switch key {
+		case "on":
+			b.On = d
+		case "off":
+			b.Off = d
+		case "jitter":
+			b.Jitter = d
+		default:
+			return errors.New(wantBurst)
+		}
+	}
+
+	if !seen["on"] || !seen["off"] {
+		return errors.New(wantBurst)
+	}
+
+	*v = burstValue(b)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--burst periods`.
+func (v *burstValue) Type() string { return "periods" }
+
+func (v *burstValue) String() string {
+	if *v == (burstValue{}) {
+		return ""
+	}
+
+	s := "on=" + v.On.String() + ",off=" + v.Off.String()
+	if v.Jitter > 0 {
+		s += ",jitter=" + v.Jitter.String()
+	}
+
+	return s
+}
+
+// chaosValue adapts --chaos to the flag.Value interface: the bounds a chaotic
+// run draws its changes from, as workers=, duty= and every= ranges such as
+// workers=1-4,duty=20%-100%,every=5s-30s, separated by commas, in any order. A
+// range of one value, workers=4, is that value every time. The Seed is
+// --seed's, and Set leaves it as it is, whichever flag comes first.
+type chaosValue stress.Chaos
+
+// newChaosValue leaves p as it is; the zero Chaos is a run without one.
+func newChaosValue(p *stress.Chaos) *chaosValue { return (*chaosValue)(p) }
+
+// wantChaos is the guidance every rejected --chaos ends in.
+const wantChaos = "want workers=, duty= and every= ranges, such as workers=2-6,duty=25%-75%,every=10s-1m"
+
+// Set replaces the bounds rather than adding to them, as burstValue's does,
+// and takes all three: chaos in one of them alone is a steady run in the
+// others, which is a fact about the run the command line should say.
+func (v *chaosValue) Set(s string) error {
+	var (
+		ch   = stress.Chaos{Seed: v.Seed}
+		seen = map[string]bool{}
+	)
+
+	for item := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || seen[key] {
+			return errors.New(wantChaos)
+		}
+
+		seen[key] = true
+
+		low, high, ok := strings.Cut(value, "-")
+		if !ok {
+			high = low
+		}
+
+		var err error
+
+		switch key {
+		case "workers":
+			ch.MinWorkers, err = strconv.Atoi(low)
+			if err == nil {
+				ch.MaxWorkers, err = strconv.Atoi(high)
+			}
+		case "duty":
+			ch.MinDuty, err = parsePercent(low)
+			if err == nil {
+				ch.MaxDuty, err = parsePercent(high)
+			}
+		case "every":
+			ch.MinEvery, err = time.ParseDuration(low)
+			if err == nil {
+				ch.MaxEvery, err = time.ParseDuration(high)
+			}
+		default:
+			return errors.New(wantChaos)
+		}
+
+		if err != nil {
+			return errors.New(wantChaos)
+		}
+	}
+
+	if !seen["workers"] || !seen["duty"] || !seen["every"] {
+		return errors.New(wantChaos)
+	}
+
+	*v = chaosValue(ch)
+
+	return nil
+}
+
+// parsePercent reads a duty such as 25% as the share of the time it is.
+func parsePercent(s string) (float64, error) {
+	n, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return 0, errors.New("want a percent")
+	}
+
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return f / 100, nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--chaos bounds`.
+func (v *chaosValue) Type() string { return "bounds" }
+
+func (v *chaosValue) String() string {
+	if v.MaxEvery == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"workers=%s,duty=%s,every=%s",
+		span(strconv.Itoa(v.MinWorkers), strconv.Itoa(v.MaxWorkers)),
+		span(formatPercent(v.MinDuty), formatPercent(v.MaxDuty)),
+		span(v.MinEvery.String(), v.MaxEvery.String()),
+	)
+}
+
+// span is a range as --chaos spells it: low-high, or one value where they are
+// the same.
+func span(low, high string) string {
+	if low == high {
+		return low
+	}
+
+	return low + "-" + high
+}
+
+// formatPercent prints a duty as parsePercent reads one.
+func formatPercent(f float64) string {
+	return strconv.FormatFloat(f*100, 'g', -1, 64) + "%"
+}
+
+// seedValue adapts --seed to the flag.Value interface: the whole number a
+// --chaos run draws its changes from.
+type seedValue uint64
+
+// newSeedValue leaves p as it is; which seed a run without --seed gets is the
+// command's to pick, once it knows none was typed.
+func newSeedValue(p *uint64) *seedValue { return (*seedValue)(p) }
+
+func (v *seedValue) Set(s string) error {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return errors.New("want a whole number such as 42, as a startup line gives it")
+	}
+
+	*v = seedValue(n)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--seed int`.
+func (v *seedValue) Type() string { return "int" }
+
+func (v *seedValue) String() string { return strconv.FormatUint(uint64(*v), 10) }
+
+// niceValue adapts --nice to the flag.Value interface: a whole number, which
+// may be negative. Whether it is one a thread may be given is validate's, as a
+// timeout's range is.
+type niceValue int
+
+// newNiceValue leaves p as it is; 0 leaves the workers at the process's nice.
+func newNiceValue(p *int) *niceValue { return (*niceValue)(p) }
+
+func (v *niceValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return errors.New("want a whole number from -20 to 19, such as 10")
+	}
+
+	*v = niceValue(n)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--nice int`.
+func (v *niceValue) Type() string { return "int" }
+
+func (v *niceValue) String() string { return strconv.Itoa(int(*v)) }
+
+// tempValue adapts --max-temp to the flag.Value interface: degrees Celsius,
+// with or without the C, as "90C" or "90". A limit at or under 0 is none any
+// machine running this could be under, and is refused here.
+type tempValue float64
+
+// newTempValue leaves p as it is; 0 is no limit.
+func newTempValue(p *float64) *tempValue { return (*tempValue)(p) }
+
+func (v *tempValue) Set(s string) error {
+	degrees, _ := strings.CutSuffix(strings.ToUpper(s), "C")
+
+	n, err := strconv.ParseFloat(degrees, 64)
+	if err != nil || !(n > 0) || math.IsInf(n, 0) {
+		return errors.New("want a temperature in degrees Celsius greater than 0, such as 90C")
+	}
+
+	*v = tempValue(n)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--max-temp temp`.
+func (v *tempValue) Type() string { return "temp" }
+
+// String is "" for no limit, so the flag table has no default to print.
+func (v *tempValue) String() string {
+	if *v == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(float64(*v), 'f', -1, 64) + "C"
+}
+
+// schedValue adapts --sched to the flag.Value interface, refusing a policy
+// the stress package has no name for, as stressorValue refuses a stressor.
+type schedValue string
+
+// newSchedValue leaves p as it is; "" leaves the workers under the process's
+// policy.
+func newSchedValue(p *string) *schedValue { return (*schedValue)(p) }
+
+func (v *schedValue) Set(s string) error {
+	if !slices.Contains(stress.Scheds(), s) {
+		return errors.New("want one of " + strings.Join(stress.Scheds(), ", "))
+	}
+
+	*v = schedValue(s)
+
+	return nil
+}
+
+// Type is the placeholder the Flags block prints, as in `--sched policy`.
+func (v *schedValue) Type() string { return "policy" }
+
+func (v *schedValue) String() string { return string(*v) }
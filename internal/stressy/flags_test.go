@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
 )
 
 // acceptedValue is a spelling Set must take and what the flag prints afterwards.
@@ -22,6 +24,22 @@ func TestFlagValues(t *testing.T) {
 		// adapts the field it is given and writes no default through it, so what
 		// the flag package records is whatever that field already holds.
 		timeout = 90 * time.Second
+
+		stressor string
+		modes    []string
+		sizes    []int
+		rate     int
+		perSec   float64
+		burst    stress.Burst
+		chaos    stress.Chaos
+		nice     int
+		sched    string
+		maxTemp  float64
+		replay   string
+		speed    float64
+		target   int
+		mix      []stress.Group
+		until    time.Time
 	)
 
 	tests := []struct {
@@ -62,6 +80,263 @@ func TestFlagValues(t *testing.T) {
 			wantFragments: []string{"timeout", "5 minutes", "want a duration such as 30s or 5m"},
 			noStrconv:     true,
 		},
+		{
+			name: "stressor",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newStressorValue("bcrypt", &stressor), "stressor", "the load")
+			},
+			get:      func() string { return stressor },
+			wantType: "name",
+			wantDef:  "bcrypt",
+			accepted: []acceptedValue{{set: "contention", want: "contention"}, {set: "bcrypt", want: "bcrypt"}},
+			badValue: "disk",
+			// Every stressor there is, which is the answer to what was meant.
+			wantFragments: []string{"stressor", "disk", "want one of bcrypt, contention"},
+		},
+		{
+			name: "mode",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newListValue(&modes), "mode", "the modes")
+			},
+			get:      func() string { return strings.Join(modes, ",") },
+			wantType: "list",
+			wantDef:  "",
+			accepted: []acceptedValue{
+				{set: "atomic", want: "atomic"},
+				// Replaced rather than appended to, and trimmed.
+				{set: "padded, unpadded", want: "padded,unpadded"},
+			},
+			badValue:      "atomic,,mutex",
+			wantFragments: []string{"mode", "atomic,,mutex", "want a comma-separated list"},
+		},
+		{
+			name: "working-set",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newSizesValue(&sizes), "working-set", "the sizes")
+			},
+			get:      func() string { return newSizesValue(&sizes).String() },
+			wantType: "sizes",
+			wantDef:  "auto",
+			accepted: []acceptedValue{
+				{set: "32KiB,1MiB,64MiB", want: "32KiB,1MiB,64MiB"},
+				// Printed in the largest unit that divides it.
+				{set: "2048KiB", want: "2MiB"},
+				{set: "auto", want: "auto"},
+			},
+			badValue:      "32KB",
+			wantFragments: []string{"working-set", "32KB", "want auto or sizes such as 32KiB"},
+			noStrconv:     true,
+		},
+		{
+			name: "burst",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newBurstValue(&burst), "burst", "the bursts")
+			},
+			get:      func() string { return newBurstValue(&burst).String() },
+			wantType: "periods",
+			wantDef:  "",
+			accepted: []acceptedValue{
+				{set: "on=10s,off=50s", want: "on=10s,off=50s"},
+				// In any order, and replaced rather than added to.
+				{set: "jitter=5s, off=1m, on=10s", want: "on=10s,off=1m0s,jitter=5s"},
+			},
+			badValue:      "on=10s",
+			wantFragments: []string{"burst", "on=10s", "want on= and off= durations"},
+			noStrconv:     true,
+		},
+		{
+			name: "chaos",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newChaosValue(&chaos), "chaos", "the chaos")
+			},
+			get:      func() string { return newChaosValue(&chaos).String() },
+			wantType: "bounds",
+			wantDef:  "",
+			accepted: []acceptedValue{
+				{set: "workers=1-4,duty=20%-100%,every=5s-30s", want: "workers=1-4,duty=20%-100%,every=5s-30s"},
+				// In any order, a range of one value said once, and replaced
+				// rather than added to.
+				{set: "every=1m, duty=50%, workers=0-2", want: "workers=0-2,duty=50%,every=1m0s"},
+			},
+			badValue:      "workers=1-4,every=5s-30s",
+			wantFragments: []string{"chaos", "workers=1-4,every=5s-30s", "want workers=, duty= and every= ranges"},
+			noStrconv:     true,
+		},
+		{
+			name: "seed",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newSeedValue(&chaos.Seed), "seed", "the seed")
+			},
+			get:           func() string { return strconv.FormatUint(chaos.Seed, 10) },
+			wantType:      "int",
+			wantDef:       "0",
+			accepted:      []acceptedValue{{set: "18446744073709551615", want: "18446744073709551615"}},
+			badValue:      "-1",
+			wantFragments: []string{"seed", "-1", "want a whole number such as 42"},
+			noStrconv:     true,
+		},
+		{
+			name: "nice",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newNiceValue(&nice), "nice", "the nice")
+			},
+			get:      func() string { return strconv.Itoa(nice) },
+			wantType: "int",
+			wantDef:  "0",
+			// Out of range is validate's, as a timeout's is.
+			accepted:      []acceptedValue{{set: "19", want: "19"}, {set: "-20", want: "-20"}, {set: "40", want: "40"}},
+			badValue:      "low",
+			wantFragments: []string{"nice", "low", "want a whole number from -20 to 19"},
+			noStrconv:     true,
+		},
+		{
+			name: "sched",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newSchedValue(&sched), "sched", "the policy")
+			},
+			get:           func() string { return sched },
+			wantType:      "policy",
+			wantDef:       "",
+			accepted:      []acceptedValue{{set: "idle", want: "idle"}, {set: "batch", want: "batch"}},
+			badValue:      "fifo",
+			wantFragments: []string{"sched", "fifo", "want one of other, batch, idle"},
+			noStrconv:     true,
+		},
+		{
+			name: "max-temp",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newTempValue(&maxTemp), "max-temp", "the limit")
+			},
+			get:           func() string { return newTempValue(&maxTemp).String() },
+			wantType:      "temp",
+			wantDef:       "",
+			accepted:      []acceptedValue{{set: "90C", want: "90C"}, {set: "85.5", want: "85.5C"}, {set: "70c", want: "70C"}},
+			badValue:      "-5C",
+			wantFragments: []string{"max-temp", "-5C", "want a temperature in degrees Celsius greater than 0, such as 90C"},
+			noStrconv:     true,
+		},
+		{
+			name: "replay",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newPathValue(&replay), "replay", "the trace")
+			},
+			get:           func() string { return replay },
+			wantType:      "file",
+			wantDef:       "",
+			accepted:      []acceptedValue{{set: "trace.csv", want: "trace.csv"}},
+			badValue:      "",
+			wantFragments: []string{"replay", "want the path of a file"},
+			noStrconv:     true,
+		},
+		{
+			name: "replay-speed",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newSpeedValue(&speed), "replay-speed", "the speed")
+			},
+			get:      func() string { v := speedValue(speed); return v.String() },
+			wantType: "speed",
+			wantDef:  "1x",
+			accepted: []acceptedValue{
+				{set: "10x", want: "10x"},
+				// The x is optional, and a trace may be slowed as well.
+				{set: "0.5", want: "0.5x"},
+			},
+			badValue:      "-2x",
+			wantFragments: []string{"replay-speed", "-2x", "greater than 0, such as 10x"},
+			noStrconv:     true,
+		},
+		{
+			name: "rate",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newRateValue(&perSec), "rate", "the rate")
+			},
+			get:      func() string { return newRateValue(&perSec).String() },
+			wantType: "rate",
+			wantDef:  "0",
+			accepted: []acceptedValue{
+				{set: "50/s", want: "50/s"},
+				// Held, and so printed, a second at a time.
+				{set: "300/m", want: "5/s"},
+				{set: "36/h", want: "0.01/s"},
+				{set: "2.5", want: "2.5/s"},
+				{set: "0", want: "0"},
+			},
+			badValue:      "50/d",
+			wantFragments: []string{"rate", "50/d", "want a number of units a second, minute or hour such as 50/s"},
+			noStrconv:     true,
+		},
+		{
+			name: "alloc-rate",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newSizeValue(&rate, "unlimited", "/s"), "alloc-rate", "the rate")
+			},
+			get:      func() string { return newSizeValue(&rate, "unlimited", "/s").String() },
+			wantType: "rate",
+			wantDef:  "unlimited",
+			accepted: []acceptedValue{
+				{set: "256MiB/s", want: "256MiB/s"},
+				// A second is what a rate is per, said or not.
+				{set: "1GiB", want: "1GiB/s"},
+				{set: "unlimited", want: "unlimited"},
+			},
+			badValue:      "256MB/s",
+			wantFragments: []string{"alloc-rate", "256MB/s", "want unlimited or a rate such as 64MiB/s"},
+			noStrconv:     true,
+		},
+		{
+			name: "heap-target",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newSizeValue(&target, "auto", ""), "heap-target", "the heap")
+			},
+			get:      func() string { return newSizeValue(&target, "auto", "").String() },
+			wantType: "size",
+			wantDef:  "auto",
+			accepted: []acceptedValue{
+				{set: "256MiB", want: "256MiB"},
+				{set: "auto", want: "auto"},
+			},
+			// A rate is not a size.
+			badValue:      "256MiB/s",
+			wantFragments: []string{"heap-target", "256MiB/s", "want auto or a size such as 64MiB"},
+			noStrconv:     true,
+		},
+		{
+			name: "mix",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newMixValue(&mix), "mix", "the groups")
+			},
+			get:      func() string { return newMixValue(&mix).String() },
+			wantType: "groups",
+			wantDef:  "",
+			accepted: []acceptedValue{
+				{set: "bcrypt:4,cache:2", want: "bcrypt:4,cache:2"},
+				// Replaced, not appended to.
+				{set: "gc:1", want: "gc:1"},
+			},
+			// memory is a stressor the request names and stressy does not have.
+			badValue:      "memory:2",
+			wantFragments: []string{"mix", "memory:2", "want stressors from bcrypt, contention, cache, gc, syscall"},
+			noStrconv:     true,
+		},
+		{
+			name: "until",
+			register: func(fs *flag.FlagSet) {
+				fs.Var(newTimeValue(&until), "until", "when to stop")
+			},
+			get:      func() string { return newTimeValue(&until).String() },
+			wantType: "time",
+			// No default, rather than the zero time's year 1.
+			wantDef: "",
+			accepted: []acceptedValue{
+				{set: "2026-01-02T15:04:05Z", want: "2026-01-02T15:04:05Z"},
+				// The offset typed is the one printed back.
+				{set: "2026-01-02T16:04:05+01:00", want: "2026-01-02T16:04:05+01:00"},
+			},
+			// A local time with no zone is a different instant on every node.
+			badValue:      "2026-01-02 15:04:05",
+			wantFragments: []string{"until", "2026-01-02 15:04:05", "want an RFC 3339 time such as 2026-01-02T15:04:05Z"},
+			noStrconv:     true,
+		},
 	}
 
 	for _, tt := range tests {
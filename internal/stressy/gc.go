@@ -0,0 +1,22 @@
+package stressy
+
+import (
+	"fmt"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// gcMessage is the line a gc run adds under its summary.
+func gcMessage(s stress.GCStats) string {
+	return fmt.Sprintf(
+		"GC: %d %s, %.3fms paused in total, longest pause under %.3fms, heap peak %s",
+		s.Cycles, plural(s.Cycles, "cycle", "cycles"),
+		// Milliseconds to the microsecond, always: a pause is tens of
+		// microseconds, and Duration would print those with a µ, where every
+		// line stressy prints is ASCII.
+		s.PauseTotal.Seconds()*1000,
+		s.PauseMax.Seconds()*1000,
+		units.FormatBytes(s.HeapPeak),
+	)
+}
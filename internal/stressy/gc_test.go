@@ -0,0 +1,53 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestGCMessage(t *testing.T) {
+	got := gcMessage(stress.GCStats{Cycles: 1, PauseTotal: 1500 * time.Microsecond, PauseMax: 57 * time.Microsecond, HeapPeak: 96 << 20})
+	want := "GC: 1 cycle, 1.500ms paused in total, longest pause under 0.057ms, heap peak 96.0MiB"
+
+	if got != want {
+		t.Errorf("gcMessage() = %q, want %q", got, want)
+	}
+}
+
+// TestRunReportsTheCollector runs gc through Run: the summary, then the line
+// the probe adds under it, which a heap this size cannot get through without a
+// cycle.
+func TestRunReportsTheCollector(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 2, Timeout: 200 * time.Millisecond, Stressor: "gc", HeapTarget: 8 << 20}, Out: &buf}
+
+	if err := cfg.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	if len(lines) != 4 {
+		t.Fatalf("Run() printed %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+
+	for i, want := range []string{
+		"Starting GC stress test with 2 workers for 200ms",
+		"Timer expired, shutting down; waiting for every worker to finish the batch it is on...",
+		"Computed ",
+		"GC: ",
+	} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d = %q, want it to start with %q", i+1, lines[i], want)
+		}
+	}
+
+	if strings.HasPrefix(lines[3], "GC: 0 cycles") {
+		t.Errorf("Run() reported %q, want the collector to have run", lines[3])
+	}
+}
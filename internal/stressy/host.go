@@ -0,0 +1,73 @@
+package stressy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// hostLines is the block --verbose prints under the startup line: the machine's
+// CPUs, what the process runs on and under, and the cgroup's limits, so a log
+// kept from one node says what the node was without a note beside it.
+//
+//	Host: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz, 2 cores, 4 threads
+//	Runtime: linux/amd64, kernel 6.1.0-18-amd64, go1.25.0, 4 CPUs, GOMAXPROCS 4
+//	Cgroup: 2.00 CPUs, 4GiB memory
+func hostLines(h stress.Host) []string {
+	return []string{"Host: " + cpusClause(h), "Runtime: " + runtimeClause(h), "Cgroup: " + limitsClause(h)}
+}
+
+// cpusClause is the machine's CPUs, "Intel(R) Xeon(R) Platinum 8375C CPU @
+// 2.90GHz, 2 cores, 4 threads", with what /proc/cpuinfo does not say left out.
+func cpusClause(h stress.Host) string {
+	model := h.Model
+	if model == "" {
+		model = "unknown CPU"
+	}
+
+	cpus := []string{model}
+	if h.Cores > 0 {
+		cpus = append(cpus, fmt.Sprintf("%d %s", h.Cores, plural(h.Cores, "core", "cores")))
+	}
+
+	if h.Threads > 0 {
+		cpus = append(cpus, fmt.Sprintf("%d %s", h.Threads, plural(h.Threads, "thread", "threads")))
+	}
+
+	return strings.Join(cpus, ", ")
+}
+
+// runtimeClause is what the process runs on and under, "linux/amd64, kernel
+// 6.1.0-18-amd64, go1.25.0, 4 CPUs, GOMAXPROCS 4".
+func runtimeClause(h stress.Host) string {
+	run := []string{h.OS + "/" + h.Arch}
+	if h.Kernel != "" {
+		run = append(run, "kernel "+h.Kernel)
+	}
+
+	run = append(run, h.Go, fmt.Sprintf("%d %s, GOMAXPROCS %d", h.CPUs, plural(h.CPUs, "CPU", "CPUs"), h.GOMAXPROCS))
+
+	return strings.Join(run, ", ")
+}
+
+// limitsClause is the cgroup's limits, "2.00 CPUs, 4GiB memory", or that it has
+// neither.
+func limitsClause(h stress.Host) string {
+	var limits []string
+
+	if h.CPULimit > 0 {
+		limits = append(limits, fmt.Sprintf("%.2f CPUs", h.CPULimit))
+	}
+
+	if h.MemoryLimit > 0 {
+		limits = append(limits, units.FormatSize(int(h.MemoryLimit))+" memory")
+	}
+
+	if len(limits) == 0 {
+		return "no CPU quota or memory limit"
+	}
+
+	return strings.Join(limits, ", ")
+}
@@ -0,0 +1,81 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestHostLines(t *testing.T) {
+	xeon := stress.Host{
+		Model: "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz", Cores: 2, Threads: 4, CPUs: 4, GOMAXPROCS: 4,
+		OS: "linux", Arch: "amd64", Kernel: "6.1.0-18-amd64", Go: "go1.25.0",
+		CPULimit: 2, MemoryLimit: 4 << 30,
+	}
+
+	// A Mac's: no /proc, and so no model, cores, kernel or cgroup.
+	mac := stress.Host{CPUs: 1, GOMAXPROCS: 1, OS: "darwin", Arch: "arm64", Go: "go1.25.0"}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{
+			name: "linux, in a cgroup",
+			got:  hostLines(xeon),
+			want: []string{
+				"Host: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz, 2 cores, 4 threads",
+				"Runtime: linux/amd64, kernel 6.1.0-18-amd64, go1.25.0, 4 CPUs, GOMAXPROCS 4",
+				"Cgroup: 2.00 CPUs, 4GiB memory",
+			},
+		},
+		{
+			name: "nothing to read",
+			got:  hostLines(mac),
+			want: []string{
+				"Host: unknown CPU",
+				"Runtime: darwin/arm64, go1.25.0, 1 CPU, GOMAXPROCS 1",
+				"Cgroup: no CPU quota or memory limit",
+			},
+		},
+		{
+			name: "a node",
+			got:  []string{nodeHost(xeon)},
+			want: []string{"Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz, 2 cores, 4 threads; linux/amd64, kernel 6.1.0-18-amd64, go1.25.0, 4 CPUs, GOMAXPROCS 4; cgroup: 2.00 CPUs, 4GiB memory"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Join(tt.got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(tt.got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestVerboseStartup holds --verbose's block to where it goes, under the
+// startup line, and to #70: without the flag there is none.
+func TestVerboseStartup(t *testing.T) {
+	ev := stress.StartEvent{Groups: []stress.GroupResult{group("bcrypt", 2)}, Host: stress.Host{CPUs: 1, GOMAXPROCS: 1, OS: "linux", Arch: "amd64", Go: "go1.25.0"}}
+
+	for _, verbose := range []bool{true, false} {
+		var out bytes.Buffer
+
+		textObserver{Cfg{Cfg: stress.Cfg{Workers: 2, Timeout: time.Minute}, Out: &out, Verbose: verbose}}.OnStart(ev)
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+		if verbose && (len(lines) != 4 || !strings.HasPrefix(lines[1], "Host: ") || !strings.HasPrefix(lines[3], "Cgroup: ")) {
+			t.Errorf("OnStart() with --verbose printed:\n%s\nwant the startup line and the host block under it", out.String())
+		}
+
+		if !verbose && len(lines) != 1 {
+			t.Errorf("OnStart() without --verbose printed:\n%s\nwant the startup line alone (#70)", out.String())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package stressy
+
+import (
+	"fmt"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// latencyClause is what the probe adds to every progress line, over every
+// wakeup so far, as the line's own rate is.
+func latencyClause(s stress.LatencyStats) string {
+	return "wakeup latency " + latencyFigures(s)
+}
+
+// latencyMessage is the line the probe adds under the summary.
+func latencyMessage(s stress.LatencyStats) string {
+	thread := ""
+	if s.Locked {
+		thread = " on a locked OS thread"
+	}
+
+	return fmt.Sprintf(
+		"Wakeup latency: %d %s every %s%s, %s",
+		s.Wakeups, plural(s.Wakeups, "wakeup", "wakeups"), units.FormatDuration(s.Interval), thread, latencyFigures(s),
+	)
+}
+
+// latencyFigures is the four figures both lines quote, in milliseconds to the
+// microsecond for the reason gcMessage gives, or what stands in for them
+// before the first wakeup.
+func latencyFigures(s stress.LatencyStats) string {
+	if s.Wakeups == 0 {
+		return "not measured yet"
+	}
+
+	return fmt.Sprintf(
+		"%.3fms min, %.3fms avg, %.3fms p99, %.3fms max",
+		s.Min.Seconds()*1000,
+		s.Avg.Seconds()*1000,
+		s.P99.Seconds()*1000,
+		s.Max.Seconds()*1000,
+	)
+}
@@ -0,0 +1,101 @@
+package stressy
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestLatencyMessages(t *testing.T) {
+	none := stress.LatencyStats{Interval: 500 * time.Microsecond}
+
+	if got, want := latencyClause(none), "wakeup latency not measured yet"; got != want {
+		t.Errorf("latencyClause() of no wakeups = %q, want %q", got, want)
+	}
+
+	s := stress.LatencyStats{
+		Interval: 500 * time.Microsecond,
+		Locked:   true,
+		Wakeups:  101,
+		Avg:      98514 * time.Nanosecond,
+		P99:      51199 * time.Nanosecond,
+		Max:      5 * time.Millisecond,
+	}
+
+	if got, want := latencyClause(s), "wakeup latency 0.000ms min, 0.099ms avg, 0.051ms p99, 5.000ms max"; got != want {
+		t.Errorf("latencyClause() = %q, want %q", got, want)
+	}
+
+	// The interval in ASCII, as every line stressy prints is.
+	if got, want := latencyMessage(s), "Wakeup latency: 101 wakeups every 500us on a locked OS thread, 0.000ms min, 0.099ms avg, 0.051ms p99, 5.000ms max"; got != want {
+		t.Errorf("latencyMessage() = %q, want %q", got, want)
+	}
+}
+
+// TestLatencyProbeReportsInProgressLines runs the text observer under the stress
+// package directly, which has no floor on Report, for progress lines inside a
+// test's time: every one carries the probe's clause, and the summary its line.
+func TestLatencyProbeReportsInProgressLines(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 120 * time.Millisecond, LatencyProbe: time.Millisecond, Report: 50 * time.Millisecond}, Out: &buf}
+
+	run := cfg.Cfg
+	run.Observer = textObserver{cfg}
+
+	if _, err := run.RunContext(context.Background()); err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	var progress int
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	for _, line := range lines {
+		if strings.Contains(line, " elapsed, ") {
+			progress++
+
+			if !strings.Contains(line, " hashes/s; wakeup latency ") {
+				t.Errorf("progress line %q carries no wakeup latency", line)
+			}
+		}
+	}
+
+	if progress == 0 {
+		t.Errorf("the run printed:\n%s\nwant progress lines", buf.String())
+	}
+
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "Wakeup latency: ") || !strings.Contains(last, " wakeups every 1ms, ") {
+		t.Errorf("the run's last line = %q, want the Wakeup latency line", last)
+	}
+}
+
+// TestRunReportsWakeupLatency: the probe's line goes under the summary, locked
+// to its thread as asked, and the rest of the output is what it was.
+func TestRunReportsWakeupLatency(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 200 * time.Millisecond, LatencyProbe: time.Millisecond, LatencyLocked: true}, Out: &buf}
+
+	if err := cfg.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	if len(lines) != 4 {
+		t.Fatalf("Run() printed %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+
+	if !strings.HasPrefix(lines[2], "Computed ") {
+		t.Errorf("line 3 = %q, want the summary", lines[2])
+	}
+
+	if !strings.HasPrefix(lines[3], "Wakeup latency: ") || !strings.Contains(lines[3], " every 1ms on a locked OS thread, ") {
+		t.Errorf("line 4 = %q, want the locked probe's line", lines[3])
+	}
+}
@@ -0,0 +1,132 @@
+package stressy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// mixWorkers is every worker in the mix, which is what the startup line and
+// the summary quote for the run as a whole.
+func mixWorkers(groups []stress.GroupResult) int {
+	var n int
+	for _, g := range groups {
+		n += g.Workers
+	}
+
+	return n
+}
+
+// mixStartupMessage is startupMessage for a mix: the workers in all, then each
+// group's, and the variants of any group that takes turns between them.
+func mixStartupMessage(groups []stress.GroupResult, duration string) string {
+	parts := make([]string, len(groups))
+	for i, g := range groups {
+		parts[i] = fmt.Sprintf("%d %s", g.Workers, g.Stressor)
+
+		if clause := variantClause(g); clause != "" {
+			parts[i] += " (" + clause + ")"
+		}
+	}
+
+	n := mixWorkers(groups)
+
+	return fmt.Sprintf("Starting mixed stress test with %d %s %s: %s", n, plural(n, "worker", "workers"), duration, strings.Join(parts, ", "))
+}
+
+// mixProgressMessage is progressMessage for a mix: a count and a rate for each
+// group, each in its own units, because a total of hashes and accesses added
+// together counts nothing.
+func mixProgressMessage(r stress.Result) string {
+	parts := make([]string, len(r.Groups))
+
+	for i, g := range r.Groups {
+		s := describe(g.Stressor)
+		parts[i] = fmt.Sprintf(
+			"%s %d %s at %.1f %s/s",
+			s.Name, g.Count, plural(g.Count, s.Unit, s.Units), rate(g.Count, r.Elapsed-r.Paused), s.Units,
+		)
+	}
+
+	return fmt.Sprintf("%s elapsed, %s%s", r.Elapsed.Round(time.Millisecond), strings.Join(parts, ", "), pausedClause(r.Paused))
+}
+
+// mixSummaryLines is the summary of a mix. Its first line starts with
+// "Computed " as every summary does, and carries no count, for the reason the
+// progress line carries no total; a line per group follows, with the lines of
+// its variants under it.
+func mixSummaryLines(r stress.Result) []string {
+	n := mixWorkers(r.Groups)
+
+	lines := []string{fmt.Sprintf(
+		"Computed a mix of %d stressors in %s (%d %s%s)",
+		len(r.Groups), r.Elapsed.Round(time.Millisecond), n, plural(n, "worker", "workers"), pausedClause(r.Paused),
+	)}
+
+	for _, g := range r.Groups {
+		s := describe(g.Stressor)
+
+		lines = append(lines, fmt.Sprintf(
+			"Group %s: %d %s in %s (%.1f %s/s, %d %s)",
+			s.Name, g.Count, plural(g.Count, s.Unit, s.Units),
+			r.Elapsed.Round(time.Millisecond),
+			rate(g.Count, r.Elapsed-r.Paused), s.Units,
+			g.Workers, plural(g.Workers, "worker", "workers"),
+		))
+
+		lines = append(lines, variantLines(g)...)
+	}
+
+	return lines
+}
+
+// step is what the shutdown line says the drain is waiting on: the step of each
+// mode the run has, or for a mix of each group's, once each — "the hash or
+// batch it is on". A syscall run with exec is "the batch or exec it is on".
+func (c Cfg) step() string {
+	if len(c.Mix) == 0 {
+		return strings.Join(modeSteps(c.Stressor, c.Modes), " or ")
+	}
+
+	var steps []string
+
+	for _, m := range c.Mix {
+		for _, step := range modeSteps(m.Stressor, c.Modes) {
+			if !slices.Contains(steps, step) {
+				steps = append(steps, step)
+			}
+		}
+	}
+
+	return strings.Join(steps, " or ")
+}
+
+// modeSteps is the steps of a run of stressor given modes, once each: the
+// stressor's Step, or where its modes differ in what a unit is, those of the
+// modes the run takes, every one where modes is empty. A mix's modes go to each
+// group whose stressor has them, so one naming none of a group's is no mode of
+// that group's.
+func modeSteps(stressor string, modes []string) []string {
+	s := describe(stressor)
+
+	var steps []string
+
+	for i, mode := range s.Modes {
+		if len(modes) > 0 && !slices.Contains(modes, mode) {
+			continue
+		}
+
+		if !slices.Contains(steps, s.ModeSteps[i]) {
+			steps = append(steps, s.ModeSteps[i])
+		}
+	}
+
+	if len(steps) == 0 {
+		return []string{s.Step}
+	}
+
+	return steps
+}
@@ -0,0 +1,97 @@
+package stressy
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestMixMessages(t *testing.T) {
+	cfg := Cfg{Cfg: stress.Cfg{Timeout: 30 * time.Second, Mix: []stress.Group{{Stressor: "bcrypt", Workers: 4}, {Stressor: "contention", Workers: 2}, {Stressor: "cache", Workers: 1}}, Modes: []string{"atomic", "mutex"}, WorkingSets: []int{32 << 10}}}
+
+	r := stress.Result{
+		Elapsed: 2 * time.Second,
+		Groups: []stress.GroupResult{
+			{Stressor: "bcrypt", Workers: 4, Count: 8},
+			{Stressor: "contention", Workers: 2, Variants: []stress.VariantResult{{Name: "atomic"}, {Name: "mutex"}}},
+			{Stressor: "cache", Workers: 1, Count: 1000, Variants: []stress.VariantResult{{Name: "32KiB", Count: 1000, Spent: 2 * time.Second}}},
+		},
+	}
+
+	if got, want := cfg.startupMessage(r.Groups), "Starting mixed stress test with 7 workers for 30s: 4 bcrypt, 2 contention (modes atomic, mutex), 1 cache (working set 32KiB)"; got != want {
+		t.Errorf("startupMessage() = %q, want %q", got, want)
+	}
+
+	// Each step once, in the order of the groups.
+	if got, want := cfg.shutdownMessage(nil), "Timer expired, shutting down; waiting for every worker to finish the hash or batch or walk it is on..."; got != want {
+		t.Errorf("shutdownMessage(nil) = %q, want %q", got, want)
+	}
+
+	if got, want := cfg.progressLine(r), "2s elapsed, bcrypt 8 hashes at 4.0 hashes/s, contention 0 ops at 0.0 ops/s, cache 1000 accesses at 500.0 accesses/s"; got != want {
+		t.Errorf("progressLine() = %q, want %q", got, want)
+	}
+
+	want := []string{
+		"Computed a mix of 3 stressors in 2s (7 workers)",
+		"Group bcrypt: 8 hashes in 2s (4.0 hashes/s, 4 workers)",
+		"Group contention: 0 ops in 2s (0.0 ops/s, 2 workers)",
+		"Mode atomic: 0 ops in 0s (0.0 ops/s)",
+		"Mode mutex: 0 ops in 0s (0.0 ops/s)",
+		"Group cache: 1000 accesses in 2s (500.0 accesses/s, 1 worker)",
+		"Working set 32KiB: 1000 accesses in 2s (500.0 accesses/s, 2000000.0 ns/access)",
+	}
+
+	if got := cfg.summaryLines(r); !slices.Equal(got, want) {
+		t.Errorf("summaryLines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A second of the two paused: every group's rate doubles, and the lines
+	// that carry the run's say so.
+	r.Elapsed, r.Paused = 2*time.Second, time.Second
+
+	if got, want := cfg.progressLine(r), "2s elapsed, bcrypt 8 hashes at 8.0 hashes/s, contention 0 ops at 0.0 ops/s, cache 1000 accesses at 1000.0 accesses/s, 1s paused"; got != want {
+		t.Errorf("progressLine() of a paused run = %q, want %q", got, want)
+	}
+
+	if got, want := cfg.summaryLines(r)[:2], []string{"Computed a mix of 3 stressors in 2s (7 workers, 1s paused)", "Group bcrypt: 8 hashes in 2s (8.0 hashes/s, 4 workers)"}; !slices.Equal(got, want) {
+		t.Errorf("summaryLines() of a paused run =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestRunReportsEveryGroup runs a mix through Run: one shutdown, one drain, and
+// a summary with a line per group and the gc group's probe under it all.
+func TestRunReportsEveryGroup(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := Cfg{Cfg: stress.Cfg{Timeout: 200 * time.Millisecond, Mix: []stress.Group{{Stressor: "contention", Workers: 1}, {Stressor: "gc", Workers: 1}}, Modes: []string{"padded"}, HeapTarget: 4 << 20}, Out: &buf}
+
+	if err := cfg.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	want := []string{
+		"Starting mixed stress test with 2 workers for 200ms: 1 contention (mode padded), 1 gc",
+		"Timer expired, shutting down; waiting for every worker to finish the batch it is on...",
+		"Computed a mix of 2 stressors in ",
+		"Group contention: ",
+		"Mode padded: ",
+		"Group gc: ",
+		"GC: ",
+	}
+
+	if len(lines) != len(want) {
+		t.Fatalf("Run() printed %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+
+	for i := range want {
+		if !strings.HasPrefix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to start with %q", i+1, lines[i], want[i])
+		}
+	}
+}
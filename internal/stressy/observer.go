@@ -0,0 +1,214 @@
+package stressy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// textObserver is the Observer a run has where Cfg names none: the lines stressy
+// has always printed, every one of them to Out. Each is built by a message
+// function on Cfg, from the event alone.
+type textObserver struct {
+	Cfg
+}
+
+func (o textObserver) OnStart(ev stress.StartEvent) {
+	writef(o.Out, "%s\n", o.startupMessage(ev.Groups))
+
+	if hint := o.hintMessage(); hint != "" {
+		writef(o.Out, "%s\n", hint)
+	}
+
+	if o.Verbose {
+		for _, line := range hostLines(ev.Host) {
+			writef(o.Out, "%s\n", line)
+		}
+	}
+}
+
+func (o textObserver) OnProgress(r stress.Result) {
+	writef(o.Out, "%s\n", o.progressLine(r))
+}
+
+// OnPause says what the pause holds and how it ends, because the signal that
+// asked for it printed nothing of its own.
+func (o textObserver) OnPause(ev stress.PauseEvent) {
+	writef(o.Out, "%s\n", o.pauseMessage(ev))
+}
+
+func (o textObserver) OnResume(ev stress.PauseEvent) {
+	writef(o.Out, "%s\n", resumeMessage(ev))
+}
+
+func (o textObserver) OnBurst(ev stress.BurstEvent) {
+	writef(o.Out, "%s\n", o.turnMessage(ev))
+}
+
+func (o textObserver) OnSegment(ev stress.SegmentEvent) {
+	writef(o.Out, "%s\n", segmentMessage(ev))
+}
+
+// OnShutdown names the signal where one ended the run, which the signal gate
+// hands on as the event's cause. A run cancelled for any other cause is an
+// agent's, stopped by its coordinator or by losing it, and says which.
+func (o textObserver) OnShutdown(ev stress.ShutdownEvent) {
+	var signalled *SignalError
+
+	switch {
+	case errors.As(ev.Cause, &signalled):
+		writef(o.Out, "%s\n", o.shutdownMessage(signalled.Signal))
+	case ev.Reason == stress.StopCount:
+		writef(o.Out, "%s\n", o.countMessage())
+	case ev.Reason == stress.StopTemp:
+		writef(o.Out, "%s\n", o.tempMessage(ev.Cause))
+	case ev.Reason == stress.StopStall:
+		writef(o.Out, "%s\n", o.stallMessage(ev.Cause))
+	case ev.Reason == stress.StopCanceled:
+		writef(o.Out, "%s, shutting down; %s\n", stoppedBy(ev.Cause), fmt.Sprintf(drainNotice, o.step()))
+	default:
+		writef(o.Out, "%s\n", o.shutdownMessage(nil))
+	}
+}
+
+func (o textObserver) OnSummary(r stress.Result) {
+	for _, line := range o.summaryLines(r) {
+		writef(o.Out, "%s\n", line)
+	}
+}
+
+// signalGate is the Observer a run of the command hands the stress package: the
+// one it reports to, behind a gate that settles whether a signal or the timer
+// ended the run before that one is told which. The run knows only its context,
+// and a signal that lands as the deadline expires has not cancelled it yet; the
+// gate is where #117's answer to that, the signal, is still given.
+type signalGate struct {
+	stress.Observer
+
+	received chan os.Signal
+
+	// control is where the signals that do not end a run arrive, and nil
+	// where the platform has none.
+	control chan os.Signal
+
+	// ended is closed by OnShutdown, which then waits on decided for what
+	// waitForShutdown made of it.
+	ended   chan struct{}
+	decided chan os.Signal
+
+	// sig is the signal that ended the run, or nil for the timer. Written in
+	// OnShutdown and read once the run's Wait has returned, which is after.
+	sig os.Signal
+
+	// halt is the cause of a run a limit of its own ended, --max-temp's or
+	// --stall-timeout's, and nil for any other; written and read as sig is.
+	halt error
+}
+
+func newSignalGate(obs stress.Observer, received, control chan os.Signal) *signalGate {
+	return &signalGate{
+		Observer: obs,
+		received: received,
+		control:  control,
+		ended:    make(chan struct{}),
+		decided:  make(chan os.Signal, 1),
+	}
+}
+
+// watch waits for the run's shutdown in a goroutine of its own, cancelling the
+// run with the signal where one comes first, and answering the control signals
+// for r until then.
+func (g *signalGate) watch(cancel context.CancelCauseFunc, r *stress.Run) {
+	go func() {
+		sig := waitForShutdown(g.ended, g.received, g.control, func(sig os.Signal) { answerSignal(r, sig) })
+
+		// One shutdown is all this run has to report, and everything after it
+		// is the drain. Handling stops here rather than at Run's deferred call,
+		// which does not run until the drain is over: for the whole of a wait
+		// that grows with Workers, a second signal landed in a one-deep buffer
+		// nobody read again and the run could be stopped by nothing but SIGKILL
+		// (#122).
+		//
+		// Stopped rather than drained, so the signal goes back to the
+		// disposition it had before Notify — the default, for anything a
+		// terminal, a `docker stop` or a kubelet signals — and the second one
+		// ends the process where it stands. On the timer path too, where the run
+		// was going to exit 0 and the operator pressing Ctrl-C through the drain
+		// asked for something else.
+		//
+		// Before the shutdown line rather than after it, so the log says the run
+		// is draining only once a signal can in fact interrupt the drain.
+		signal.Stop(g.received)
+
+		// Tells the workers to stop on the signal path. On the timer path the
+		// run is already stopping, and there is nothing to cancel.
+		if sig != nil {
+			cancel(&SignalError{Signal: sig})
+		}
+
+		g.decided <- sig
+	}()
+}
+
+func (g *signalGate) OnShutdown(ev stress.ShutdownEvent) {
+	close(g.ended)
+
+	g.sig = <-g.decided
+
+	// The run may have seen its deadline first; the gate's answer is the one
+	// the line and the exit code go by.
+	if g.sig != nil {
+		ev.Reason, ev.Cause = stress.StopCanceled, &SignalError{Signal: g.sig}
+	}
+
+	if ev.Reason == stress.StopTemp || ev.Reason == stress.StopStall {
+		g.halt = ev.Cause
+	}
+
+	g.Observer.OnShutdown(ev)
+}
+
+// The events a run looks for on its Observer are passed on where the one
+// behind the gate takes them; embedding hides them from the run otherwise.
+
+func (g *signalGate) OnPause(ev stress.PauseEvent) {
+	if o, ok := g.Observer.(stress.PauseObserver); ok {
+		o.OnPause(ev)
+	}
+}
+
+func (g *signalGate) OnResume(ev stress.PauseEvent) {
+	if o, ok := g.Observer.(stress.PauseObserver); ok {
+		o.OnResume(ev)
+	}
+}
+
+func (g *signalGate) OnBurst(ev stress.BurstEvent) {
+	if o, ok := g.Observer.(stress.BurstObserver); ok {
+		o.OnBurst(ev)
+	}
+}
+
+func (g *signalGate) OnSegment(ev stress.SegmentEvent) {
+	if o, ok := g.Observer.(stress.SegmentObserver); ok {
+		o.OnSegment(ev)
+	}
+}
+
+// answerSignal is what a run does on a signal that does not end it: a progress
+// line for reportSignal, and for pauseSignal a pause, or the resume of one — the
+// signal that paused a run is the one that sets it going again.
+func answerSignal(r *stress.Run, sig os.Signal) {
+	switch sig {
+	case reportSignal:
+		r.Report()
+	case pauseSignal:
+		if !r.Pause() {
+			r.Resume()
+		}
+	}
+}
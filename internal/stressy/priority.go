@@ -0,0 +1,20 @@
+package stressy
+
+import "fmt"
+
+// priorityClause is what --nice and --sched add to the startup line: ", nice
+// 19, sched idle", either alone where only one was given, or "" for workers
+// at the process's priority.
+func (c Cfg) priorityClause() string {
+	var clause string
+
+	if c.Nice != 0 {
+		clause += fmt.Sprintf(", nice %d", c.Nice)
+	}
+
+	if c.Sched != "" {
+		clause += ", sched " + c.Sched
+	}
+
+	return clause
+}
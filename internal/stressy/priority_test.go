@@ -0,0 +1,53 @@
+package stressy
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestPriorityMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "neither", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1m0s"},
+		{name: "both", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute, Nice: 19, Sched: "idle"}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1m0s, nice 19, sched idle"},
+		{name: "nice alone, raised", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute, Nice: -5}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1m0s, nice -5"},
+		{name: "a mix", got: Cfg{Cfg: stress.Cfg{Timeout: time.Minute, Sched: "batch", Mix: []stress.Group{{Stressor: "bcrypt", Workers: 2}, {Stressor: "gc", Workers: 1}}}}.startupMessage([]stress.GroupResult{group("bcrypt", 2), group("gc", 1)}), want: "Starting mixed stress test with 3 workers for 1m0s: 2 bcrypt, 1 gc, sched batch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPriorityIsCheckedBeforeTheRun covers a nice the command turns away
+// before anything starts: one out of range on Linux, and any elsewhere.
+func TestPriorityIsCheckedBeforeTheRun(t *testing.T) {
+	want := "nice must be from -20 to 19"
+	if runtime.GOOS != "linux" {
+		want = "nice and sched set the workers' threads' priority on Linux, and this is " + runtime.GOOS
+	}
+
+	var cfg Cfg
+	cmd := newTestCmd(t, &cfg)
+
+	var ran bool
+	cmd.run = func(*Cfg) error { ran = true; return nil }
+
+	if err := cmd.execute([]string{"-t", "1m", "--nice", "20"}); err == nil || err.Error() != want {
+		t.Errorf("execute(--nice 20) error = %v, want %q", err, want)
+	}
+
+	if ran {
+		t.Error("execute(--nice 20) ran the stress test, want it turned away first")
+	}
+}
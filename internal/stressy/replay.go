@@ -0,0 +1,215 @@
+package stressy
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// loadReplay reads the trace c.Replay names into c.Shape, and where no
+// --timeout or --until was given makes the trace's length the run's, so the
+// startup line and the bounds on --report have one to go by. It does nothing
+// without a Replay, or where the Shape is already read, so a command's check
+// and Run can both call it.
+func (c *Cfg) loadReplay() error {
+	if c.Replay == "" || len(c.Shape) > 0 {
+		return nil
+	}
+
+	f, err := os.Open(c.Replay)
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	defer f.Close()
+
+	speed := c.ReplaySpeed
+	if speed == 0 {
+		speed = 1
+	}
+
+	shape, err := readTrace(f, c.Workers, speed)
+	if err != nil {
+		return fmt.Errorf("replay %s: %w", c.Replay, err)
+	}
+
+	c.Shape = shape
+
+	if c.Timeout == 0 && c.Until.IsZero() {
+		for _, s := range shape {
+			c.Timeout += s.Length
+		}
+	}
+
+	return nil
+}
+
+// readTrace reads a trace of CPU utilisation into the segments that replay it
+// on workers workers, speed times faster than it was recorded.
+//
+// A trace is CSV, a row per sample: when it was taken, as an RFC 3339 time,
+// "2006-01-02 15:04:05" or Unix seconds, and the utilisation then, in percent
+// of the workers or in cores. A sample lasts until the next, and the last as
+// long as the one before it. A first row that is not a sample is a header, and
+// its second column, percent or cores, says which a bare number is; a number
+// with a % is percent whatever the header says. A blank line and one starting
+// with # are skipped, so an export with notes at the top reads as it is.
+//
+// Percent is of the workers rather than of the machine, which is not read
+// (#104): a trace from a node of 8 cores is replayed on -w 8, and 50% of it is 4
+// workers' worth.
+func readTrace(r io.Reader, workers int, speed float64) ([]stress.Segment, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var (
+		unit    string // "percent" or "cores", from the header
+		times   []time.Time
+		loads   []float64
+		lines   []int
+		started bool
+	)
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: want a time and a utilisation, such as 2026-01-02T15:04:00Z,42.5%%", line)
+		}
+
+		at, ok := parseTraceTime(record[0])
+		if !ok {
+			if started {
+				return nil, fmt.Errorf("line %d: want a time as RFC 3339, 2006-01-02 15:04:05 or Unix seconds", line)
+			}
+
+			started = true
+
+			switch unit = strings.ToLower(strings.TrimSpace(record[1])); unit {
+			case "percent", "cores":
+			default:
+				return nil, fmt.Errorf("line %d: want a header whose second column is percent or cores", line)
+			}
+
+			continue
+		}
+
+		started = true
+
+		cores, err := parseTraceLoad(record[1], unit, workers)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if n := len(times); n > 0 && !at.After(times[n-1]) {
+			return nil, fmt.Errorf("line %d: %s is not after the sample before it", line, record[0])
+		}
+
+		times, loads, lines = append(times, at), append(loads, cores), append(lines, line)
+	}
+
+	// One sample says nothing about how long it lasted.
+	if len(times) < 2 {
+		return nil, errors.New("want two samples at least, so the trace says how long each lasts")
+	}
+
+	shape := make([]stress.Segment, len(times))
+
+	for i := range times {
+		// The last lasts as long as the one before it.
+		j := min(i, len(times)-2)
+
+		length := time.Duration(float64(times[j+1].Sub(times[j])) / speed)
+		if length <= 0 {
+			return nil, fmt.Errorf("line %d: a sample at %gx lasts less than a nanosecond", lines[i], speed)
+		}
+
+		shape[i] = stress.NewSegment(length, loads[i])
+	}
+
+	return shape, nil
+}
+
+// parseTraceTime reads the time of a sample.
+func parseTraceTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range []string{time.RFC3339, time.DateTime} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(secs, 0) || math.IsNaN(secs) {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(secs*float64(time.Second))), true
+}
+
+// parseTraceLoad reads the utilisation of a sample, in unit where it is a bare
+// number, as cores of workers.
+func parseTraceLoad(s, unit string, workers int) (float64, error) {
+	s = strings.TrimSpace(s)
+
+	pct, percent := strings.CutSuffix(s, "%")
+	if percent {
+		s, unit = pct, "percent"
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(n >= 0) || math.IsInf(n, 0) {
+		return 0, errors.New("want a utilisation of 0 or more, in percent such as 42.5% or in cores such as 2.5")
+	}
+
+	switch unit {
+	case "percent":
+		if n > 100 {
+			return 0, fmt.Errorf("%g%% is more than all of the workers", n)
+		}
+
+		return n / 100 * float64(workers), nil
+	case "cores":
+		if n > float64(workers) {
+			return 0, fmt.Errorf("%g cores is more than the %d %s replaying it; raise -w", n, workers, plural(workers, "worker", "workers"))
+		}
+
+		return n, nil
+	}
+
+	return 0, fmt.Errorf("want a header of percent or cores to say what %g is in, or %g%% for percent", n, n)
+}
+
+// replayClause is what --replay adds to the startup line: the trace, and how
+// much faster it goes than it was recorded where that is not 1x.
+func (c Cfg) replayClause() string {
+	if c.Replay == "" {
+		return ""
+	}
+
+	clause := ", replaying " + c.Replay
+	if speed := speedValue(c.ReplaySpeed); speed != 0 && speed != 1 {
+		clause += " at " + speed.String()
+	}
+
+	return clause
+}
@@ -0,0 +1,221 @@
+package stressy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestReadTrace(t *testing.T) {
+	minute := time.Minute
+
+	tests := []struct {
+		name    string
+		trace   string
+		workers int
+		speed   float64
+		want    []stress.Segment
+		// wantErr is a fragment of the error, where the trace is turned away.
+		wantErr string
+	}{
+		{
+			name:    "percent, RFC 3339",
+			trace:   "2026-01-02T15:04:00Z,50%\n2026-01-02T15:05:00Z,100%\n",
+			workers: 4, speed: 1,
+			want: []stress.Segment{{Length: minute, Workers: 2, Duty: 1}, {Length: minute, Workers: 4, Duty: 1}},
+		},
+		{
+			name:    "cores under a header, with notes and a blank line",
+			trace:   "# node-7, exported 2026-01-02\n\ntime,cores\n2026-01-02 15:04:00,2.5\n2026-01-02 15:04:30,0\n2026-01-02 15:05:30,1\n",
+			workers: 4, speed: 1,
+			want: []stress.Segment{
+				{Length: 30 * time.Second, Workers: 3, Duty: 2.5 / 3},
+				{Length: minute},
+				{Length: minute, Workers: 1, Duty: 1},
+			},
+		},
+		{
+			name:    "Unix seconds, sped up",
+			trace:   "1767366240,25%\n1767366300,75%\n",
+			workers: 4, speed: 10,
+			want: []stress.Segment{{Length: 6 * time.Second, Workers: 1, Duty: 1}, {Length: 6 * time.Second, Workers: 3, Duty: 1}},
+		},
+		{
+			name:    "a percent header, with a bare number",
+			trace:   "when,percent\n0,50\n10,25\n",
+			workers: 2, speed: 1,
+			want: []stress.Segment{{Length: 10 * time.Second, Workers: 1, Duty: 1}, {Length: 10 * time.Second, Workers: 1, Duty: 0.5}},
+		},
+		{name: "one sample", trace: "0,50%\n", workers: 1, speed: 1, wantErr: "want two samples at least"},
+		{name: "empty", trace: "", workers: 1, speed: 1, wantErr: "want two samples at least"},
+		{name: "one column", trace: "0\n10\n", workers: 1, speed: 1, wantErr: "line 1: want a time and a utilisation"},
+		{name: "a bare number without a header", trace: "0,2\n10,1\n", workers: 4, speed: 1, wantErr: "line 1: want a header of percent or cores"},
+		{name: "an unknown header", trace: "time,load\n0,2\n10,1\n", workers: 4, speed: 1, wantErr: "line 1: want a header whose second column is percent or cores"},
+		{name: "a bad time past the first line", trace: "0,50%\nnoon,25%\n", workers: 1, speed: 1, wantErr: "line 2: want a time"},
+		{name: "out of order", trace: "10,50%\n0,25%\n", workers: 1, speed: 1, wantErr: "line 2: 0 is not after the sample before it"},
+		{name: "past every worker", trace: "0,150%\n10,25%\n", workers: 1, speed: 1, wantErr: "line 1: 150% is more than all of the workers"},
+		{name: "more cores than workers", trace: "time,cores\n0,6\n10,1\n", workers: 4, speed: 1, wantErr: "line 2: 6 cores is more than the 4 workers replaying it; raise -w"},
+		{name: "negative", trace: "0,-5%\n10,25%\n", workers: 1, speed: 1, wantErr: "line 1: want a utilisation of 0 or more"},
+		{name: "sped past a nanosecond", trace: "0,50%\n0.000000001,25%\n", workers: 1, speed: 10, wantErr: "lasts less than a nanosecond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readTrace(strings.NewReader(tt.trace), tt.workers, tt.speed)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readTrace() error = %v, want one containing %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("readTrace() error = %v, want nil", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("readTrace() = %+v, want %+v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i].Length != tt.want[i].Length || got[i].Workers != tt.want[i].Workers || !near(got[i].Duty, tt.want[i].Duty) {
+					t.Errorf("segment %d = %+v, want %+v", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// near reports whether a and b are the same duty, but for rounding.
+func near(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }
+
+func TestReplayMessages(t *testing.T) {
+	segment := stress.Segment{Length: 6 * time.Second, Workers: 3, Duty: 2.5 / 3}
+	shape := []stress.Segment{segment, {Length: 6 * time.Second}}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "startup", got: Cfg{Cfg: stress.Cfg{Timeout: 12 * time.Second, Shape: shape}, Replay: "trace.csv", ReplaySpeed: 1}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 12s, replaying trace.csv"},
+		{name: "startup, sped up", got: Cfg{Cfg: stress.Cfg{Timeout: 12 * time.Second, Shape: shape}, Replay: "trace.csv", ReplaySpeed: 10}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 12s, replaying trace.csv at 10x"},
+		{name: "segment", got: segmentMessage(stress.SegmentEvent{Segments: 2, Segment: segment}), want: "Segment 1 of 2 at 0s elapsed: 2.50 cores, 3 workers at 83% duty, for 6s"},
+		{name: "segment, held", got: segmentMessage(stress.SegmentEvent{Index: 1, Segments: 2, Elapsed: 6001234 * time.Microsecond, Segment: shape[1]}), want: "Segment 2 of 2 at 6.001s elapsed: 0.00 cores, every worker held, for 6s"},
+		{name: "segment, one worker", got: segmentMessage(stress.SegmentEvent{Segments: 1, Segment: stress.Segment{Length: time.Second, Workers: 1, Duty: 1}}), want: "Segment 1 of 1 at 0s elapsed: 1.00 cores, 1 worker at 100% duty, for 1s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	// Averaged over the time each segment ran, so the 2s the last had before
+	// the run ended count for a third of what the first 6s did.
+	got := shapeLines(stress.Result{Shape: []stress.SegmentResult{
+		{Segment: segment, Ran: 6 * time.Second, Offered: 2.47},
+		{Segment: stress.Segment{Length: 6 * time.Second, Workers: 1, Duty: 1}, Start: 6 * time.Second, Ran: 2 * time.Second, Offered: 0.99},
+	}})
+
+	want := []string{
+		"Shape: 2 segments, 2.12 cores asked and 2.10 offered on average",
+		"Segment 1 at 0s for 6s: 2.50 cores asked, 2.47 offered",
+		"Segment 2 at 6s for 2s: 1.00 cores asked, 0.99 offered",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("shapeLines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestRunReplaysATrace runs --replay from end to end: the trace's length is the
+// run's, every segment is announced in order, and the summary ends with what
+// each offered.
+func TestRunReplaysATrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.csv")
+	if err := os.WriteFile(path, []byte("time,percent\n0,100\n1,0\n2,50\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+
+	c := Cfg{
+		Cfg:    stress.Cfg{Workers: 2, Stressor: "contention", Modes: []string{"atomic"}},
+		Out:    &out,
+		Replay: path, ReplaySpeed: 10,
+	}
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	want := []string{
+		"Starting contention stress test with 2 workers for 300ms, mode atomic, replaying " + path + " at 10x",
+		"Segment 1 of 3 at 0s elapsed: 2.00 cores",
+		"Segment 2 of 3 at ",
+		"Segment 3 of 3 at ",
+		"Timer expired",
+	}
+	for i, w := range want {
+		if i >= len(lines) || !strings.HasPrefix(lines[i], w) {
+			t.Fatalf("Run() printed:\n%s\nwant line %d to start %q", out.String(), i, w)
+		}
+	}
+
+	tail := lines[len(lines)-4:]
+	if !strings.HasPrefix(tail[0], "Shape: 3 segments, ") || !strings.HasPrefix(tail[3], "Segment 3 at ") {
+		t.Errorf("the last lines = %q, want the shape and its segments", tail)
+	}
+}
+
+// TestReplayIsCheckedBeforeTheRun covers a trace the command turns away before
+// anything starts: one it cannot read, and one the run's workers cannot keep.
+func TestReplayIsCheckedBeforeTheRun(t *testing.T) {
+	dir := t.TempDir()
+
+	cores := filepath.Join(dir, "cores.csv")
+	if err := os.WriteFile(cores, []byte("time,cores\n0,2\n60,1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "a missing file", args: []string{"--replay", filepath.Join(dir, "nope.csv")}, want: "no such file"},
+		{name: "more cores than workers", args: []string{"-w", "1", "--replay", cores}, want: "line 2: 2 cores is more than the 1 worker replaying it; raise -w"},
+		{name: "a timeout past the trace", args: []string{"-w", "2", "-t", "5m", "--replay", cores}, want: "timeout 5m0s is longer than the shape's 2m0s, so the run would outlast it"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Cfg
+			cmd := newTestCmd(t, &cfg)
+
+			var ran bool
+			cmd.run = func(*Cfg) error { ran = true; return nil }
+
+			err := cmd.execute(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("execute(%q) error = %v, want one containing %q", tt.args, err, tt.want)
+			}
+
+			if ran {
+				t.Errorf("execute(%q) ran the stress test, want it turned away first", tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package stressy
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// validateWindow holds StartAt and Until to a window a run can still have, as
+// of now. A start that has gone by is not one the run could keep: started late,
+// it is seconds out from the nodes it was meant to start with, which is the
+// skew the flag is there to take away. An end that has gone by, or that comes
+// no later than the start, leaves the run no time at all. Until and Timeout
+// both say when the run ends, so a command line giving both is one of them
+// going unheard, whichever that would be.
+func (c Cfg) validateWindow(now time.Time) error {
+	switch {
+	case !c.StartAt.IsZero() && !c.StartAt.After(now):
+		return fmt.Errorf("start at %s is in the past", c.StartAt.Format(time.RFC3339))
+	case c.Until.IsZero():
+		return nil
+	case c.Timeout > 0:
+		return fmt.Errorf("until and timeout both say when the run ends; give one of them")
+	case !c.Until.After(now):
+		return fmt.Errorf("until %s is in the past", c.Until.Format(time.RFC3339))
+	case !c.StartAt.IsZero() && !c.Until.After(c.StartAt):
+		return fmt.Errorf("until %s is not after start at %s, so the run would have no time", c.Until.Format(time.RFC3339), c.StartAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// untilTimeout is the Timeout Until stands for, for a run starting at now or at
+// StartAt, whichever is the later. Never 0, which is a run with no end: an Until
+// that has gone by in the moment since validate is a run over as it begins.
+func (c Cfg) untilTimeout(now time.Time) time.Duration {
+	start := now
+	if c.StartAt.After(now) {
+		start = c.StartAt
+	}
+
+	return max(c.Until.Sub(start), time.Nanosecond)
+}
+
+// waitToStart blocks until StartAt, counting down: a line as the wait begins,
+// one every Report where there is one and one for each reportSignal, because a
+// wait that says nothing looks like a hang. It returns the shutdown signal that
+// ended the wait instead, where one did, and nil at the start.
+//
+// pauseSignal has nothing to pause yet, and is taken off control and dropped
+// rather than left there to pause the run the moment it starts.
+func (c Cfg) waitToStart(received, control <-chan os.Signal) os.Signal {
+	timer := time.NewTimer(time.Until(c.StartAt))
+	defer timer.Stop()
+
+	var tick <-chan time.Time
+
+	if c.Report > 0 {
+		ticker := time.NewTicker(c.Report)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	writef(c.Out, "%s\n", countdownMessage(c.StartAt, time.Until(c.StartAt)))
+
+	for {
+		select {
+		case sig := <-received:
+			return sig
+		case sig := <-control:
+			if sig == reportSignal {
+				writef(c.Out, "%s\n", countdownMessage(c.StartAt, time.Until(c.StartAt)))
+			}
+		case <-tick:
+			writef(c.Out, "%s\n", countdownMessage(c.StartAt, time.Until(c.StartAt)))
+		case <-timer.C:
+			// A signal in the same instant as the start is the one the
+			// operator sent, for #117's reason.
+			select {
+			case sig := <-received:
+				return sig
+			default:
+				return nil
+			}
+		}
+	}
+}
+
+// countdownMessage is the line a run waiting for StartAt prints: the instant,
+// as the operator typed it, and how long is left until it.
+func countdownMessage(at time.Time, left time.Duration) string {
+	return fmt.Sprintf("Waiting to start at %s, %s from now", at.Format(time.RFC3339), left.Round(time.Millisecond))
+}
+
+// notStartedMessage is what a run a signal ended before its start prints in
+// place of the shutdown line and the summary, neither of which it has.
+func notStartedMessage(sig os.Signal) string {
+	return fmt.Sprintf("Received %s before the start; no worker was started", signalName(sig))
+}
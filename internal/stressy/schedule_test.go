@@ -0,0 +1,93 @@
+package stressy
+
+import (
+	"bytes"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// TestUntilTimeout covers the arithmetic an Until is turned into a Timeout by:
+// from now, or from a start still to come, and never the 0 that means forever.
+func TestUntilTimeout(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		startAt time.Time
+		until   time.Time
+		want    time.Duration
+	}{
+		{name: "from now", until: now.Add(10 * time.Minute), want: 10 * time.Minute},
+		{name: "from a start to come", startAt: now.Add(time.Minute), until: now.Add(10 * time.Minute), want: 9 * time.Minute},
+		// Run's wait ends at the start or a moment past it, never before.
+		{name: "from a start just gone by", startAt: now.Add(-time.Millisecond), until: now.Add(time.Minute), want: time.Minute},
+		{name: "an end gone by since validate", until: now.Add(-time.Millisecond), want: time.Nanosecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Cfg{StartAt: tt.startAt, Until: tt.until}).untilTimeout(now); got != tt.want {
+				t.Errorf("untilTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestScheduleMessages pins the lines a window adds: the instant as typed, and
+// the signal named as the shutdown line names it.
+func TestScheduleMessages(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 0, 0, 0, time.FixedZone("", 3600))
+
+	if got, want := countdownMessage(at, 41*time.Second+1499*time.Microsecond), "Waiting to start at 2026-01-02T15:00:00+01:00, 41.001s from now"; got != want {
+		t.Errorf("countdownMessage() = %q, want %q", got, want)
+	}
+
+	if got, want := notStartedMessage(syscall.SIGTERM), "Received SIGTERM before the start; no worker was started"; got != want {
+		t.Errorf("notStartedMessage() = %q, want %q", got, want)
+	}
+
+	if got, want := (Cfg{Cfg: stress.Cfg{Timeout: 10 * time.Minute}, Until: at}).startupMessage([]stress.GroupResult{{Stressor: "bcrypt", Workers: 4}}), "Starting CPU stress test with 4 workers until 2026-01-02T15:00:00+01:00"; got != want {
+		t.Errorf("startupMessage() = %q, want %q", got, want)
+	}
+}
+
+// TestRunKeepsToItsWindow runs a window through Run: a countdown first, no
+// worker before the start, and the end at the instant given rather than a
+// timeout's length after whenever the run began.
+func TestRunKeepsToItsWindow(t *testing.T) {
+	var buf bytes.Buffer
+
+	startAt := time.Now().Add(300 * time.Millisecond)
+	until := startAt.Add(200 * time.Millisecond)
+
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 1, Stressor: "contention", Modes: []string{"atomic"}}, Out: &buf, StartAt: startAt, Until: until}).Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	if now := time.Now(); now.Before(until) {
+		t.Errorf("Run() returned %s before its until, want it to run to the end of the window", until.Sub(now))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	wants := []string{
+		"Waiting to start at " + startAt.Format(time.RFC3339) + ", ",
+		"Starting contention stress test with 1 worker until " + until.Format(time.RFC3339) + ", mode atomic",
+		"Timer expired, shutting down; ",
+		"Computed ",
+	}
+
+	if len(lines) < len(wants) {
+		t.Fatalf("Run() printed:\n%s\nwant a countdown, the startup line, the shutdown and the summary", buf.String())
+	}
+
+	for i, want := range wants {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("Run() line %d = %q, want it to start %q", i+1, lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package stressy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// segmentMessage is the line a run with a shape prints as each segment starts:
+// which of how many, when, and the load it asks for and how the workers make
+// it up.
+func segmentMessage(ev stress.SegmentEvent) string {
+	return fmt.Sprintf(
+		"Segment %d of %d at %s elapsed: %.2f cores, %s, for %s",
+		ev.Index+1, ev.Segments, ev.Elapsed.Round(time.Millisecond), ev.Segment.Cores(), segmentWorkers(ev.Segment), ev.Segment.Length.Round(time.Millisecond),
+	)
+}
+
+// segmentWorkers is how a segment's workers make up its load: "3 workers at
+// 83% duty", or "every worker held" for none.
+func segmentWorkers(s stress.Segment) string {
+	if s.Workers == 0 || s.Duty == 0 {
+		return "every worker held"
+	}
+
+	return fmt.Sprintf("%d %s at %.0f%% duty", s.Workers, plural(s.Workers, "worker", "workers"), s.Duty*100)
+}
+
+// shapeLines are the lines a shaped run adds under its summary: the load asked
+// for and offered over the whole of it, each an average over the time its
+// segments ran, and the same for each segment. The load offered is the workers'
+// wall-clock time in units, not the CPU time the OS gave them, so a machine
+// with no room for it shows in --cpu-time's figures rather than here.
+func shapeLines(r stress.Result) []string {
+	var asked, offered, ran float64
+
+	for _, s := range r.Shape {
+		asked += s.Cores() * s.Ran.Seconds()
+		offered += s.Offered * s.Ran.Seconds()
+		ran += s.Ran.Seconds()
+	}
+
+	if ran > 0 {
+		asked, offered = asked/ran, offered/ran
+	}
+
+	lines := []string{fmt.Sprintf(
+		"Shape: %d %s, %.2f cores asked and %.2f offered on average",
+		len(r.Shape), plural(len(r.Shape), "segment", "segments"), asked, offered,
+	)}
+
+	for i, s := range r.Shape {
+		lines = append(lines, fmt.Sprintf(
+			"Segment %d at %s for %s: %.2f cores asked, %.2f offered",
+			i+1, s.Start.Round(time.Millisecond), s.Ran.Round(time.Millisecond), s.Cores(), s.Offered,
+		))
+	}
+
+	return lines
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package stressy
+
+import "os"
+
+// reportSignal and pauseSignal are nil where there is no SIGUSR1 or SIGUSR2 to
+// send: a run is reported on at its --report ticks and cannot be paused.
+var reportSignal, pauseSignal os.Signal
@@ -0,0 +1,16 @@
+//go:build unix
+
+package stressy
+
+import (
+	"os"
+	"syscall"
+)
+
+// reportSignal asks a run for a progress line there and then, and pauseSignal
+// pauses it or resumes it, whichever it is not. Neither ends a run, and Windows
+// has neither, which is what this file's build tag is for.
+var (
+	reportSignal os.Signal = syscall.SIGUSR1
+	pauseSignal  os.Signal = syscall.SIGUSR2
+)
@@ -0,0 +1,45 @@
+package stressy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// stallMessage is the shutdown line of a run its --stall-timeout ended: which
+// worker stalled, for how long, and that the drain waits on the others for no
+// longer than the limit again, in place of the timer's line. Workers are
+// counted from 1 here, "worker 2 of 4", and named by stressor in a mix.
+func (c Cfg) stallMessage(cause error) string {
+	var stalled *stress.StallError
+	if !errors.As(cause, &stalled) {
+		return "Stalled, shutting down; " + fmt.Sprintf(drainNotice, c.step())
+	}
+
+	s := describe(stalled.Stressor)
+	worker := fmt.Sprintf("worker %d of %d", stalled.Worker+1, c.groupWorkers(stalled.Stressor))
+
+	if len(c.Mix) > 0 {
+		worker = s.Name + " " + worker
+	}
+
+	return fmt.Sprintf(
+		"Stalled: %s has been on one %s for %s, past --stall-timeout %s, shutting down; waiting up to %s for every other worker to finish the %s it is on...",
+		worker, strings.Join(modeSteps(stalled.Stressor, c.Modes), " or "), stalled.For.Round(100*time.Millisecond), stalled.Timeout, stalled.Timeout, c.step(),
+	)
+}
+
+// groupWorkers is how many workers the group running stressor has: the run's,
+// or a mix's group's.
+func (c Cfg) groupWorkers(stressor string) int {
+	for _, g := range c.Mix {
+		if g.Stressor == stressor {
+			return g.Workers
+		}
+	}
+
+	return c.Workers
+}
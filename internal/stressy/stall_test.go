@@ -0,0 +1,44 @@
+package stressy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestStallMessage(t *testing.T) {
+	stalled := &stress.StallError{Stressor: "bcrypt", Worker: 1, For: 30150 * time.Millisecond, Timeout: 30 * time.Second}
+
+	mix := Cfg{Cfg: stress.Cfg{Mix: []stress.Group{{Stressor: "cache", Workers: 2}, {Stressor: "bcrypt", Workers: 3}}}}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "a run of one stressor",
+			got:  Cfg{Cfg: stress.Cfg{Workers: 4}}.stallMessage(stalled),
+			want: "Stalled: worker 2 of 4 has been on one hash for 30.2s, past --stall-timeout 30s, shutting down; waiting up to 30s for every other worker to finish the hash it is on...",
+		},
+		{
+			name: "a mix",
+			got:  mix.stallMessage(stalled),
+			want: "Stalled: bcrypt worker 2 of 3 has been on one hash for 30.2s, past --stall-timeout 30s, shutting down; waiting up to 30s for every other worker to finish the walk or hash it is on...",
+		},
+		{name: "no worker named", got: Cfg{}.stallMessage(nil), want: "Stalled, shutting down; waiting for every worker to finish the hash it is on..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if got := (&HaltError{Cause: stalled}).ExitCode(); got != exitStall {
+		t.Errorf("ExitCode() = %d, want %d", got, exitStall)
+	}
+}
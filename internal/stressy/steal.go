@@ -0,0 +1,24 @@
+package stressy
+
+import (
+	"fmt"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// stealClause is what --steal adds to every progress line: the steal and
+// iowait since the line before, which is where a run that slows down says
+// when it did.
+func stealClause(s stress.StealStats) string {
+	return fmt.Sprintf("steal %.1f%%, iowait %.1f%%", s.RecentSteal, s.RecentIOWait)
+}
+
+// stealMessage is the line --steal adds under the summary: the same over the
+// whole run, in percent of every CPU's time, as top has them.
+func stealMessage(s *stress.StealStats) string {
+	if s == nil {
+		return "Steal: unknown; there is no /proc/stat to read"
+	}
+
+	return fmt.Sprintf("Steal: %.1f%% of the CPUs' time over the run, and iowait %.1f%%", s.Steal, s.IOWait)
+}
@@ -0,0 +1,46 @@
+package stressy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestStealMessages(t *testing.T) {
+	s := stress.StealStats{Steal: 12.345, IOWait: 0.4, RecentSteal: 31.2, RecentIOWait: 0}
+
+	r := stress.Result{Count: 8, Elapsed: 2 * time.Second, Groups: []stress.GroupResult{group("bcrypt", 2)}, Steal: &s}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "clause", got: stealClause(s), want: "steal 31.2%, iowait 0.0%"},
+		{name: "summary", got: stealMessage(&s), want: "Steal: 12.3% of the CPUs' time over the run, and iowait 0.4%"},
+		{name: "summary, no /proc/stat", got: stealMessage(nil), want: "Steal: unknown; there is no /proc/stat to read"},
+		{name: "progress", got: Cfg{Steal: true}.progressLine(r), want: "2s elapsed, 8 hashes, 4.0 hashes/s; steal 31.2%, iowait 0.0%"},
+		{name: "progress, off", got: Cfg{}.progressLine(r), want: "2s elapsed, 8 hashes, 4.0 hashes/s"},
+		{name: "progress, no /proc/stat", got: Cfg{Steal: true}.progressLine(stress.Result{Count: 8, Elapsed: 2 * time.Second, Groups: r.Groups}), want: "2s elapsed, 8 hashes, 4.0 hashes/s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	// The summary's last line, and only where asked for (#70).
+	on := Cfg{Cfg: stress.Cfg{Workers: 2}, Steal: true}.summaryLines(r)
+	if last := on[len(on)-1]; !strings.HasPrefix(last, "Steal: ") {
+		t.Errorf("summaryLines() ends %q, want the steal line", last)
+	}
+
+	if off := (Cfg{Cfg: stress.Cfg{Workers: 2}}).summaryLines(r); len(off) != len(on)-1 {
+		t.Errorf("summaryLines() without --steal = %q, want no steal line", off)
+	}
+}
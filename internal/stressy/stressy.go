@@ -1,36 +1,29 @@
-// Package stressy is the stressy command: the flag grammar it publishes and the
-// CPU stress test that grammar configures, which worker goroutines run by
-// hashing bcrypt. The root main.go is a call into Main and nothing else.
+// Package stressy is the stressy command: the flag grammar it publishes, and the
+// lines and exit codes a run of the stress package it configures is reported
+// in. The root main.go is a call into Main and nothing else.
 //
-// What stressy publishes is that command line, not a Go API. The package is
-// under internal/, so nothing here can be imported from outside this module,
-// and 1.0.0 freezes the flags rather than any identifier below. Main is the
-// whole of what main.go needs; a name exported past it is exported for a caller
-// that cannot exist.
+// What stressy publishes here is that command line, not a Go API; the Go API is
+// the stress package, which this one layers printing and signal handling over.
+// The package is under internal/, so nothing in it can be imported from
+// outside this module, and 1.0.0 freezes the flags rather than any identifier
+// below. Main is the whole of what main.go needs.
 package stressy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"os"
 	"os/signal"
-	"sync"
-	"sync/atomic"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/felipeneuwald/stressy/stress"
 )
 
-// hashCost is the bcrypt cost every worker hashes at. bcrypt doubles its work
-// per increment, so the cost sets how long one uninterruptible
-// GenerateFromPassword call runs, and so how long a worker takes to notice
-// cancellation: ~0.18s per hash at cost 12, against ~26 hours at bcrypt.MaxCost,
-// which pegs a core no harder and leaves the cancellation check unreachable.
-const hashCost = 12
-
 // reportFloor is the shortest --report interval a run will start on. Below it a
 // run spends itself formatting rather than hashing: `-t 1s -r 1ns` puts hundreds
 // of thousands of lines and tens of megabytes on stdout for one second of work,
@@ -38,6 +31,12 @@ const hashCost = 12
 // and none of its purpose (#114).
 const reportFloor = time.Second
 
+// stallFloor is the shortest --stall-timeout a run will start on. A bcrypt hash
+// is hundreds of milliseconds on a healthy core and an exec is tens, so a limit
+// under a second is one a slow machine's ordinary unit trips, and a smoke test
+// that fails good hardware is worse than none.
+const stallFloor = time.Second
+
 // shutdownSignals are the signals that end a run. README.md's exit-code table
 // documents what each one exits with; nothing holds the two together. Adding one
 // means giving signalName a spelling for it, which a test does hold.
@@ -71,202 +70,368 @@ func (e *SignalError) ExitCode() int {
 	return 128 + int(sig)
 }
 
-// Cfg is a configured CPU stress test, ready to Run.
+// HaltError is what Run returns when a limit of the run's own ended it rather
+// than the timer or a signal: --max-temp's, with the *stress.TempError that says
+// which zone reached it, or --stall-timeout's, with the *stress.StallError that
+// says which worker stalled. A coordinator returns one too, for the worst of
+// its agents' runs a limit ended. Like a SignalError it is an exit code rather
+// than a failure to report, the shutdown line having already said why the run
+// ended.
+type HaltError struct {
+	Cause error
+}
+
+// Error implements error. Nothing prints it on the normal path.
+func (e *HaltError) Error() string { return "run halted: " + e.Cause.Error() }
+
+func (e *HaltError) Unwrap() error { return e.Cause }
+
+// ExitCode is the status a run this ended should exit with, one README.md's
+// exit-code table gives each limit: 3 for the temperature and 4 for a stall.
+// Neither 1, which is a configuration rejected, nor 128 and up, which are the
+// signals'.
+func (e *HaltError) ExitCode() int {
+	var (
+		hot     *stress.TempError
+		stalled *stress.StallError
+		node    *nodeHalt
+	)
+
+	switch {
+	case errors.As(e.Cause, &hot):
+		return exitTemp
+	case errors.As(e.Cause, &stalled):
+		return exitStall
+	case errors.As(e.Cause, &node):
+		return node.exitCode()
+	}
+
+	return 1
+}
+
+// exitTemp is the code a run --max-temp ended exits with, and exitStall one
+// --stall-timeout did.
+const (
+	exitTemp  = 3
+	exitStall = 4
+)
+
+// Cfg is a configured stress test as the command runs it: the stress package's
+// configuration, and where the lines the command makes of it go.
 type Cfg struct {
-	Workers int           // number of parallel worker goroutines
-	Timeout time.Duration // how long to run (0 for indefinite)
-	Report  time.Duration // how often to print a progress line (0 for never)
+	stress.Cfg
 
 	// Out is where a run prints, and every line a run prints goes through it:
 	// the startup line, the hint an indefinite run adds under it, a progress
-	// line per --report tick, the shutdown line and the summary. The hint and
-	// the progress line are conditional, so only a run that is both indefinite
-	// and reporting prints all of them. The command sets it to the stream it
-	// prints its own lines on, so redirecting that redirects both; nil is
-	// os.Stdout, for a run configured by something other than a command.
+	// line per --report tick and per SIGUSR1, a line for each pause and resume,
+	// the shutdown line and the summary, with a line under it per variant where
+	// the stressor has them and whatever its probes have to say after those.
+	// The hint and the progress line are conditional, so only a run that is
+	// both indefinite and reporting prints all of them. The command sets it to
+	// the stream it prints its own lines on, so redirecting that redirects
+	// both; nil is os.Stdout, for a run configured by something other than a
+	// command.
+	//
+	// Those lines are the text observer's, which is the Observer a run has
+	// where Cfg sets none. One that does set it is told everything the lines
+	// are built from, and nothing is printed.
 	Out io.Writer
+
+	// StartAt, where it is set, is when the run starts: Run waits for it,
+	// counting down. Until, where it is set, is when the run ends, in place of
+	// Timeout, which Run works out from it once the wait is over. Both are
+	// instants on the wall clock rather than lengths from whenever a process
+	// happened to start, so forty pods a rollout started seconds apart load
+	// their nodes over one window.
+	StartAt time.Time
+	Until   time.Time
+
+	// Replay, where it is set, is a file of CPU utilisation samples the run
+	// follows, read into the Shape before it starts, and ReplaySpeed how many
+	// times faster than they were taken it goes through them; 0 is 1x. The
+	// trace's length is the run's where neither Timeout nor Until is set.
+	Replay      string
+	ReplaySpeed float64
+
+	// CPUTime adds the CPU time the process was given to the progress lines
+	// and the summary, where the OS says what it was. Off, the lines are the
+	// ones a run has always printed (#70).
+	CPUTime bool
+
+	// Throttling adds what the cgroup's CPU quota held the run to and the
+	// machine's CPU pressure over it to the summary, which are what a slow
+	// rate in a container most often is. Off, as CPUTime is, for #70.
+	Throttling bool
+
+	// Steal adds the machine's CPU time a hypervisor stole and I/O waited on
+	// to the progress lines and the summary, which on a VM is most often what
+	// a rate that drops is. Off, as CPUTime is, for #70.
+	Steal bool
+
+	// Thermal adds the CPUs' frequencies and the machine's temperature to
+	// the progress lines and the summary, for the throttling a CPU does to
+	// itself when it runs hot. Off, as CPUTime is, for #70.
+	Thermal bool
+
+	// Energy adds what the CPU packages drew to the summary, and the work
+	// done a joule, for comparing one kind of machine against another by
+	// more than its rate. Off, as CPUTime is, for #70.
+	Energy bool
+
+	// Verbose adds a block under the startup line saying what the machine
+	// is: its CPUs, kernel, Go and cgroup limits. Off, as CPUTime is, for #70.
+	Verbose bool
 }
 
-// Run starts the configured workers and blocks until the timeout expires or a
-// shutdown signal arrives, printing a progress line every report interval while
-// it waits. It then waits for every worker to finish the hash it is on — the
-// drain — and prints what the run did.
+// Run starts the configured workers and blocks until the run ends — its timeout
+// or --until, its --count done, a limit of its own, or a shutdown signal —
+// printing a progress line every report interval while it waits, and answering
+// reportSignal and pauseSignal where the platform has them. It then waits for
+// every worker to finish the hash it is on — the drain — and prints what the
+// run did. A stressor other than bcrypt drains the same way, on a unit of its
+// own.
 //
-// That drain is one hash long only while the workers fit in GOMAXPROCS. Past it
-// the hashes in flight finish in series, so the drain runs for roughly
-// Workers/GOMAXPROCS of them: measured on 18 cores against `-t 1s`, `-w 18`
-// ended about 0.2s past the deadline and `-w 2000` about 20s past it. Nothing
-// caps Workers against the machine, because nothing here reads the machine
-// (#104): the one ceiling validate imposes is the int32 the wg.Add below counts
-// in, not the cores (#143). What the length costs is said out loud instead —
-// the shutdown line names what the wait is for, and signal handling is stopped
-// before it, so a second signal kills the process rather than being buffered
-// where nothing reads it again (#122).
+// That drain is one hash long only while the workers fit in GOMAXPROCS, and
+// roughly Workers/GOMAXPROCS of them past it, which stress.Cfg.RunContext says
+// more about. What the length costs is said out loud here — the shutdown line
+// names what the wait is for, and signal handling is stopped before it, so a
+// second signal kills the process rather than being buffered where nothing
+// reads it again (#122).
 //
-// It returns an error if the configuration is invalid, a *SignalError — not a
-// failure, an exit code — if a signal ended the run, and nil if the timer did.
+// It returns an error if the configuration is invalid or a variant cannot be
+// readied, and otherwise one of the exit codes README.md's table documents:
+// nil, for 0, if the timer or --until ended the run or its --count was done; a
+// *SignalError, for 128 plus the signal, if a signal ended it or the wait for
+// --start-at; and a *HaltError if a limit of the run's own did, for 3 where it
+// was --max-temp and 4 where it was --stall-timeout. Neither of the last two is
+// a failure to print, the shutdown line having said why the run ended.
 func (c Cfg) Run() error {
+	if err := c.loadReplay(); err != nil {
+		return err
+	}
+
 	if err := c.validate(); err != nil {
 		return err
 	}
 
 	// Defaulted before the first line is printed, and on the copy this value
-	// receiver already holds, so waitForShutdown below prints to it too.
+	// receiver already holds, so the text observer below prints to it too. An
+	// Observer of the caller's takes the text's place, the countdown's lines
+	// with it.
 	if c.Out == nil {
 		c.Out = os.Stdout
 	}
 
-	// Both shutdown triggers meet in one select — waitForShutdown's, below — over
-	// one context, so two triggers cannot both fire. The buffer of 1 is what
-	// makes a signal arriving before the select is reached a shutdown rather than
-	// a lost one, and it is registered before the first line is printed: until it
-	// is, either signal terminates the process outright and there is no shutdown
-	// to report. TestExitCodes relies on that ordering.
+	if c.Observer != nil {
+		c.Out = io.Discard
+	}
+
+	// Both shutdown triggers meet in one select — waitForShutdown's, which the
+	// gate below runs — so two triggers cannot both be reported. The buffer of
+	// 1 is what makes a signal arriving before the select is reached a shutdown
+	// rather than a lost one, and it is registered before the first line is
+	// printed: until it is, either signal terminates the process outright and
+	// there is no shutdown to report. TestExitCodes relies on that ordering.
 	received := make(chan os.Signal, 1)
 	signal.Notify(received, shutdownSignals...)
 
-	// The guard for a Run that never reaches the stop below, which today means
-	// a panic; the shutdown path does not wait for it, and cannot (#122).
+	// The guard for a Run that never reaches the gate's stop, which today means
+	// a panic or a run that could not start; the shutdown path does not wait
+	// for it, and cannot (#122).
 	defer signal.Stop(received)
 
-	writef(c.Out, "%s\n", c.startupMessage())
-
-	if hint := c.hintMessage(); hint != "" {
-		writef(c.Out, "%s\n", hint)
+	// The signals a run answers rather than ends on, registered with the
+	// others for their reason, and kept until Run returns rather than stopped
+	// with them: one sent during the drain goes unanswered, where unhandled it
+	// would kill the process for asking for a progress line. nil, and so never
+	// ready in a select, where the platform has neither.
+	var control chan os.Signal
+	if reportSignal != nil {
+		control = make(chan os.Signal, 1)
+		signal.Notify(control, reportSignal, pauseSignal)
+
+		defer signal.Stop(control)
 	}
 
-	// Started before the deadline is set, so the elapsed time the summary
-	// reports is never less than the timeout the operator asked for.
-	start := time.Now()
+	// The wait is under the handlers above: a signal while it counts down is
+	// a run cut short like any other, with the exit code to say so, and the
+	// line in place of the shutdown line says nothing ran.
+	if !c.StartAt.IsZero() {
+		if sig := c.waitToStart(received, control); sig != nil {
+			signal.Stop(received)
+			writef(c.Out, "%s\n", notStartedMessage(sig))
 
-	ctx, stop := context.WithCancel(context.Background())
-	defer stop()
-
-	if c.Timeout > 0 {
-		var expire context.CancelFunc
-		ctx, expire = context.WithTimeout(ctx, c.Timeout)
-		defer expire()
+			return &SignalError{Signal: sig}
+		}
 	}
 
-	var wg sync.WaitGroup
+	if !c.Until.IsZero() {
+		c.Timeout = c.untilTimeout(time.Now())
+	}
 
-	// Atomic because the --report heartbeat reads it mid-run, while every worker
-	// is still adding to it.
-	var hashes atomic.Uint64
+	// The run's timeout is its own; cancel is the signal path's, and the
+	// signal it is cancelled with is the cause the shutdown is reported with.
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 
-	wg.Add(c.Workers)
-	for range c.Workers {
-		go func() {
-			defer wg.Done()
-			stressTestCPU(ctx, &hashes)
-		}()
+	obs := c.Observer
+	if obs == nil {
+		obs = textObserver{c}
 	}
 
-	sig := c.waitForShutdown(ctx, received, &hashes, start)
-
-	// One shutdown is all this run has to report, and everything below it is the
-	// drain. Handling stops here rather than at the deferred call, which does
-	// not run until the drain is over: for the whole of a wait that grows with
-	// Workers, a second signal landed in a one-deep buffer nobody read again and
-	// the run could be stopped by nothing but SIGKILL (#122).
-	//
-	// Stopped rather than drained, so the signal goes back to the disposition it
-	// had before Notify — the default, for anything a terminal, a `docker stop`
-	// or a kubelet signals — and the second one ends the process where it
-	// stands. On the timer path too, where the run was going to exit 0 and the
-	// operator pressing Ctrl-C through the drain asked for something else.
-	//
-	// Before the line below rather than after it, so the log says the run is
-	// draining only once a signal can in fact interrupt the drain.
-	signal.Stop(received)
+	gate := newSignalGate(obs, received, control)
 
-	writef(c.Out, "%s\n", shutdownMessage(sig))
+	run := c.Cfg
+	run.Observer = gate
 
-	// Tells the workers to stop on the signal path; a no-op on the timer path,
-	// where ctx is already done.
-	stop()
+	// A variant that cannot be readied fails the run before its first line:
+	// nothing was measured.
+	r, err := run.Start(ctx)
+	if err != nil {
+		return err
+	}
 
-	wg.Wait()
+	gate.watch(cancel, r)
 
-	writef(c.Out, "%s\n", c.summaryMessage(hashes.Load(), time.Since(start)))
+	// Progress, the shutdown and the summary are all the observer's, called
+	// from the run's own goroutines while this waits.
+	r.Wait()
 
-	if sig != nil {
+	if sig := gate.sig; sig != nil {
 		return &SignalError{Signal: sig}
 	}
 
+	if gate.halt != nil {
+		return &HaltError{Cause: gate.halt}
+	}
+
 	return nil
 }
 
-// waitForShutdown blocks until the run ends, printing a progress line every
-// report interval while it waits. It returns the signal that ended the run, or
-// nil where the deadline expired — the distinction Run's shutdown line and the
-// process exit code are both chosen from.
-func (c Cfg) waitForShutdown(ctx context.Context, received <-chan os.Signal, hashes *atomic.Uint64, start time.Time) os.Signal {
-	// nil where --report is off, and a receive from a nil channel blocks forever,
-	// so the default run waits on exactly the two channels it always did.
-	var tick <-chan time.Time
-
-	if c.Report > 0 {
-		ticker := time.NewTicker(c.Report)
-		defer ticker.Stop()
-
-		tick = ticker.C
-	}
-
+// waitForShutdown blocks until done closes or a signal arrives, and returns the
+// signal, or nil where done closed first — the distinction the shutdown line and
+// the process exit code are both chosen from. A signal on control ends nothing:
+// it is handed to answer, and the wait goes on.
+func waitForShutdown(done <-chan struct{}, received, control <-chan os.Signal, answer func(os.Signal)) os.Signal {
 	for {
 		select {
 		case sig := <-received:
 			return sig
-		case <-ctx.Done():
-			// Both of Run's cancel functions are deferred, so this branch means
-			// the deadline expired; nil is what tells the two shutdowns apart.
+		case sig := <-control:
+			answer(sig)
+		case <-done:
+			// The run's context is done and it was not this that cancelled
+			// it, so the deadline expired.
 			//
-			// A signal arriving in the same instant leaves both cases ready, and
-			// select picks between ready cases at random, so the deadline could
-			// win a run a signal had ended: `Timer expired` on stdout and exit 0
-			// where README.md's table says 143. Reading the channel first is what
-			// makes that table true — a signal already in it ended this run (#117).
+			// A signal arriving in the same instant leaves both cases ready,
+			// and select picks between ready cases at random, so the deadline
+			// could win a run a signal had ended: `Timer expired` on stdout and
+			// exit 0 where README.md's table says 143. Reading the channel
+			// first is what makes that table true — a signal already in it
+			// ended this run (#117).
 			//
-			// A signal arriving after this returns is not caught at all: Run
-			// stops handling the moment it has one shutdown to report, so the
-			// second one ends the process outright rather than being buffered
-			// where nothing reads it again. A run that had served its whole
-			// timeout therefore dies with the signal instead of exiting 0,
-			// which is what pressing Ctrl-C through the drain asks for (#122).
+			// A signal arriving after this returns is not caught at all: the
+			// gate stops handling the moment it has one shutdown to report, so
+			// the second one ends the process outright rather than being
+			// buffered where nothing reads it again. A run that had served its
+			// whole timeout therefore dies with the signal instead of exiting
+			// 0, which is what pressing Ctrl-C through the drain asks for
+			// (#122).
 			select {
 			case sig := <-received:
 				return sig
 			default:
 				return nil
 			}
-		case <-tick:
-			// time.Since rather than the timestamp the tick carries: a late tick
-			// carries the time it fired, printing the elapsed time the line would
-			// have had if the process were healthy — hiding exactly the pathology
-			// an operator turns this on to see.
-			writef(c.Out, "%s\n", progressMessage(hashes.Load(), time.Since(start)))
 		}
 	}
 }
 
-// startupMessage is the line Run prints before the workers start: how many
-// workers, and for how long. Built as a string rather than printed in place,
-// like the four message functions below, so it is testable without os.Stdout.
-func (c Cfg) startupMessage() string {
-	// The timeout is a time.Duration and formats itself: "30s", "5m0s".
-	duration := "indefinitely"
-	if c.Timeout > 0 {
-		duration = "for " + c.Timeout.String()
+// startupMessage is the line Run prints once the workers start: what load, how
+// many workers, and for how long. groups are the run's as they stand then,
+// which is where the variants each takes turns between are named. Built as a
+// string rather than printed in place, like the message functions below, so it
+// is testable without os.Stdout.
+func (c Cfg) startupMessage(groups []stress.GroupResult) string {
+	duration := c.lengthClause()
+
+	if len(c.Mix) > 0 {
+		return mixStartupMessage(groups, duration) + c.burstClause() + c.priorityClause() + c.tempClause()
+	}
+
+	g := groups[0]
+
+	line := fmt.Sprintf("Starting %s stress test with %d %s %s", describe(g.Stressor).Label, g.Workers, plural(g.Workers, "worker", "workers"), duration)
+
+	// Named here as well as in the summary, so a run stopped before its summary
+	// still said what it was measuring.
+	if clause := variantClause(g); clause != "" {
+		line += ", " + clause
+	}
+
+	return line + c.rateClause() + c.burstClause() + c.replayClause() + c.chaosClause() + c.priorityClause() + c.tempClause()
+}
+
+// lengthClause is how long the run is to last, in the words the startup line
+// says it in: "for 5m0s", "until 2026-01-02T15:10:00Z", "for 1000 hashes or
+// 5m0s, whichever is first", or "indefinitely".
+//
+// The timeout is a time.Duration and formats itself: "30s", "5m0s". One worked
+// out from Until would print to the nanosecond, so the instant the operator
+// typed is what the clause names instead.
+func (c Cfg) lengthClause() string {
+	var bound string
+
+	switch {
+	case !c.Until.IsZero():
+		bound = "until " + c.Until.Format(time.RFC3339)
+	case c.Timeout > 0:
+		bound = c.Timeout.String()
+	}
+
+	if c.Count > 0 {
+		unit, units := c.countUnits()
+		work := fmt.Sprintf("for %d %s", c.Count, plural(c.Count, unit, units))
+
+		if bound == "" {
+			return work
+		}
+
+		return work + " or " + bound + ", whichever is first"
+	}
+
+	switch {
+	case bound == "":
+		return "indefinitely"
+	case c.Until.IsZero():
+		return "for " + bound
+	default:
+		return bound
+	}
+}
+
+// variantClause names the variants a group takes turns between — "modes
+// atomic, mutex" — or is "" for a stressor that has none.
+func variantClause(g stress.GroupResult) string {
+	s := describe(g.Stressor)
+	if s.Kind == "" {
+		return ""
+	}
+
+	names := make([]string, len(g.Variants))
+	for i, v := range g.Variants {
+		names[i] = v.Name
 	}
 
-	return fmt.Sprintf("Starting CPU stress test with %d %s %s", c.Workers, plural(c.Workers, "worker", "workers"), duration)
+	return fmt.Sprintf("%s %s", plural(len(names), s.Kind, s.Kind+"s"), strings.Join(names, ", "))
 }
 
 // hintMessage is the second line Run prints, and only on an indefinite run —
 // the one that has to say how to stop it. SIGTERM is named beside Ctrl-C
-// because that is what a `docker stop` or a node drain sends.
+// because that is what a `docker stop` or a node drain sends. A run with a count
+// ends when the work does, and is not indefinite however long that takes.
 func (c Cfg) hintMessage() string {
-	if c.Timeout > 0 {
+	if c.Timeout > 0 || c.Count > 0 {
 		return ""
 	}
 
@@ -275,18 +440,85 @@ func (c Cfg) hintMessage() string {
 
 // progressMessage is the line a --report run prints on every tick. Its rate is
 // cumulative rather than per-interval, so the last progress line of a run and
-// the summary under it agree.
-func progressMessage(hashes uint64, elapsed time.Duration) string {
+// the summary under it agree; it leaves out the time paused, which the line
+// then names.
+func (c Cfg) progressMessage(count uint64, elapsed, paused time.Duration) string {
+	s := describe(c.Stressor)
+
 	return fmt.Sprintf(
-		"%s elapsed, %d %s, %.1f hashes/s",
+		"%s elapsed, %d %s, %.1f %s/s%s",
 		elapsed.Round(time.Millisecond),
-		hashes, plural(hashes, "hash", "hashes"),
-		hashRate(hashes, elapsed),
+		count, plural(count, s.Unit, s.Units),
+		rate(count, elapsed-paused), s.Units,
+		pausedClause(paused),
 	)
 }
 
+// progressLine is the whole of a progress line for the run as r has it: the
+// run's own, or for a mix each group's, and the clauses of the schedule and the
+// latency probe where there are those.
+func (c Cfg) progressLine(r stress.Result) string {
+	var line string
+
+	if len(c.Mix) == 0 {
+		line = c.progressMessage(r.Count, r.Elapsed, r.Paused)
+	} else {
+		line = mixProgressMessage(r)
+	}
+
+	if r.Dispatch != nil {
+		line += "; " + c.dispatchClause(*r.Dispatch, r.Elapsed, r.Paused)
+	}
+
+	if r.Latency != nil {
+		line += "; " + latencyClause(*r.Latency)
+	}
+
+	if c.CPUTime && r.CPU != nil {
+		line += "; " + cpuClause(r)
+	}
+
+	if c.Steal && r.Steal != nil {
+		line += "; " + stealClause(*r.Steal)
+	}
+
+	if c.Thermal && r.Thermal != nil {
+		line += "; " + thermalClause(*r.Thermal)
+	}
+
+	return line
+}
+
+// pauseMessage is the line a run prints once it is paused. The workers are
+// still finishing the unit each is on when it prints, as they are under the
+// shutdown line, and it says so in the same words.
+func (c Cfg) pauseMessage(ev stress.PauseEvent) string {
+	return fmt.Sprintf(
+		"Paused at %s elapsed; every worker holds once it has finished the %s it is on. Send SIGUSR2 again to resume",
+		ev.Elapsed.Round(time.Millisecond), c.step(),
+	)
+}
+
+// resumeMessage is the line a run prints once it goes on after a pause.
+func resumeMessage(ev stress.PauseEvent) string {
+	return fmt.Sprintf("Resumed at %s elapsed, after %s paused", ev.Elapsed.Round(time.Millisecond), ev.Held.Round(time.Millisecond))
+}
+
+// pausedClause is what a line with a rate adds where the run has been paused:
+// the time the rate leaves out, so that a count, an elapsed time and a rate that
+// do not divide into one another are explained on the line. "" for a run never
+// paused, whose lines are the ones they always were.
+func pausedClause(paused time.Duration) string {
+	if paused == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(", %s paused", paused.Round(time.Millisecond))
+}
+
 // drainNotice is the clause both shutdown lines end in: what the run is doing
-// between that line and the summary under it.
+// between that line and the summary under it, with %s the stressor's step —
+// "hash", for the bcrypt run every line before --stressor was printed by.
 //
 // Without it a drain is a hang — nothing prints, and the length is not one hash
 // but roughly Workers/GOMAXPROCS of them, which on a large `-w` is long enough
@@ -298,7 +530,7 @@ func progressMessage(hashes uint64, elapsed time.Duration) string {
 // No count in it, deliberately: the startup line already says how many workers
 // there are, and a line that changes shape with the configuration is one more
 // thing for a script reading stdout to get wrong.
-const drainNotice = "waiting for every worker to finish the hash it is on..."
+const drainNotice = "waiting for every worker to finish the %s it is on..."
 
 // shutdownMessage says why the run is ending, and what it is waiting for before
 // it does. sig is the signal that stopped it, or nil where the timer expired —
@@ -307,12 +539,36 @@ const drainNotice = "waiting for every worker to finish the hash it is on..."
 // The signal is named rather than called "a signal", because otherwise the two
 // signalled shutdowns print the same line while exiting 130 and 143, and telling
 // those apart is what the exit-code table is for (#111).
-func shutdownMessage(sig os.Signal) string {
+func (c Cfg) shutdownMessage(sig os.Signal) string {
+	drain := fmt.Sprintf(drainNotice, c.step())
+
 	if sig == nil {
-		return "Timer expired, shutting down; " + drainNotice
+		return "Timer expired, shutting down; " + drain
+	}
+
+	return fmt.Sprintf("Received %s, shutting down; %s", signalName(sig), drain)
+}
+
+// countMessage is the shutdown line of a run its --count ended: every unit is
+// done or in hand, and the drain is the ones in hand.
+func (c Cfg) countMessage() string {
+	unit, units := c.countUnits()
+
+	return fmt.Sprintf("All %d %s handed out, shutting down; %s", c.Count, plural(c.Count, unit, units), fmt.Sprintf(drainNotice, c.step()))
+}
+
+// countUnits is what a --count is counted in, as the lines that name it say
+// it: the run's stressor's units, or for a mix, whose groups each count in
+// units of their own and which stress.Cfg.Validate turns a count away from,
+// "unit" and "units" rather than the hashes of a stressor it may not have.
+func (c Cfg) countUnits() (unit, units string) {
+	if len(c.Mix) > 0 {
+		return "unit", "units"
 	}
 
-	return fmt.Sprintf("Received %s, shutting down; %s", signalName(sig), drainNotice)
+	s := describe(c.Stressor)
+
+	return s.Unit, s.Units
 }
 
 // signalName is what stressy calls a signal in the lines it prints: "SIGTERM",
@@ -339,26 +595,168 @@ func signalName(sig os.Signal) string {
 // past its deadline — by one hash where the workers fit in GOMAXPROCS and by
 // roughly Workers/GOMAXPROCS of them where they do not — and the rate divides by
 // the time that actually passed.
-func (c Cfg) summaryMessage(hashes uint64, elapsed time.Duration) string {
+//
+// "Computed" whatever the stressor, though an op is done more than computed:
+// the summary is the line a script finds by that word, and the README says so.
+//
+// A run that was paused is quoted a rate over the time it was not, with the
+// time it was named at the end.
+func (c Cfg) summaryMessage(count uint64, elapsed, paused time.Duration) string {
+	s := describe(c.Stressor)
+
 	return fmt.Sprintf(
-		"Computed %d %s in %s (%.1f hashes/s, %d %s)",
-		hashes, plural(hashes, "hash", "hashes"),
+		"Computed %d %s in %s (%.1f %s/s, %d %s%s)",
+		count, plural(count, s.Unit, s.Units),
 		// Rounded: the digits below a millisecond are noise against a hash that
 		// costs two hundred of them.
 		elapsed.Round(time.Millisecond),
-		hashRate(hashes, elapsed),
+		rate(count, elapsed-paused), s.Units,
 		c.Workers, plural(c.Workers, "worker", "workers"),
+		pausedClause(paused),
 	)
 }
 
-// hashRate is the rate both reporting lines quote. The guard is why it is worth
-// a function: dividing by a zero elapsed time would print "+Inf hashes/s".
-func hashRate(hashes uint64, elapsed time.Duration) float64 {
+// summaryLines is every line the summary is: summaryMessage and a line per
+// variant under it, or for a mix a line for the run and a group's lines for
+// each group, and then a line for the schedule and each probe the run had.
+func (c Cfg) summaryLines(r stress.Result) []string {
+	var lines []string
+
+	if len(c.Mix) > 0 {
+		lines = mixSummaryLines(r)
+	} else {
+		g := r.Groups[0]
+		lines = append([]string{c.summaryMessage(g.Count, r.Elapsed, r.Paused)}, variantLines(g)...)
+	}
+
+	if c.Count > 0 {
+		lines = append(lines, c.completionMessage(r))
+	}
+
+	if r.Dispatch != nil {
+		lines = append(lines, c.dispatchMessage(*r.Dispatch, r.Elapsed, r.Paused))
+	}
+
+	if r.Burst != nil {
+		lines = append(lines, burstMessage(r))
+	}
+
+	if len(r.Shape) > 0 {
+		lines = append(lines, shapeLines(r)...)
+	}
+
+	if r.GC != nil {
+		lines = append(lines, gcMessage(*r.GC))
+	}
+
+	if r.Latency != nil {
+		lines = append(lines, latencyMessage(*r.Latency))
+	}
+
+	return append(lines, c.figureLines(r)...)
+}
+
+// figureLines is a line for each figure of r's that --cpu-time, --steal,
+// --thermal, --energy and --throttling ask for, in that order, each "Name: ...".
+// They end the summary, and a coordinator prints them under its table, a set
+// per node.
+func (c Cfg) figureLines(r stress.Result) []string {
+	var lines []string
+
+	if c.CPUTime && r.CPU != nil {
+		lines = append(lines, cpuMessage(r))
+	}
+
+	if c.Steal {
+		lines = append(lines, stealMessage(r.Steal))
+	}
+
+	if c.Thermal {
+		lines = append(lines, thermalMessage(r.Thermal))
+	}
+
+	if c.Energy && r.Energy != nil {
+		lines = append(lines, c.energyMessage(r))
+	}
+
+	if c.Throttling {
+		lines = append(lines, throttleMessage(r.Cgroup), pressureMessage(r.Pressure))
+	}
+
+	return lines
+}
+
+// completionMessage is the line a run with a --count adds under its summary:
+// the time the work took, which is the figure the run was for, or where the run
+// ended short of it, how much of the work was done. The time leaves out any the
+// run spent paused, which was no work's.
+//
+// The count is what the workers did, which for a stressor that counts in
+// batches is up to a batch a worker past --count.
+func (c Cfg) completionMessage(r stress.Result) string {
+	unit, units := c.countUnits()
+
+	if r.Reason != stress.StopCount {
+		return fmt.Sprintf("Time to completion: none; %d of %d %s were done when the run ended", r.Count, c.Count, units)
+	}
+
+	return fmt.Sprintf("Time to completion: %s for %d %s", (r.Elapsed - r.Paused).Round(time.Millisecond), r.Count, plural(r.Count, unit, units))
+}
+
+// variantLines is a line per variant of the group, for the summary, or none
+// for a stressor without them.
+func variantLines(g stress.GroupResult) []string {
+	lines := make([]string, len(g.Variants))
+	for i, v := range g.Variants {
+		lines[i] = variantMessage(g, v)
+	}
+
+	return lines
+}
+
+// variantMessage is the line the summary carries for each variant a group had,
+// under the line for the run as a whole. Its rate divides by the time that
+// variant had the workers rather than by the run's, which is what makes two of
+// them comparable however the phases fell.
+//
+// A stressor that reports latency adds what one unit cost a worker, which is
+// the rate turned over and multiplied back out by the workers. It is a true
+// latency only while the workers fit in GOMAXPROCS; past that it counts the
+// time a worker waited for a core as well.
+func variantMessage(g stress.GroupResult, v stress.VariantResult) string {
+	s := describe(g.Stressor)
+
+	// "Mode atomic:", from a kind of "mode".
+	kind := strings.ToUpper(s.Kind[:1]) + s.Kind[1:]
+
+	line := fmt.Sprintf(
+		"%s %s: %d %s in %s (%.1f %s/s",
+		kind, v.Name,
+		v.Count, plural(v.Count, s.Unit, s.Units),
+		v.Spent.Round(time.Millisecond),
+		rate(v.Count, v.Spent), s.Units,
+	)
+
+	if s.Latency {
+		var each float64
+		if v.Count > 0 {
+			each = float64(v.Spent.Nanoseconds()) * float64(g.Workers) / float64(v.Count)
+		}
+
+		line += fmt.Sprintf(", %.1f ns/%s", each, s.Unit)
+	}
+
+	return line + ")"
+}
+
+// rate is the rate every reporting line quotes. The guard is why it is worth a
+// function: dividing by a zero elapsed time would print "+Inf hashes/s".
+func rate(count uint64, elapsed time.Duration) float64 {
 	if elapsed <= 0 {
 		return 0
 	}
 
-	return float64(hashes) / elapsed.Seconds()
+	return float64(count) / elapsed.Seconds()
 }
 
 // plural picks the form of a noun that goes with n.
@@ -370,44 +768,29 @@ func plural[T int | uint64](n T, one, many string) string {
 	return many
 }
 
-// validate reports whether a run can be started with this configuration.
-// Timeout 0 is indefinite and has no upper bound, because any length is one the
-// operator asked for. Report 0 is off; an interval that is on has a floor
-// always and a ceiling on a bounded run, because outside them it is not one
-// anybody asked for: under reportFloor it is the run (#114), and past the
-// timeout it is a line that never prints, which is the shape of `-r 1m` typed
-// where `-r 1s` was meant (#115).
-//
-// Workers has the one ceiling the program cannot do without. sync.WaitGroup
-// counts in an int32, so Run's wg.Add wrapped negative at 2^31 and the run died
-// on `panic: sync: negative WaitGroup counter`: a stack trace, no shutdown line
-// and no summary, and exit 2 — a code README.md's table does not carry (#143).
-// That is a property of the library rather than of the host, so #104's "nothing
-// here reads the machine" stands.
-//
-// It is also the whole of the ceiling. `-w 500000 -t 1ns` completes cleanly and
-// exits 0, so any lower bound would be invented, and the number it would have to
-// be measured against is GOMAXPROCS — which is the machine. A count far above
-// the cores on offer is slow to shut down rather than wrong, and Run says what
-// that wait is for instead (#122).
+// validate is stress.Cfg.Validate with the command's own bounds on the report
+// interval checked first, in the command's words. Report 0 is off; an interval
+// that is on has a floor always and a ceiling on a bounded run, because outside
+// them it is not one anybody asked for: under reportFloor it is the run (#114),
+// and past the timeout it is a line that never prints, which is the shape of
+// `-r 1m` typed where `-r 1s` was meant (#115). The stress package checks the
+// ceiling too, but says progress rather than a line, having none to print.
 //
 // dispatch calls it so a rejected configuration is reported before the first
 // worker starts; Run calls it again for a caller that never came through the
-// command. Both matter: Workers 0 starts no goroutines and idles forever, and
-// Report -1 panics inside time.NewTicker.
+// command.
 func (c Cfg) validate() error {
+	if err := c.validateWindow(time.Now()); err != nil {
+		return err
+	}
+
+	// An Until is a Timeout from the start, and everything a Timeout bounds —
+	// --report here, the latency probe in the stress package — it bounds too.
+	if !c.Until.IsZero() {
+		c.Timeout = c.untilTimeout(time.Now())
+	}
+
 	switch {
-	case c.Workers < 1:
-		return fmt.Errorf("workers must be 1 or greater")
-	// The number is said to whoever exceeded it and nowhere else — an int32
-	// width in the help or the flag list is the program reading its arithmetic
-	// aloud at everyone, which is what #129 took out of the parser. Unreachable
-	// on a 32-bit build, where int is that width already and nothing can get
-	// past it to overflow; every published binary is 64-bit.
-	case c.Workers > math.MaxInt32:
-		return fmt.Errorf("workers must be %d or fewer", math.MaxInt32)
-	case c.Timeout < 0:
-		return fmt.Errorf("timeout must be 0 (indefinite) or greater")
 	case c.Report < 0, c.Report > 0 && c.Report < reportFloor:
 		return fmt.Errorf("report must be 0 (off) or %s or greater", reportFloor)
 	// An indefinite run outlives every interval, so only a bounded one can be
@@ -416,37 +799,42 @@ func (c Cfg) validate() error {
 	// prints first is the runtime's to order.
 	case c.Timeout > 0 && c.Report > c.Timeout:
 		return fmt.Errorf("report %s is longer than timeout %s, so no progress line would print", c.Report, c.Timeout)
+	case c.StallTimeout < 0, c.StallTimeout > 0 && c.StallTimeout < stallFloor:
+		return fmt.Errorf("stall timeout must be 0 (off) or %s or greater", stallFloor)
 	}
 
-	return nil
+	return c.Cfg.Validate()
 }
 
-// stressTestCPU computes bcrypt hashes at hashCost until ctx is cancelled,
-// counting each into hashes as it goes. That is where the whole of the load
-// lives: bcrypt salts every call itself, so nothing outside the hash has to vary
-// for the work to be real.
-//
-// A worker returns one hash after ctx is done — one hash, not one hash's worth
-// of seconds. Past GOMAXPROCS workers that hash is sharing a core with the rest,
-// so the wall clock it takes stretches with how many there are, and the run does
-// not end until the last worker is through (#122).
-func stressTestCPU(ctx context.Context, hashes *atomic.Uint64) {
-	// Hoisted; a constant also stays well inside bcrypt's 72-byte limit.
-	password := []byte("stressy")
+// describe is the stress package's description of the stressor called name,
+// and of the default for "". validate has turned away any other name before a
+// line is built.
+func describe(name string) stress.Stressor {
+	all := stress.Stressors()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			// Unreachable in practice: the cost is a valid constant and the
-			// password is seven bytes, which leaves salt generation as the
-			// only error source, and crypto/rand no longer reports failure.
-			if _, err := bcrypt.GenerateFromPassword(password, hashCost); err != nil {
-				panic(err)
-			}
-
-			hashes.Add(1)
+	for _, s := range all {
+		if s.Name == name {
+			return s
 		}
 	}
+
+	return all[0]
+}
+
+// knownStressor is whether name is a stressor the flags may be given. "" is
+// not: it is what an unset --stressor leaves, not a spelling of bcrypt.
+func knownStressor(name string) bool {
+	return slices.ContainsFunc(stress.Stressors(), func(s stress.Stressor) bool { return s.Name == name })
+}
+
+// stressorNames lists the stressors for a message or a usage text.
+func stressorNames() string {
+	all := stress.Stressors()
+
+	names := make([]string, len(all))
+	for i, s := range all {
+		names[i] = s.Name
+	}
+
+	return strings.Join(names, ", ")
 }
@@ -10,9 +10,12 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
 )
 
 // TestRunStopsOnSignal covers #14's race and #48's which-shutdown guarantee.
@@ -37,7 +40,7 @@ func TestRunStopsOnSignal(t *testing.T) {
 			defer signal.Stop(guard)
 
 			done := make(chan error, 1)
-			go func() { done <- Cfg{Workers: 1, Timeout: tt.timeout, Out: io.Discard}.Run() }()
+			go func() { done <- Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: tt.timeout}, Out: io.Discard}.Run() }()
 
 			// Run installs its handler asynchronously, so signal until it takes.
 			deadline := time.After(stopBudget)
@@ -78,3 +81,127 @@ func TestRunStopsOnSignal(t *testing.T) {
 		})
 	}
 }
+
+// lineWriter hands each line written to it to whoever reads the channel, for a
+// test that has to wait on a run's output before signalling it again.
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+
+	return len(p), nil
+}
+
+// TestRunAnswersControlSignals covers the signals that do not end a run: a
+// progress line for SIGUSR1 on a run with no --report, a pause and a resume for
+// SIGUSR2 twice, and a summary that names the time paused.
+func TestRunAnswersControlSignals(t *testing.T) {
+	// Keeps the binary alive if a signal beats Run's own handler.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGINT)
+	defer signal.Stop(guard)
+
+	out := make(lineWriter, 16)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Cfg{Cfg: stress.Cfg{Workers: 1, Stressor: "contention", Modes: []string{"atomic"}}, Out: out}.Run()
+	}()
+
+	// expect reads lines until one contains want, and returns it.
+	expect := func(want string) string {
+		t.Helper()
+
+		for {
+			select {
+			case line := <-out:
+				if strings.Contains(line, want) {
+					return line
+				}
+			case <-time.After(stopBudget):
+				t.Fatalf("Run() printed no line with %q within %s", want, stopBudget)
+			}
+		}
+	}
+
+	kill := func(sig syscall.Signal) {
+		t.Helper()
+
+		if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+			t.Fatalf("Kill(%v) error = %v", sig, err)
+		}
+	}
+
+	// The handler is installed before the startup line is printed.
+	expect("Starting ")
+
+	kill(syscall.SIGUSR1)
+
+	if line := expect(" elapsed, "); strings.Contains(line, " paused") {
+		t.Errorf("progress line = %q before any pause, want no paused clause", line)
+	}
+
+	kill(syscall.SIGUSR2)
+	expect("Paused at ")
+
+	time.Sleep(50 * time.Millisecond)
+
+	kill(syscall.SIGUSR2)
+	expect("Resumed at ")
+
+	kill(syscall.SIGINT)
+
+	if line := expect("Computed "); !strings.HasSuffix(line, " paused)\n") {
+		t.Errorf("summary = %q, want it to name the time paused", line)
+	}
+
+	var sigErr *SignalError
+	if err := <-done; !errors.As(err, &sigErr) || sigErr.Signal != syscall.SIGINT {
+		t.Errorf("Run() error = %v, want the SIGINT that ended it", err)
+	}
+}
+
+// TestASignalBeforeTheStartRunsNothing: a run stopped while it counts down exits
+// with the signal's code, as one stopped while running does, and says that no
+// worker ran in place of a summary it does not have.
+func TestASignalBeforeTheStartRunsNothing(t *testing.T) {
+	// Keeps the binary alive if a signal beats Run's own handler.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGTERM)
+	defer signal.Stop(guard)
+
+	out := make(lineWriter, 16)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Cfg{Cfg: stress.Cfg{Workers: 1}, Out: out, StartAt: time.Now().Add(time.Hour)}.Run()
+	}()
+
+	// The handler is installed before the countdown line is printed.
+	select {
+	case line := <-out:
+		if !strings.HasPrefix(line, "Waiting to start at ") {
+			t.Fatalf("Run() printed %q first, want the countdown", line)
+		}
+	case <-time.After(stopBudget):
+		t.Fatalf("Run() printed nothing within %s", stopBudget)
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Kill(SIGTERM) error = %v", err)
+	}
+
+	select {
+	case err := <-done:
+		var sigErr *SignalError
+		if !errors.As(err, &sigErr) || sigErr.ExitCode() != 143 {
+			t.Errorf("Run() error = %v, want SIGTERM's exit code, 143", err)
+		}
+	case <-time.After(stopBudget):
+		t.Fatalf("Run() did not return within %s of SIGTERM", stopBudget)
+	}
+
+	if line := <-out; line != "Received SIGTERM before the start; no worker was started\n" {
+		t.Errorf("Run() printed %q after the signal, want the line that says nothing ran", line)
+	}
+}
@@ -4,14 +4,14 @@ import (
 	"bytes"
 	"context"
 	"io"
-	"math"
 	"os"
 	"regexp"
 	"strings"
-	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
 )
 
 // stopBudget bounds how long a worker may take to observe cancellation. It is
@@ -22,7 +22,7 @@ import (
 const stopBudget = 30 * time.Second
 
 // progressLine is the shape a --report tick prints; its numbers are measured.
-var progressLine = regexp.MustCompile(`^\S+ elapsed, (\d+) hash(?:es)?, \d+\.\d+ hashes/s$`)
+var progressLine = regexp.MustCompile(`^\S+ elapsed, (\d+) hash(?:es)?, \d+\.\d+ hashes/s(?:, \S+ paused)?$`)
 
 func TestValidate(t *testing.T) {
 	tests := []struct {
@@ -30,31 +30,26 @@ func TestValidate(t *testing.T) {
 		cfg     Cfg
 		wantErr string
 	}{
-		{name: "one worker, indefinite", cfg: Cfg{Workers: 1, Timeout: 0}},
-		{name: "one worker, one second", cfg: Cfg{Workers: 1, Timeout: time.Second}},
-		{name: "many workers", cfg: Cfg{Workers: 64, Timeout: time.Minute}},
-		{name: "sub-second timeout", cfg: Cfg{Workers: 1, Timeout: 250 * time.Millisecond}},
-		{name: "reporting off", cfg: Cfg{Workers: 1, Timeout: 30 * time.Second, Report: 0}},
-		{name: "reporting on", cfg: Cfg{Workers: 1, Timeout: time.Minute, Report: 30 * time.Second}},
-		{name: "report at the floor", cfg: Cfg{Workers: 1, Timeout: time.Minute, Report: reportFloor}},
+		{name: "one worker, indefinite", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 0}}},
+		{name: "reporting off", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 30 * time.Second, Report: 0}}},
+		{name: "reporting on", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Minute, Report: 30 * time.Second}}},
+		{name: "report at the floor", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Minute, Report: reportFloor}}},
 		// The boundary #115 leaves open: one tick, landing on the deadline.
-		{name: "report as long as the run", cfg: Cfg{Workers: 1, Timeout: time.Second, Report: time.Second}},
+		{name: "report as long as the run", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Second, Report: time.Second}}},
 		// An indefinite run outlives every interval, so none is too long for it.
-		{name: "a long report on an indefinite run", cfg: Cfg{Workers: 1, Timeout: 0, Report: time.Hour}},
-		// The ceiling is sync.WaitGroup's counter, so the last accepted value is
-		// the largest wg.Add can hold rather than a number anybody would run.
-		{name: "workers at the WaitGroup ceiling", cfg: Cfg{Workers: math.MaxInt32, Timeout: time.Second}},
-		{name: "zero workers", cfg: Cfg{Workers: 0, Timeout: 0}, wantErr: "workers must be 1 or greater"},
-		{name: "negative workers", cfg: Cfg{Workers: -1, Timeout: 0}, wantErr: "workers must be 1 or greater"},
-		// #143: wg.Add(2^31) wrapped negative and the run panicked out at exit 2.
-		{name: "workers one past the WaitGroup ceiling", cfg: Cfg{Workers: math.MaxInt32 + 1, Timeout: 0}, wantErr: "workers must be 2147483647 or fewer"},
-		{name: "negative timeout", cfg: Cfg{Workers: 1, Timeout: -time.Second}, wantErr: "timeout must be 0 (indefinite) or greater"},
-		{name: "negative report", cfg: Cfg{Workers: 1, Report: -time.Second}, wantErr: "report must be 0 (off) or 1s or greater"},
+		{name: "a long report on an indefinite run", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 0, Report: time.Hour}}},
+		// The stress package's own checks come through it as they are.
+		{name: "zero workers", cfg: Cfg{Cfg: stress.Cfg{Workers: 0, Timeout: 0}}, wantErr: "workers must be 1 or greater"},
+		{name: "negative report", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Report: -time.Second}}, wantErr: "report must be 0 (off) or 1s or greater"},
 		// #114: `-t 1s -r 1ns` put hundreds of thousands of lines on stdout.
-		{name: "report of a nanosecond", cfg: Cfg{Workers: 1, Timeout: time.Second, Report: time.Nanosecond}, wantErr: "report must be 0 (off) or 1s or greater"},
-		{name: "report just under the floor", cfg: Cfg{Workers: 1, Timeout: time.Minute, Report: reportFloor - time.Nanosecond}, wantErr: "report must be 0 (off) or 1s or greater"},
+		{name: "report of a nanosecond", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Second, Report: time.Nanosecond}}, wantErr: "report must be 0 (off) or 1s or greater"},
+		{name: "report just under the floor", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Minute, Report: reportFloor - time.Nanosecond}}, wantErr: "report must be 0 (off) or 1s or greater"},
 		// #115: three lines and exit 0, which is what `-r 1s` mistyped looks like.
-		{name: "report longer than the run", cfg: Cfg{Workers: 1, Timeout: 3 * time.Second, Report: time.Minute}, wantErr: "report 1m0s is longer than timeout 3s, so no progress line would print"},
+		{name: "report longer than the run", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 3 * time.Second, Report: time.Minute}}, wantErr: "report 1m0s is longer than timeout 3s, so no progress line would print"},
+		{name: "stall timeout at the floor", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, StallTimeout: stallFloor}}},
+		// A hash on a slow core is most of a second, and a smoke test that fails good hardware is worse than none.
+		{name: "stall timeout under the floor", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, StallTimeout: 500 * time.Millisecond}}, wantErr: "stall timeout must be 0 (off) or 1s or greater"},
+		{name: "a stressor there is none of", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Stressor: "disk"}}, wantErr: "stressor must be one of bcrypt, contention, cache, gc, syscall"},
 	}
 
 	for _, tt := range tests {
@@ -81,25 +76,45 @@ func TestValidate(t *testing.T) {
 // TestStartupMessage covers #17c: the default run announced itself as "1 workers".
 func TestStartupMessage(t *testing.T) {
 	tests := []struct {
-		name string
-		cfg  Cfg
-		want string
+		name    string
+		timeout time.Duration
+		g       stress.GroupResult
+		want    string
 	}{
-		{name: "one worker", cfg: Cfg{Workers: 1}, want: "Starting CPU stress test with 1 worker indefinitely"},
-		{name: "several workers", cfg: Cfg{Workers: 4}, want: "Starting CPU stress test with 4 workers indefinitely"},
-		{name: "one worker, bounded", cfg: Cfg{Workers: 1, Timeout: 5 * time.Minute}, want: "Starting CPU stress test with 1 worker for 5m0s"},
-		{name: "several workers, bounded", cfg: Cfg{Workers: 4, Timeout: 30 * time.Second}, want: "Starting CPU stress test with 4 workers for 30s"},
+		{name: "one worker", g: group("bcrypt", 1), want: "Starting CPU stress test with 1 worker indefinitely"},
+		{name: "several workers", g: group("bcrypt", 4), want: "Starting CPU stress test with 4 workers indefinitely"},
+		{name: "one worker, bounded", timeout: 5 * time.Minute, g: group("bcrypt", 1), want: "Starting CPU stress test with 1 worker for 5m0s"},
+		{name: "several workers, bounded", timeout: 30 * time.Second, g: group("bcrypt", 4), want: "Starting CPU stress test with 4 workers for 30s"},
+		{name: "contention, every mode", timeout: time.Minute, g: group("contention", 4, "atomic", "unpadded", "padded", "mutex"), want: "Starting contention stress test with 4 workers for 1m0s, modes atomic, unpadded, padded, mutex"},
+		{name: "contention, one mode", g: group("contention", 2, "mutex"), want: "Starting contention stress test with 2 workers indefinitely, mode mutex"},
+		{name: "cache, the auto tiers", timeout: time.Minute, g: group("cache", 1, "16KiB", "512KiB", "8MiB", "128MiB"), want: "Starting cache stress test with 1 worker for 1m0s, working sets 16KiB, 512KiB, 8MiB, 128MiB"},
+		{name: "cache, one working set", g: group("cache", 1, "1MiB"), want: "Starting cache stress test with 1 worker indefinitely, working set 1MiB"},
+		{name: "gc, which has no variants", g: group("gc", 2), want: "Starting GC stress test with 2 workers indefinitely"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.cfg.startupMessage(); got != tt.want {
+			cfg := Cfg{Cfg: stress.Cfg{Timeout: tt.timeout}}
+
+			if got := cfg.startupMessage([]stress.GroupResult{tt.g}); got != tt.want {
 				t.Errorf("startupMessage() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
 
+// group is the GroupResult a run of workers on stressor has before any of them
+// has done anything, taking turns between the variants named.
+func group(stressor string, workers int, variants ...string) stress.GroupResult {
+	g := stress.GroupResult{Stressor: stressor, Workers: workers}
+
+	for _, name := range variants {
+		g.Variants = append(g.Variants, stress.VariantResult{Name: name})
+	}
+
+	return g
+}
+
 // TestHintMessage covers #52: the pointer printed always, the stop hint never.
 func TestHintMessage(t *testing.T) {
 	tests := []struct {
@@ -107,10 +122,10 @@ func TestHintMessage(t *testing.T) {
 		cfg  Cfg
 		want string
 	}{
-		{name: "indefinite", cfg: Cfg{Workers: 1}, want: "Press Ctrl+C or send SIGTERM to stop. Use --help for additional information"},
-		{name: "indefinite, several workers", cfg: Cfg{Workers: 4}, want: "Press Ctrl+C or send SIGTERM to stop. Use --help for additional information"},
-		{name: "bounded", cfg: Cfg{Workers: 1, Timeout: 5 * time.Minute}},
-		{name: "bounded, sub-second", cfg: Cfg{Workers: 4, Timeout: 250 * time.Millisecond}},
+		{name: "indefinite", cfg: Cfg{Cfg: stress.Cfg{Workers: 1}}, want: "Press Ctrl+C or send SIGTERM to stop. Use --help for additional information"},
+		{name: "indefinite, several workers", cfg: Cfg{Cfg: stress.Cfg{Workers: 4}}, want: "Press Ctrl+C or send SIGTERM to stop. Use --help for additional information"},
+		{name: "bounded", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 5 * time.Minute}}},
+		{name: "bounded, sub-second", cfg: Cfg{Cfg: stress.Cfg{Workers: 4, Timeout: 250 * time.Millisecond}}},
 	}
 
 	for _, tt := range tests {
@@ -129,7 +144,7 @@ func TestHintMessageNamesEverySignalARunStopsOn(t *testing.T) {
 		syscall.SIGTERM: "SIGTERM",
 	}
 
-	hint := Cfg{Workers: 1}.hintMessage()
+	hint := Cfg{Cfg: stress.Cfg{Workers: 1}}.hintMessage()
 
 	for _, sig := range shutdownSignals {
 		want, ok := spellings[sig]
@@ -151,6 +166,7 @@ func TestProgressMessage(t *testing.T) {
 		name    string
 		hashes  uint64
 		elapsed time.Duration
+		paused  time.Duration
 		want    string
 	}{
 		{name: "a minute in", hashes: 1320, elapsed: 60001 * time.Millisecond, want: "1m0.001s elapsed, 1320 hashes, 22.0 hashes/s"},
@@ -160,11 +176,14 @@ func TestProgressMessage(t *testing.T) {
 		// A late tick says when it fired; the interval never reaches this function.
 		{name: "a tick delivered late", hashes: 1650, elapsed: 7 * time.Minute, want: "7m0s elapsed, 1650 hashes, 3.9 hashes/s"},
 		{name: "no time passed at all", hashes: 0, elapsed: 0, want: "0s elapsed, 0 hashes, 0.0 hashes/s"},
+		// The rate is over the 40s the workers ran, and the line says why it is not over the 70s.
+		{name: "paused for half a minute", hashes: 880, elapsed: 70 * time.Second, paused: 30 * time.Second, want: "1m10s elapsed, 880 hashes, 22.0 hashes/s, 30s paused"},
+		{name: "paused all along", hashes: 0, elapsed: 5 * time.Second, paused: 5 * time.Second, want: "5s elapsed, 0 hashes, 0.0 hashes/s, 5s paused"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := progressMessage(tt.hashes, tt.elapsed)
+			got := Cfg{}.progressMessage(tt.hashes, tt.elapsed, tt.paused)
 
 			if got != tt.want {
 				t.Errorf("progressMessage(%d, %s) = %q, want %q", tt.hashes, tt.elapsed, got, tt.want)
@@ -203,7 +222,7 @@ func TestShutdownMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := shutdownMessage(tt.sig); got != tt.want {
+			if got := (Cfg{}).shutdownMessage(tt.sig); got != tt.want {
 				t.Errorf("shutdownMessage(%v) = %q, want %q", tt.sig, got, tt.want)
 			}
 		})
@@ -227,7 +246,7 @@ func TestShutdownMessageNamesEverySignalARunStopsOn(t *testing.T) {
 			continue
 		}
 
-		if got := shutdownMessage(sig); !strings.HasPrefix(got, "Received "+want+", shutting down;") {
+		if got := (Cfg{}).shutdownMessage(sig); !strings.HasPrefix(got, "Received "+want+", shutting down;") {
 			t.Errorf("shutdownMessage(%v) = %q, want it to name the signal %q (#111)", sig, got, want)
 		}
 	}
@@ -240,18 +259,20 @@ func TestSummaryMessage(t *testing.T) {
 		cfg     Cfg
 		hashes  uint64
 		elapsed time.Duration
+		paused  time.Duration
 		want    string
 	}{
-		{name: "several workers", cfg: Cfg{Workers: 4, Timeout: time.Minute}, hashes: 1324, elapsed: 60100 * time.Millisecond, want: "Computed 1324 hashes in 1m0.1s (22.0 hashes/s, 4 workers)"},
-		{name: "one worker, one hash", cfg: Cfg{Workers: 1, Timeout: 200 * time.Millisecond}, hashes: 1, elapsed: 200 * time.Millisecond, want: "Computed 1 hash in 200ms (5.0 hashes/s, 1 worker)"},
-		{name: "interrupted before the first hash", cfg: Cfg{Workers: 2}, hashes: 0, elapsed: 3 * time.Millisecond, want: "Computed 0 hashes in 3ms (0.0 hashes/s, 2 workers)"},
-		{name: "elapsed time is rounded", cfg: Cfg{Workers: 1, Timeout: 2 * time.Second}, hashes: 11, elapsed: 2*time.Second + 1499*time.Microsecond, want: "Computed 11 hashes in 2.001s (5.5 hashes/s, 1 worker)"},
-		{name: "no time passed at all", cfg: Cfg{Workers: 1}, hashes: 0, elapsed: 0, want: "Computed 0 hashes in 0s (0.0 hashes/s, 1 worker)"},
+		{name: "several workers", cfg: Cfg{Cfg: stress.Cfg{Workers: 4, Timeout: time.Minute}}, hashes: 1324, elapsed: 60100 * time.Millisecond, want: "Computed 1324 hashes in 1m0.1s (22.0 hashes/s, 4 workers)"},
+		{name: "one worker, one hash", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 200 * time.Millisecond}}, hashes: 1, elapsed: 200 * time.Millisecond, want: "Computed 1 hash in 200ms (5.0 hashes/s, 1 worker)"},
+		{name: "interrupted before the first hash", cfg: Cfg{Cfg: stress.Cfg{Workers: 2}}, hashes: 0, elapsed: 3 * time.Millisecond, want: "Computed 0 hashes in 3ms (0.0 hashes/s, 2 workers)"},
+		{name: "elapsed time is rounded", cfg: Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 2 * time.Second}}, hashes: 11, elapsed: 2*time.Second + 1499*time.Microsecond, want: "Computed 11 hashes in 2.001s (5.5 hashes/s, 1 worker)"},
+		{name: "no time passed at all", cfg: Cfg{Cfg: stress.Cfg{Workers: 1}}, hashes: 0, elapsed: 0, want: "Computed 0 hashes in 0s (0.0 hashes/s, 1 worker)"},
+		{name: "paused for a while", cfg: Cfg{Cfg: stress.Cfg{Workers: 4, Timeout: time.Minute}}, hashes: 880, elapsed: 60100 * time.Millisecond, paused: 20100 * time.Millisecond, want: "Computed 880 hashes in 1m0.1s (22.0 hashes/s, 4 workers, 20.1s paused)"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.cfg.summaryMessage(tt.hashes, tt.elapsed); got != tt.want {
+			if got := tt.cfg.summaryMessage(tt.hashes, tt.elapsed, tt.paused); got != tt.want {
 				t.Errorf("summaryMessage(%d, %s) = %q, want %q", tt.hashes, tt.elapsed, got, tt.want)
 			}
 		})
@@ -295,111 +316,22 @@ func (unnumberedSignal) Signal()        {}
 
 // TestRunRejectsInvalidConfig covers Run's gate, which fails before any worker starts.
 func TestRunRejectsInvalidConfig(t *testing.T) {
-	if err := (Cfg{Workers: 0}).Run(); err == nil {
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 0}}).Run(); err == nil {
 		t.Error("Run() error = nil, want a validation error")
 	}
 
-	if err := (Cfg{Workers: 1, Timeout: -time.Second}).Run(); err == nil {
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: -time.Second}}).Run(); err == nil {
 		t.Error("Run() error = nil, want a validation error")
 	}
 }
 
-// TestStressTestCPUStopsWhenCancelled is #15: the check ran once every ~26 hours.
-func TestStressTestCPUStopsWhenCancelled(t *testing.T) {
-	tests := []struct {
-		name string
-		// ctx is cancelled before the worker reads it, or mid-hash.
-		ctx func(t *testing.T) context.Context
-		// wantNoHashes is set where the count is decided rather than raced.
-		wantNoHashes bool
-	}{
-		{
-			name: "cancelled before the worker starts",
-			ctx: func(t *testing.T) context.Context {
-				ctx, cancel := context.WithCancel(context.Background())
-				cancel()
-				t.Cleanup(cancel)
-				return ctx
-			},
-			wantNoHashes: true,
-		},
-		{
-			name: "cancelled while the worker is hashing",
-			ctx: func(t *testing.T) context.Context {
-				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
-				t.Cleanup(cancel)
-				return ctx
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// One writer, no reader until it returns, so the read below is safe.
-			var hashes atomic.Uint64
-
-			done := make(chan struct{})
-			go func() {
-				defer close(done)
-
-				stressTestCPU(tt.ctx(t), &hashes)
-			}()
-
-			select {
-			case <-done:
-				if tt.wantNoHashes && hashes.Load() != 0 {
-					t.Errorf("stressTestCPU published %d, want 0 from an already-cancelled context", hashes.Load())
-				}
-			case <-time.After(stopBudget):
-				t.Fatalf("stressTestCPU did not return within %s of cancellation", stopBudget)
-			}
-		})
-	}
-}
-
-// TestStressTestCPUPublishesAsItGoes: the count has to be readable mid-run (#70).
-func TestStressTestCPUPublishesAsItGoes(t *testing.T) {
-	var hashes atomic.Uint64
-
-	// Cancelled on a count rather than a deadline, which would be a second budget.
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-
-		stressTestCPU(ctx, &hashes)
-	}()
-
-	deadline := time.After(stopBudget)
-
-	for hashes.Load() == 0 {
-		select {
-		case <-done:
-			t.Fatal("stressTestCPU returned before it published a hash, so nothing can read its count mid-run (#70)")
-		case <-deadline:
-			t.Fatalf("stressTestCPU published no hash within %s while still running (#70)", stopBudget)
-		case <-time.After(10 * time.Millisecond):
-		}
-	}
-
-	cancel()
-
-	select {
-	case <-done:
-	case <-time.After(stopBudget):
-		t.Fatalf("stressTestCPU did not return within %s of cancellation", stopBudget)
-	}
-}
-
 // TestRunStopsAtTimeout: Run waits on its workers, so returning is the evidence.
 func TestRunStopsAtTimeout(t *testing.T) {
 	const timeout = 10 * time.Millisecond
 
 	start := time.Now()
 	done := make(chan error, 1)
-	go func() { done <- Cfg{Workers: 2, Timeout: timeout, Out: io.Discard}.Run() }()
+	go func() { done <- Cfg{Cfg: stress.Cfg{Workers: 2, Timeout: timeout}, Out: io.Discard}.Run() }()
 
 	select {
 	case err := <-done:
@@ -420,7 +352,7 @@ func TestRunDefaultOutputIsUnchanged(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	if err := (Cfg{Workers: 1, Timeout: timeout, Out: &buf}).Run(); err != nil {
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: timeout}, Out: &buf}).Run(); err != nil {
 		t.Errorf("Run() error = %v, want nil", err)
 	}
 
@@ -442,6 +374,37 @@ func TestRunDefaultOutputIsUnchanged(t *testing.T) {
 	}
 }
 
+// shutdowns is an Observer that keeps the shutdown it is told of and nothing
+// else.
+type shutdowns struct {
+	got []stress.ShutdownEvent
+}
+
+func (o *shutdowns) OnStart(stress.StartEvent)          {}
+func (o *shutdowns) OnProgress(stress.Result)           {}
+func (o *shutdowns) OnSummary(stress.Result)            {}
+func (o *shutdowns) OnShutdown(ev stress.ShutdownEvent) { o.got = append(o.got, ev) }
+
+// TestRunWithAnObserverPrintsNothing: an Observer replaces the text output
+// rather than joining it, and is told why the run stopped as the text would be.
+func TestRunWithAnObserverPrintsNothing(t *testing.T) {
+	var buf bytes.Buffer
+
+	obs := &shutdowns{}
+
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: 10 * time.Millisecond, Observer: obs}, Out: &buf}).Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Run() printed:\n%s\nwant nothing where an Observer is set", buf.String())
+	}
+
+	if len(obs.got) != 1 || obs.got[0].Reason != stress.StopTimeout || obs.got[0].Cause != nil {
+		t.Errorf("OnShutdown() got %+v, want the one shutdown, by the timer", obs.got)
+	}
+}
+
 // TestRunPrintsProgressWhenAsked is #70's wiring: the ticker starts and repeats.
 func TestRunPrintsProgressWhenAsked(t *testing.T) {
 	const (
@@ -454,7 +417,7 @@ func TestRunPrintsProgressWhenAsked(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	if err := (Cfg{Workers: 1, Timeout: timeout, Report: report, Out: &buf}).Run(); err != nil {
+	if err := (Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: timeout, Report: report}, Out: &buf}).Run(); err != nil {
 		t.Errorf("Run() error = %v, want nil", err)
 	}
 
@@ -535,9 +498,7 @@ func TestWaitForShutdownPrefersAPendingSignal(t *testing.T) {
 					received <- tt.pending
 				}
 
-				var hashes atomic.Uint64
-
-				got := Cfg{Workers: 1, Out: io.Discard}.waitForShutdown(ctx, received, &hashes, time.Now())
+				got := waitForShutdown(ctx.Done(), received, nil, nil)
 				if got != tt.want {
 					t.Fatalf("waitForShutdown() = %v on call %d of %d, want %v: a run a signal ended must not be reported as one the timer ended (#117)", got, i+1, calls, tt.want)
 				}
@@ -546,9 +507,40 @@ func TestWaitForShutdownPrefersAPendingSignal(t *testing.T) {
 	}
 }
 
+// TestWaitForShutdownAnswersControlSignals: a signal on the control channel is
+// answered and the wait goes on, to end on the shutdown signal after it.
+func TestWaitForShutdownAnswersControlSignals(t *testing.T) {
+	received := make(chan os.Signal, 1)
+	control := make(chan os.Signal, 1)
+
+	var answered []os.Signal
+
+	answer := func(sig os.Signal) {
+		answered = append(answered, sig)
+
+		// Sent from inside the answer, so it is only there once the control
+		// signal has been taken.
+		if len(answered) == 1 {
+			control <- unnumberedSignal{}
+		} else {
+			received <- syscall.SIGTERM
+		}
+	}
+
+	control <- unnumberedSignal{}
+
+	if got := waitForShutdown(make(chan struct{}), received, control, answer); got != syscall.SIGTERM {
+		t.Errorf("waitForShutdown() = %v, want %v", got, syscall.SIGTERM)
+	}
+
+	if len(answered) != 2 {
+		t.Errorf("waitForShutdown() answered %v, want both control signals", answered)
+	}
+}
+
 // TestRunIsReusable covers #14's third item: a second call panicked on a channel.
 func TestRunIsReusable(t *testing.T) {
-	cfg := Cfg{Workers: 1, Timeout: time.Millisecond, Out: io.Discard}
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Millisecond}, Out: io.Discard}
 
 	for i := range 2 {
 		done := make(chan error, 1)
@@ -0,0 +1,83 @@
+package stressy
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// TestRunReportsEverySyscallMode runs the modes through Run, exec included,
+// which is this test binary starting up, running no test and exiting again:
+// Main is what makes stressy its own exec child, and a test binary is not run
+// through it.
+func TestRunReportsEverySyscallMode(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := Cfg{Cfg: stress.Cfg{Workers: 2, Timeout: 500 * time.Millisecond, Stressor: "syscall", Exec: []string{os.Args[0], "-test.run=^$"}}, Out: &buf}
+
+	if err := cfg.Run(); err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	out := buf.String()
+
+	for _, mode := range describe("syscall").Modes {
+		want := "\nMode " + mode + ": "
+		if !strings.Contains(out, want) {
+			t.Errorf("Run() printed:\n%s\nwant it to contain %q", out, want)
+		}
+	}
+
+	if !strings.HasPrefix(out, "Starting syscall stress test with 2 workers for 500ms, modes getpid, ") {
+		t.Errorf("Run() printed:\n%s\nwant the syscall startup line first", out)
+	}
+}
+
+// TestRunFailsWhereAModeCannotStart is a FROM scratch image, which has no /tmp
+// to open files in: the run is refused before its first line rather than
+// measuring nothing under the open mode's name.
+func TestRunFailsWhereAModeCannotStart(t *testing.T) {
+	missing := t.TempDir() + "/missing"
+	t.Setenv("TMPDIR", missing)
+	t.Setenv("TMP", missing)
+
+	var buf bytes.Buffer
+
+	err := Cfg{Cfg: stress.Cfg{Workers: 1, Timeout: time.Second, Stressor: "syscall", Modes: []string{"open"}}, Out: &buf}.Run()
+
+	if err == nil || !strings.HasPrefix(err.Error(), "syscall mode open: ") {
+		t.Errorf("Run() error = %v, want it to name the syscall mode open", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Run() printed %q, want nothing from a run that never started", buf.String())
+	}
+}
+
+// TestExecIsWaitedOnAsItself: the drain of a syscall run waits on a batch of
+// calls or on one exec, and names whichever the run's modes have.
+func TestExecIsWaitedOnAsItself(t *testing.T) {
+	tests := []struct {
+		modes []string
+		want  string
+	}{
+		{modes: nil, want: "batch or exec"},
+		{modes: []string{"getpid", "pipe"}, want: "batch"},
+		{modes: []string{"exec"}, want: "exec"},
+	}
+
+	for _, tt := range tests {
+		if got := (Cfg{Cfg: stress.Cfg{Stressor: "syscall", Modes: tt.modes}}).step(); got != tt.want {
+			t.Errorf("step() of modes %q = %q, want %q", tt.modes, got, tt.want)
+		}
+	}
+
+	cfg := Cfg{Cfg: stress.Cfg{Stressor: "syscall", Modes: []string{"exec"}, TargetRate: 10}}
+	if got, want := cfg.rateClause(), ", open loop at 10.0 execs/s"; got != want {
+		t.Errorf("rateClause() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package stressy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// tempClause is what --max-temp adds to the startup line, ", up to 90C", so a
+// log that ends early says from its first line that it could.
+func (c Cfg) tempClause() string {
+	if c.MaxTemp == 0 {
+		return ""
+	}
+
+	return ", up to " + newTempValue(&c.MaxTemp).String()
+}
+
+// tempMessage is the shutdown line of a run its --max-temp ended: which zone
+// reached it, and how hot it was, in place of the timer's line. cause is the
+// stress package's *TempError, which is all a run ended this way is told.
+func (c Cfg) tempMessage(cause error) string {
+	drain := fmt.Sprintf(drainNotice, c.step())
+
+	var hot *stress.TempError
+	if !errors.As(cause, &hot) {
+		return "Temperature limit reached, shutting down; " + drain
+	}
+
+	return fmt.Sprintf("Temperature limit reached: %s at %.1fC, against --max-temp %s, shutting down; %s", hot.Zone, hot.Temp, newTempValue(&hot.Max), drain)
+}
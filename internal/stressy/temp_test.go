@@ -0,0 +1,84 @@
+package stressy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestTempMessages(t *testing.T) {
+	hot := &stress.TempError{Zone: "thermal_zone1 (x86_pkg_temp)", Temp: 91.5, Max: 90}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "startup", got: Cfg{Cfg: stress.Cfg{Timeout: time.Hour, MaxTemp: 90}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1h0m0s, up to 90C"},
+		{name: "startup, a fraction", got: Cfg{Cfg: stress.Cfg{Timeout: time.Hour, MaxTemp: 87.5}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1h0m0s, up to 87.5C"},
+		{name: "startup, none", got: Cfg{Cfg: stress.Cfg{Timeout: time.Hour}}.startupMessage([]stress.GroupResult{group("bcrypt", 4)}), want: "Starting CPU stress test with 4 workers for 1h0m0s"},
+		{name: "shutdown", got: Cfg{}.tempMessage(hot), want: "Temperature limit reached: thermal_zone1 (x86_pkg_temp) at 91.5C, against --max-temp 90C, shutting down; waiting for every worker to finish the hash it is on..."},
+		{name: "shutdown, no zone", got: Cfg{}.tempMessage(nil), want: "Temperature limit reached, shutting down; waiting for every worker to finish the hash it is on..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if got := (&HaltError{Cause: hot}).ExitCode(); got != exitTemp {
+		t.Errorf("ExitCode() = %d, want %d", got, exitTemp)
+	}
+}
+
+// TestRunStopsAtMaxTemp runs --max-temp from end to end against a thermal zone
+// already past it: the run ends at once, on the temperature's line rather than
+// the timer's, and Run returns the HaltError the exit code comes from.
+func TestRunStopsAtMaxTemp(t *testing.T) {
+	root := t.TempDir()
+
+	zone := filepath.Join(root, "sys/class/thermal/thermal_zone0")
+	if err := os.MkdirAll(zone, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(zone, "temp"), []byte("95000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+
+	c := Cfg{
+		Cfg: stress.Cfg{Workers: 1, Timeout: time.Minute, Stressor: "contention", Modes: []string{"atomic"}, MaxTemp: 90, Root: root},
+		Out: &out,
+	}
+
+	err := c.Run()
+
+	var halt *HaltError
+	if !errors.As(err, &halt) || halt.ExitCode() != exitTemp {
+		t.Fatalf("Run() error = %v, want a HaltError exiting %d", err, exitTemp)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+
+	want := []string{
+		"Starting contention stress test with 1 worker for 1m0s, mode atomic, up to 90C",
+		"Temperature limit reached: thermal_zone0 at 95.0C, against --max-temp 90C, shutting down; ",
+		"Computed ",
+	}
+	for i, w := range want {
+		if i >= len(lines) || !strings.HasPrefix(lines[i], w) {
+			t.Fatalf("Run() printed:\n%s\nwant line %d to start %q", out.String(), i, w)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package stressy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// thermalClause is what --thermal adds to every progress line: the CPUs'
+// frequencies and the hottest zone as the line was printed, "freq 1800-2200
+// MHz, 2000 avg, temp 88.5C". A machine with one of the two has that one.
+func thermalClause(s stress.ThermalStats) string {
+	var parts []string
+
+	if s.CPUs > 0 {
+		parts = append(parts, "freq "+freqRange(s.NowFreq))
+	}
+
+	if s.Zones > 0 {
+		parts = append(parts, fmt.Sprintf("temp %.1fC", s.NowTemp))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// thermalMessage is the line --thermal adds under the summary: the lowest and
+// highest frequency any CPU had over the run, the average, and the hottest
+// any zone was, over every sample the run took.
+func thermalMessage(s *stress.ThermalStats) string {
+	if s == nil {
+		return "Thermal: unknown; there is no cpufreq or thermal zone to read"
+	}
+
+	var parts []string
+
+	if s.CPUs > 0 {
+		parts = append(parts, "freq "+freqRange(s.Freq))
+	}
+
+	if s.Zones > 0 {
+		parts = append(parts, fmt.Sprintf("hottest %.1fC", s.Temp))
+	}
+
+	return fmt.Sprintf("Thermal: %s, over %d %s", strings.Join(parts, ", "), s.Samples, plural(s.Samples, "sample", "samples"))
+}
+
+// freqRange is "1800-2200 MHz, 2000 avg".
+func freqRange(f stress.FreqStats) string {
+	return fmt.Sprintf("%.0f-%.0f MHz, %.0f avg", f.Min, f.Max, f.Avg)
+}
@@ -0,0 +1,45 @@
+package stressy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestThermalMessages(t *testing.T) {
+	both := stress.ThermalStats{
+		Samples: 6, CPUs: 8, Zones: 3,
+		Freq:    stress.FreqStats{Min: 1800, Avg: 2612.4, Max: 3400},
+		NowFreq: stress.FreqStats{Min: 1800, Avg: 2000, Max: 2200},
+		Temp:    91.25, NowTemp: 88.5,
+	}
+
+	zone := stress.ThermalStats{Samples: 1, Zones: 1, Temp: 47, NowTemp: 47}
+	freq := stress.ThermalStats{Samples: 2, CPUs: 4, Freq: both.Freq, NowFreq: both.NowFreq}
+
+	r := stress.Result{Count: 8, Elapsed: 2 * time.Second, Groups: []stress.GroupResult{group("bcrypt", 2)}, Thermal: &both}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "clause", got: thermalClause(both), want: "freq 1800-2200 MHz, 2000 avg, temp 88.5C"},
+		{name: "clause, zones alone", got: thermalClause(zone), want: "temp 47.0C"},
+		{name: "clause, cpufreq alone", got: thermalClause(freq), want: "freq 1800-2200 MHz, 2000 avg"},
+		{name: "summary", got: thermalMessage(&both), want: "Thermal: freq 1800-3400 MHz, 2612 avg, hottest 91.2C, over 6 samples"},
+		{name: "summary, one sample", got: thermalMessage(&zone), want: "Thermal: hottest 47.0C, over 1 sample"},
+		{name: "summary, neither", got: thermalMessage(nil), want: "Thermal: unknown; there is no cpufreq or thermal zone to read"},
+		{name: "progress", got: Cfg{Thermal: true}.progressLine(r), want: "2s elapsed, 8 hashes, 4.0 hashes/s; freq 1800-2200 MHz, 2000 avg, temp 88.5C"},
+		{name: "progress, off", got: Cfg{}.progressLine(r), want: "2s elapsed, 8 hashes, 4.0 hashes/s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package stressy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+// throttleMessage is the line --throttling adds under the summary for the
+// cgroup's CPU quota: how many of the quota's periods ran out before the
+// cgroup's threads did, and the time they were held for it. A run throttled in
+// most periods is one the quota set the rate of, not the CPU.
+func throttleMessage(s *stress.CgroupStats) string {
+	switch {
+	case s == nil:
+		return "Throttled: unknown; there is no cgroup cpu.stat to read"
+	case s.Periods == 0:
+		return fmt.Sprintf("Throttled: never; the cgroup (v%d) has no CPU quota", s.Version)
+	}
+
+	return fmt.Sprintf(
+		"Throttled: %d of %d periods (%.0f%%), for %s in all (cgroup v%d)",
+		s.Throttled, s.Periods, 100*float64(s.Throttled)/float64(s.Periods),
+		s.ThrottledTime.Round(time.Millisecond), s.Version,
+	)
+}
+
+// pressureMessage is the line --throttling adds for the machine's CPU
+// pressure: the share of the run something was kept waiting for a CPU, and
+// the kernel's own averages as the run ended, in the words /proc/pressure/cpu
+// has them.
+func pressureMessage(s *stress.PressureStats) string {
+	if s == nil {
+		return "CPU pressure: unknown; the kernel keeps no /proc/pressure/cpu"
+	}
+
+	return fmt.Sprintf("CPU pressure: some %.1f%% of the run; avg10 %.2f, avg60 %.2f, avg300 %.2f at the end", s.Some, s.Avg10, s.Avg60, s.Avg300)
+}
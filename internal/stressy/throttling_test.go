@@ -0,0 +1,86 @@
+package stressy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felipeneuwald/stressy/stress"
+)
+
+func TestThrottlingMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "throttled", got: throttleMessage(&stress.CgroupStats{Version: 2, Periods: 30, Throttled: 12, ThrottledTime: 750123 * time.Microsecond}), want: "Throttled: 12 of 30 periods (40%), for 750ms in all (cgroup v2)"},
+		{name: "never throttled", got: throttleMessage(&stress.CgroupStats{Version: 1, Periods: 30}), want: "Throttled: 0 of 30 periods (0%), for 0s in all (cgroup v1)"},
+		{name: "no quota", got: throttleMessage(&stress.CgroupStats{Version: 2}), want: "Throttled: never; the cgroup (v2) has no CPU quota"},
+		{name: "no cgroup", got: throttleMessage(nil), want: "Throttled: unknown; there is no cgroup cpu.stat to read"},
+		{name: "pressure", got: pressureMessage(&stress.PressureStats{Some: 25, Avg10: 24.1, Avg60: 6.2, Avg300: 1.3}), want: "CPU pressure: some 25.0% of the run; avg10 24.10, avg60 6.20, avg300 1.30 at the end"},
+		{name: "no pressure", got: pressureMessage(nil), want: "CPU pressure: unknown; the kernel keeps no /proc/pressure/cpu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunPrintsItsThrottling runs --throttling from end to end against a tree
+// of files in place of /proc and /sys: the summary ends with what the tree
+// has, and without the flag the output has none of it (#70).
+func TestRunPrintsItsThrottling(t *testing.T) {
+	root := t.TempDir()
+
+	for path, contents := range map[string]string{
+		"proc/self/cgroup":       "0::/\n",
+		"sys/fs/cgroup/cpu.stat": "nr_periods 100\nnr_throttled 20\nthrottled_usec 1500000\n",
+		"proc/pressure/cpu":      "some avg10=1.50 avg60=0.80 avg300=0.20 total=2500000\n",
+	} {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, on := range []bool{false, true} {
+		var out bytes.Buffer
+
+		c := Cfg{
+			Cfg:        stress.Cfg{Workers: 1, Timeout: 100 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, Root: root},
+			Out:        &out,
+			Throttling: on,
+		}
+
+		if err := c.Run(); err != nil {
+			t.Fatalf("Run() error = %v, want nil", err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+		want := []string{
+			"Throttled: never; the cgroup (v2) has no CPU quota",
+			"CPU pressure: some 0.0% of the run; avg10 1.50, avg60 0.80, avg300 0.20 at the end",
+		}
+
+		if got := strings.Join(lines[len(lines)-2:], "\n") == strings.Join(want, "\n"); got != on {
+			t.Errorf("Throttling %t printed:\n%s\nwant the throttling lines %t", on, out.String(), on)
+		}
+
+		if !on && strings.Contains(out.String(), "Throttled") {
+			t.Errorf("Throttling off printed:\n%s\nwant no throttling", out.String())
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package units is how stressy spells the quantities it reads and prints —
+// sizes in binary units and durations in ASCII — shared by the stress package,
+// whose validation messages name them, and the command, whose flags parse them
+// and whose lines print them. It is internal so that neither spelling becomes
+// part of the stress package's API.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sizeUnits are the suffixes a size takes, largest first, which is the order
+// FormatSize tries them in. Binary only: a cache is sized in them, and "KB"
+// meaning 1000 or 1024 depending on who wrote it is a question not worth
+// answering for anyone.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int
+}{
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+// errSize is all ParseSize says about a size it cannot read. It checks no
+// range, and what a good size looks like depends on the flag it was typed for,
+// so the guidance is the caller's.
+var errSize = errors.New("not a size")
+
+// ParseSize parses a size such as 32KiB or 64MiB into bytes.
+func ParseSize(s string) (int, error) {
+	for _, u := range sizeUnits {
+		digits, ok := strings.CutSuffix(s, u.suffix)
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 0 || n > math.MaxInt/u.bytes {
+			break
+		}
+
+		return n * u.bytes, nil
+	}
+
+	return 0, errSize
+}
+
+// FormatSize is ParseSize backwards: the largest unit that divides the size
+// exactly, so what an operator typed is what the lines a run prints say.
+func FormatSize(n int) string {
+	for _, u := range sizeUnits {
+		if n%u.bytes == 0 && n >= u.bytes {
+			return strconv.Itoa(n/u.bytes) + u.suffix
+		}
+	}
+
+	return strconv.Itoa(n) + "B"
+}
+
+// FormatBytes is a measured amount of memory, to a tenth of the largest unit it
+// reaches. FormatSize is for sizes somebody typed and prints them exactly; a
+// heap's peak is not one, and "100663296B" helps no one.
+func FormatBytes(n uint64) string {
+	for _, u := range sizeUnits[:len(sizeUnits)-1] {
+		if n >= uint64(u.bytes) {
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(u.bytes), u.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%dB", n)
+}
+
+// FormatDuration is a duration as Duration spells it, with the µ of a
+// sub-millisecond one written u, which ParseDuration takes back: every line
+// stressy prints is ASCII, and an interval of 500µs is one an operator types.
+func FormatDuration(d time.Duration) string {
+	return strings.Replace(d.String(), "µ", "u", 1)
+}
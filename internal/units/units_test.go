@@ -0,0 +1,79 @@
+package units
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseSize covers the sizes --working-set takes, and FormatSize back.
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "32KiB", want: 32 << 10},
+		{in: "1MiB", want: 1 << 20},
+		{in: "2GiB", want: 2 << 30},
+		{in: "100B", want: 100},
+		// Decimal units are refused rather than guessed at.
+		{in: "32KB", wantErr: true},
+		{in: "32k", wantErr: true},
+		{in: "32", wantErr: true},
+		{in: "KiB", wantErr: true},
+		{in: "-1KiB", wantErr: true},
+		{in: "99999999999999999999GiB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSize(tt.in)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSize(%q) = %d, want an error", tt.in, got)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseSize(%q) = %d, %v; want %d, nil", tt.in, got, err, tt.want)
+			}
+
+			if back := FormatSize(got); back != tt.in {
+				t.Errorf("FormatSize(%d) = %q, want %q back", got, back, tt.in)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		in   uint64
+		want string
+	}{
+		{in: 512, want: "512B"},
+		{in: 1536, want: "1.5KiB"},
+		{in: 96 << 20, want: "96.0MiB"},
+		{in: 3 << 29, want: "1.5GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.in); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		500 * time.Microsecond:  "500us",
+		time.Millisecond:        "1ms",
+		1500 * time.Millisecond: "1.5s",
+	} {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%d) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -132,6 +132,8 @@ func TestExitCodes(t *testing.T) {
 			wantNoLines: []string{"stressy version"},
 			wantStderr:  `unexpected argument "extra"`,
 		},
+		// The exec mode's process starts nothing and prints nothing.
+		{name: "the syscall stressor's exec child", args: "--exec-child", wantNoLines: []string{"Starting "}},
 	}
 
 	for _, tt := range tests {
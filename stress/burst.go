@@ -0,0 +1,189 @@
+package stress
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Burst is a load that comes and goes, the way a neighbour's batch jobs do
+// rather than a steady plateau: the workers run for On, are held for Off, and
+// run again, for as long as the run lasts. Jitter, where it is set, lengthens or
+// shortens every period by up to that much, at random, so runs on many nodes do
+// not keep in step. The zero Burst is a steady run.
+//
+// The run starts on. The time held is charged to the run like any other: the
+// Result's rates are over the whole run, and its Burst has the rate over the on
+// periods alone beside them.
+type Burst struct {
+	On, Off time.Duration
+	Jitter  time.Duration
+}
+
+// validate reports whether b is a burst a run can keep, or the zero Burst.
+func (b Burst) validate() error {
+	if b == (Burst{}) {
+		return nil
+	}
+
+	switch {
+	case b.On <= 0 || b.Off <= 0:
+		return fmt.Errorf("burst on and off must both be greater than 0")
+	// Short of both, so no period is jittered to nothing or less.
+	case b.Jitter < 0 || b.Jitter >= min(b.On, b.Off):
+		return fmt.Errorf("burst jitter must be 0 or greater and shorter than both %s on and %s off", b.On, b.Off)
+	}
+
+	return nil
+}
+
+// jitter is d lengthened or shortened by up to b.Jitter.
+func (b Burst) jitter(d time.Duration) time.Duration {
+	if b.Jitter == 0 {
+		return d
+	}
+
+	return d + time.Duration(rand.Int64N(int64(2*b.Jitter)+1)) - b.Jitter
+}
+
+// bursts is a run's Burst as it goes: the off periods' hold on the workers,
+// which is a pause of its own beside Pause's, and the time spent on so far.
+type bursts struct {
+	Burst
+
+	// off holds the workers through an off period. Its time is not the run's
+	// paused time: that is left out of every rate, and an off period is what
+	// the overall rate is over.
+	off pause
+
+	mu      sync.Mutex
+	periods int           // the on periods begun
+	on      time.Duration // the on periods that have ended, less any time paused in them
+
+	// onSince is when the on period under way began, and zero during an off
+	// period; pausedThen is how long the run had been paused by then.
+	onSince    time.Time
+	pausedThen time.Duration
+}
+
+// newBursts is the cycle of b, or nil for a steady run; a nil bursts holds
+// nobody.
+func newBursts(b Burst) *bursts {
+	if b == (Burst{}) {
+		return nil
+	}
+
+	return &bursts{Burst: b}
+}
+
+// hold is where the workers wait out an off period; a nil one, for a steady run,
+// is a pause nobody begins.
+func (b *bursts) hold() *pause {
+	if b == nil {
+		return &pause{}
+	}
+
+	return &b.off
+}
+
+// cycle turns the run off and on until its context is done, starting with the
+// on period that began at r.began, and tells the Observer of every turn. Each
+// turn is due at r.began plus every period before it, as a Shape's segments
+// are, rather than a period after the last turn happened: a timer that fires
+// late delays that turn alone, instead of every one after it.
+func (b *bursts) cycle(r *Run) {
+	b.turnOn(r.began, &r.paused)
+
+	at := r.began
+
+	for on := true; ; on = !on {
+		length := b.jitter(b.Off)
+		if on {
+			length = b.jitter(b.On)
+		}
+
+		at = at.Add(length)
+		timer := time.NewTimer(time.Until(at))
+
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		// Turned under told, like Pause, so the turn and the call that says so
+		// are one step to anybody watching, and none comes after the shutdown.
+		r.told.Lock()
+
+		if r.ctx.Err() != nil {
+			r.told.Unlock()
+
+			return
+		}
+
+		now := time.Now()
+
+		if on {
+			b.turnOff(now, &r.paused)
+		} else {
+			b.off.end(now)
+			b.turnOn(now, &r.paused)
+		}
+
+		ev := BurstEvent{On: !on, Elapsed: now.Sub(r.began)}
+		r.tell(func(obs Observer) {
+			if o, ok := obs.(BurstObserver); ok {
+				o.OnBurst(ev)
+			}
+		})
+
+		r.told.Unlock()
+	}
+}
+
+// turnOn begins an on period at now.
+func (b *bursts) turnOn(now time.Time, p *pause) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.periods++
+	b.onSince, b.pausedThen = now, p.paused(now)
+}
+
+// turnOff ends the on period under way at now, and holds the workers.
+func (b *bursts) turnOff(now time.Time, p *pause) {
+	b.mu.Lock()
+	b.on += b.onFor(now, p)
+	b.onSince = time.Time{}
+	b.mu.Unlock()
+
+	b.off.begin(now)
+}
+
+// onFor is how long the on period under way has run by now, less any time the
+// run was paused in it, or 0 during an off period. The caller holds mu.
+func (b *bursts) onFor(now time.Time, p *pause) time.Duration {
+	if b.onSince.IsZero() {
+		return 0
+	}
+
+	return now.Sub(b.onSince) - (p.paused(now) - b.pausedThen)
+}
+
+// read is what the cycle has seen as of now. An on period a shutdown came in
+// runs on through the drain, which is work done on.
+func (b *bursts) read(r *Result, now time.Time, p *pause) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	on := b.on + b.onFor(now, p)
+
+	r.Burst = &BurstStats{Burst: b.Burst, Periods: b.periods, OnTime: on, OnRate: rate(r.Count, on)}
+}
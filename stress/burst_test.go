@@ -0,0 +1,94 @@
+package stress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestBurstTurnsOffAndOn runs two cycles and a half of a burst: every turn is
+// told to the Observer, off first, and the rate while on is the rate of a run
+// that was on about half the time, twice the overall one.
+func TestBurstTurnsOffAndOn(t *testing.T) {
+	obs := &recorder{}
+
+	cfg := Cfg{
+		Workers: 1, Timeout: 450 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"},
+		Burst: Burst{On: 100 * time.Millisecond, Off: 100 * time.Millisecond}, Observer: obs,
+	}
+
+	r, err := cfg.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if len(obs.bursts) != 4 {
+		t.Fatalf("OnBurst() was called %d times, want 4: %v", len(obs.bursts), obs.calls)
+	}
+
+	for i, ev := range obs.bursts {
+		if on := i%2 == 1; ev.On != on {
+			t.Errorf("turn %d On = %t, want %t", i, ev.On, on)
+		}
+
+		if at := time.Duration(i+1) * 100 * time.Millisecond; ev.Elapsed < at {
+			t.Errorf("turn %d at %s elapsed, want %s or later", i, ev.Elapsed, at)
+		}
+	}
+
+	b := r.Burst
+	if b == nil {
+		t.Fatal("Burst = nil on a run with one, want its on periods")
+	}
+
+	// 250ms on and 200ms off, give or take the turns' timers firing late. A
+	// late turn off lengthens the on period before it and shortens the off
+	// period after, and on a busy machine a timer can be a scheduler tick or
+	// two late, twice over in a run of four turns.
+	const slack = 50 * time.Millisecond
+
+	if b.Burst != cfg.Burst || b.Periods != 3 || b.OnTime < 250*time.Millisecond-slack || b.OnTime > r.Elapsed-200*time.Millisecond+slack {
+		t.Errorf("Burst = %+v over %s, want 3 periods of about 100ms on", *b, r.Elapsed)
+	}
+
+	if b.OnRate < 1.3*r.Rate {
+		t.Errorf("OnRate = %.0f against a Rate of %.0f, want the off periods left out of it", b.OnRate, r.Rate)
+	}
+}
+
+// TestSteadyRunHasNoBurst: a run without a Burst reports none.
+func TestSteadyRunHasNoBurst(t *testing.T) {
+	r, err := Cfg{Workers: 1, Timeout: 10 * time.Millisecond, Stressor: "contention"}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Burst != nil {
+		t.Errorf("Burst = %+v, want nil", *r.Burst)
+	}
+}
+
+// TestJitterStaysInBounds: a jittered period is never off by more than Jitter.
+func TestJitterStaysInBounds(t *testing.T) {
+	b := Burst{On: 10 * time.Second, Off: 50 * time.Second, Jitter: 5 * time.Second}
+
+	var shorter, longer bool
+
+	for range 1000 {
+		d := b.jitter(b.On)
+
+		if d < 5*time.Second || d > 15*time.Second {
+			t.Fatalf("jitter(%s) = %s, want within %s of it", b.On, d, b.Jitter)
+		}
+
+		shorter, longer = shorter || d < b.On, longer || d > b.On
+	}
+
+	if !shorter || !longer {
+		t.Errorf("a thousand jittered periods were shorter %t and longer %t, want both", shorter, longer)
+	}
+
+	if d := (Burst{On: time.Second, Off: time.Second}).jitter(time.Second); d != time.Second {
+		t.Errorf("jitter() with no Jitter = %s, want the period as it was", d)
+	}
+}
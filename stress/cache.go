@@ -0,0 +1,107 @@
+package stress
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+)
+
+// nodeSize is the span of one step of a cache ring: a line, on most parts.
+// Unlike the contention counters it is not padded to 128, because the order of
+// the walk is random and an adjacent-line prefetch fetches nothing it uses.
+const nodeSize = 64
+
+// cacheBatch is how many steps of the ring one cache unit takes: about 0.4ms at
+// the ~100ns a step costs in DRAM, so a worker on the largest working set
+// notices the end of a phase as soon as one on the smallest does.
+const cacheBatch = 1 << 12
+
+// minWorkingSet is two nodes, which is the smallest ring there is.
+const minWorkingSet = 2 * nodeSize
+
+// maxWorkingSet is as many nodes as a node's uint32 next can count, which no
+// machine this runs on has the memory for; it is there so a typo fails
+// validation rather than an allocation. A variable, and narrowed to an int,
+// only so a 32-bit build compiles.
+var maxWorkingSet = int(min(uint64(math.MaxInt), (math.MaxUint32+1)*nodeSize))
+
+// cacheTiers is what an empty WorkingSets walks, which is the command's
+// `--working-set auto`: sizes that sit well inside L1, L2 and L3 and well past
+// all three on common parts. Fixed rather than read off the machine, for #104's
+// reason — the same command line has to be the same test on a laptop and in a
+// pod — and so which level each one lands in on a given part is for the
+// operator to know, and the latencies to show.
+var cacheTiers = []int{16 << 10, 512 << 10, 8 << 20, 128 << 20}
+
+// cacheStressor walks a ring of pointers laid out in random order, where bcrypt
+// works in the 4KiB of its own S-boxes and never leaves L1. Each step depends on
+// the load before it, so nothing can be prefetched or overlapped and the time a
+// step takes is the latency of whichever level the working set fits in. All the
+// workers walk the same ring, from different places: reads share a line without
+// fighting over it, and the memory a run takes is the working set once, not
+// once per worker.
+var cacheStressor = &stressor{
+	name:    "cache",
+	label:   "cache",
+	unit:    "access",
+	units:   "accesses",
+	step:    "walk",
+	kind:    "working set",
+	sized:   cacheVariant,
+	latency: true,
+}
+
+// cacheNode is one step of the ring: the index of the next, alone on its line.
+type cacheNode struct {
+	next uint32
+	_    [nodeSize - 4]byte
+}
+
+// cacheVariant is the variant that walks a ring of size bytes.
+func cacheVariant(size int) variant {
+	return variant{
+		name: units.FormatSize(size),
+		start: func(workers int) func(int) uint64 {
+			ring := newRing(size / nodeSize)
+
+			// Spread out, so no two workers walk in lockstep on the same lines.
+			// Each worker touches its own entry only, once a batch.
+			at := make([]uint32, workers)
+			for id := range at {
+				at[id] = uint32(uint64(id) * uint64(len(ring)) / uint64(workers))
+			}
+
+			return func(id int) uint64 {
+				p := at[id]
+				for range cacheBatch {
+					p = ring[p].next
+				}
+
+				at[id] = p
+
+				return cacheBatch
+			}
+		},
+	}
+}
+
+// newRing lays n nodes out as a single cycle in random order: Sattolo's variant
+// of the Fisher-Yates shuffle, which is what makes it one cycle through every
+// node rather than several short ones a walk could stay inside. Seeded, so two
+// runs of a working set walk the same ring.
+func newRing(n int) []cacheNode {
+	ring := make([]cacheNode, n)
+	for i := range ring {
+		ring[i].next = uint32(i)
+	}
+
+	rng := rand.New(rand.NewPCG(0, 0))
+
+	for i := n - 1; i > 0; i-- {
+		j := rng.IntN(i)
+		ring[i].next, ring[j].next = ring[j].next, ring[i].next
+	}
+
+	return ring
+}
@@ -0,0 +1,76 @@
+package stress
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// TestNewRingIsOneCycle is what Sattolo's shuffle is for: a walk from any node
+// has to visit every node before it comes back, or a working set of 64MiB walks
+// a cycle of a few lines and measures L1.
+func TestNewRingIsOneCycle(t *testing.T) {
+	for _, n := range []int{2, 3, 16, 1000} {
+		ring := newRing(n)
+
+		seen := make([]bool, n)
+		p := uint32(0)
+
+		for step := range n {
+			if seen[p] {
+				t.Fatalf("newRing(%d) came back to node %d after %d steps, want one cycle through all %d", n, p, step, n)
+			}
+
+			seen[p] = true
+			p = ring[p].next
+		}
+
+		if p != 0 {
+			t.Errorf("newRing(%d) ended %d steps on at node %d, want back at 0", n, n, p)
+		}
+	}
+}
+
+// TestCacheNodeIsALine: two nodes on a line would let one miss bring in the
+// next step, and the smaller tiers would look faster than their level is.
+func TestCacheNodeIsALine(t *testing.T) {
+	if got := unsafe.Sizeof(cacheNode{}); got != nodeSize {
+		t.Errorf("a cache node is %d bytes, want the %d-byte line it stands for", got, nodeSize)
+	}
+}
+
+// TestCacheUnitWalksItsBatch holds the unit to the count it reports, from every
+// starting place the workers are spread to.
+func TestCacheUnitWalksItsBatch(t *testing.T) {
+	const workers = 4
+
+	unit := cacheVariant(minWorkingSet * 8).start(workers)
+
+	for id := range workers {
+		if got := unit(id); got != cacheBatch {
+			t.Errorf("cache unit for worker %d = %d, want %d", id, got, cacheBatch)
+		}
+	}
+}
+
+// TestRunContextReportsEveryWorkingSet runs two working sets: a variant each,
+// named as they were given and each with a turn.
+func TestRunContextReportsEveryWorkingSet(t *testing.T) {
+	r, err := Cfg{Workers: 1, Timeout: 100 * time.Millisecond, Stressor: "cache", WorkingSets: []int{32 << 10, 1 << 20}}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	variants := r.Groups[0].Variants
+
+	if len(variants) != 2 || variants[0].Name != "32KiB" || variants[1].Name != "1MiB" {
+		t.Fatalf("Variants = %+v, want 32KiB and 1MiB", variants)
+	}
+
+	for _, v := range variants {
+		if v.Count == 0 || v.Spent <= 0 || v.Rate <= 0 {
+			t.Errorf("working set %s = %+v, want it to have walked in its phase", v.Name, v)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package stress
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CgroupStats is what the kernel did over a run to hold the process's cgroup to
+// its CPU quota: a cgroup out of quota has every thread in it held until the
+// next period, which a rate shows as a slow machine and only cpu.stat shows as
+// a limit. Periods is the quota periods the cgroup had runnable threads in, and
+// Throttled how many of those it ran out of quota in, for ThrottledTime in all.
+// A cgroup with no quota has no periods.
+type CgroupStats struct {
+	Version       int // 2 for the unified hierarchy, 1 for the cpu controller's
+	Periods       uint64
+	Throttled     uint64
+	ThrottledTime time.Duration
+}
+
+// cgroupProbe reads the cpu.stat of the process's cgroup at the start of a run
+// and as it goes. It is every run's where there is one to read.
+type cgroupProbe struct {
+	path    string
+	version int
+
+	// then is the cpu.stat at start, and end the one at stop, which is what
+	// read reports from once stopped is set.
+	mu        sync.Mutex
+	then, end CgroupStats
+	stopped   bool
+}
+
+// newCgroupProbe is the probe of the cgroup the process is in, as /proc under
+// root says, or nil where it is in none this can read a cpu.stat of.
+func newCgroupProbe(root string) probe {
+	path, version := cgroupCPUStat(root)
+	if path == "" {
+		return nil
+	}
+
+	if _, ok := readCPUStat(path, version); !ok {
+		return nil
+	}
+
+	return &cgroupProbe{path: path, version: version}
+}
+
+func (p *cgroupProbe) start() { p.then, _ = readCPUStat(p.path, p.version) }
+
+func (p *cgroupProbe) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end, _ = readCPUStat(p.path, p.version)
+	p.stopped = true
+}
+
+func (p *cgroupProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now, ok := p.end, true
+	if !p.stopped {
+		now, ok = readCPUStat(p.path, p.version)
+	}
+
+	// A cgroup removed under the run, or counters that went back, which a
+	// cgroup made again in its place would have: no figures, rather than
+	// wrong ones.
+	if !ok || now.Periods < p.then.Periods || now.Throttled < p.then.Throttled {
+		return
+	}
+
+	r.Cgroup = &CgroupStats{
+		Version:       p.version,
+		Periods:       now.Periods - p.then.Periods,
+		Throttled:     now.Throttled - p.then.Throttled,
+		ThrottledTime: now.ThrottledTime - p.then.ThrottledTime,
+	}
+}
+
+// cgroupCPUStat finds the cpu.stat of the cgroup the process is in, under root,
+// and the version of the hierarchy it is in, as cgroupFile does.
+func cgroupCPUStat(root string) (string, int) {
+	return cgroupFile(root, "cpu", "cpu.stat", "cpu.stat")
+}
+
+// cgroupFile finds a file of the cgroup the process is in, under root, and the
+// version of the hierarchy it is in: v1's file in the controller's hierarchy
+// where /proc/self/cgroup names one, which on a hybrid machine is where the
+// limits are kept, and otherwise v2's in the unified hierarchy. It is "" where
+// there is neither.
+//
+// The cgroup's own directory comes first, and the mount's top after it: a
+// container without a cgroup namespace of its own is told the host's path to
+// its cgroup, while /sys/fs/cgroup in it is the cgroup itself.
+func cgroupFile(root, controller, v1, v2 string) (string, int) {
+	f, err := os.Open(filepath.Join(root, "proc/self/cgroup"))
+	if err != nil {
+		return "", 0
+	}
+	defer f.Close()
+
+	var (
+		unified    string
+		hasUnified bool
+		tried      []string
+	)
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(sc.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		if fields[0] == "0" && fields[1] == "" {
+			unified, hasUnified = fields[2], true
+
+			continue
+		}
+
+		if !slices.Contains(strings.Split(fields[1], ","), controller) {
+			continue
+		}
+
+		// Mounted under the controllers' joined name, "cpu,cpuacct", and
+		// most often linked from the controller's own as well.
+		for _, name := range []string{fields[1], controller} {
+			mount := filepath.Join(root, "sys/fs/cgroup", name)
+			tried = append(tried, filepath.Join(mount, fields[2]), mount)
+		}
+	}
+
+	if path := firstFile(tried, v1); path != "" {
+		return path, 1
+	}
+
+	if !hasUnified {
+		return "", 0
+	}
+
+	mount := filepath.Join(root, "sys/fs/cgroup")
+	if path := firstFile([]string{filepath.Join(mount, unified), mount}, v2); path != "" {
+		return path, 2
+	}
+
+	return "", 0
+}
+
+// firstFile is the file called name in the first of dirs that has one, or "".
+func firstFile(dirs []string, name string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// readCPUStat reads a cpu.stat of the version given: v2 keeps the throttled
+// time in microseconds and v1 in nanoseconds. A cpu.stat without the quota's
+// counters, which v2's is where the cpu controller is not enabled for the
+// cgroup, is not one to read.
+func readCPUStat(path string, version int) (CgroupStats, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return CgroupStats{}, false
+	}
+	defer f.Close()
+
+	var (
+		s     CgroupStats
+		found int
+	)
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), " ")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case key == "nr_periods":
+			s.Periods = n
+		case key == "nr_throttled":
+			s.Throttled = n
+		case key == "throttled_usec" && version == 2:
+			s.ThrottledTime = time.Duration(n) * time.Microsecond
+		case key == "throttled_time" && version == 1:
+			s.ThrottledTime = time.Duration(n)
+		default:
+			continue
+		}
+
+		found++
+	}
+
+	return s, sc.Err() == nil && found == 3
+}
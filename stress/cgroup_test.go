@@ -0,0 +1,239 @@
+package stress
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTree makes files under root, each path to its contents, as /proc and
+// /sys would have them.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for path, contents := range files {
+		path = filepath.Join(root, path)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const (
+	cpuStatV2 = "usage_usec 9000000\nuser_usec 8000000\nsystem_usec 1000000\nnr_periods 100\nnr_throttled 20\nthrottled_usec 1500000\nnr_bursts 0\nburst_usec 0\n"
+	cpuStatV1 = "nr_periods 100\nnr_throttled 20\nthrottled_time 1500000000\n"
+)
+
+func TestCgroupCPUStat(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		// want is the cpu.stat found, under the root, and "" for none.
+		want    string
+		version int
+	}{
+		{
+			name: "v2, in a namespace of its own",
+			files: map[string]string{
+				"proc/self/cgroup":       "0::/\n",
+				"sys/fs/cgroup/cpu.stat": cpuStatV2,
+			},
+			want: "sys/fs/cgroup/cpu.stat", version: 2,
+		},
+		{
+			name: "v2, told the host's path",
+			files: map[string]string{
+				"proc/self/cgroup": "0::/kubepods.slice/pod1/cri-abc\n",
+				"sys/fs/cgroup/kubepods.slice/pod1/cri-abc/cpu.stat": cpuStatV2,
+				"sys/fs/cgroup/cpu.stat":                             cpuStatV2,
+			},
+			want: "sys/fs/cgroup/kubepods.slice/pod1/cri-abc/cpu.stat", version: 2,
+		},
+		{
+			name: "v2, the path not mounted in the container",
+			files: map[string]string{
+				"proc/self/cgroup":       "0::/system.slice/docker-abc.scope\n",
+				"sys/fs/cgroup/cpu.stat": cpuStatV2,
+			},
+			want: "sys/fs/cgroup/cpu.stat", version: 2,
+		},
+		{
+			name: "v1, co-mounted",
+			files: map[string]string{
+				"proc/self/cgroup": "4:memory:/docker/abc\n3:cpu,cpuacct:/docker/abc\n0::/\n",
+				"sys/fs/cgroup/cpu,cpuacct/docker/abc/cpu.stat": cpuStatV1,
+			},
+			want: "sys/fs/cgroup/cpu,cpuacct/docker/abc/cpu.stat", version: 1,
+		},
+		{
+			name: "v1, on its own, at the mount's top",
+			files: map[string]string{
+				"proc/self/cgroup":           "2:cpuacct:/\n1:cpu:/docker/abc\n",
+				"sys/fs/cgroup/cpu/cpu.stat": cpuStatV1,
+			},
+			want: "sys/fs/cgroup/cpu/cpu.stat", version: 1,
+		},
+		{
+			name:  "no cpu.stat",
+			files: map[string]string{"proc/self/cgroup": "0::/\n"},
+		},
+		{
+			name: "no cgroup",
+			files: map[string]string{
+				"sys/fs/cgroup/cpu.stat": cpuStatV2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, tt.files)
+
+			got, version := cgroupCPUStat(root)
+
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(root, tt.want)
+			}
+
+			if got != want || version != tt.version {
+				t.Errorf("cgroupCPUStat() = %q, %d; want %q, %d", got, version, want, tt.version)
+			}
+		})
+	}
+}
+
+func TestReadCPUStat(t *testing.T) {
+	want := CgroupStats{Periods: 100, Throttled: 20, ThrottledTime: 1500 * time.Millisecond}
+
+	tests := []struct {
+		name    string
+		stat    string
+		version int
+		ok      bool
+	}{
+		{name: "v2", stat: cpuStatV2, version: 2, ok: true},
+		{name: "v1", stat: cpuStatV1, version: 1, ok: true},
+		{name: "v2, without the cpu controller", stat: "usage_usec 9000000\nuser_usec 8000000\nsystem_usec 1000000\n", version: 2},
+		{name: "v1's, read as v2's", stat: cpuStatV1, version: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, map[string]string{"cpu.stat": tt.stat})
+
+			got, ok := readCPUStat(filepath.Join(root, "cpu.stat"), tt.version)
+			if ok != tt.ok || ok && got != want {
+				t.Errorf("readCPUStat() = %+v, %t; want %+v, %t", got, ok, want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestCgroupProbeRead covers the figures a run reports: the difference between
+// the cpu.stat at either end, the one at stop however it changes after, and
+// none for counters that went back.
+func TestCgroupProbeRead(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/self/cgroup":       "0::/\n",
+		"sys/fs/cgroup/cpu.stat": cpuStatV2,
+	})
+
+	p := newCgroupProbe(root)
+	if p == nil {
+		t.Fatal("newCgroupProbe() = nil, want the probe of the tree's cgroup")
+	}
+
+	p.start()
+
+	writeTree(t, root, map[string]string{"sys/fs/cgroup/cpu.stat": "nr_periods 130\nnr_throttled 32\nthrottled_usec 2250000\n"})
+
+	var r Result
+
+	p.read(&r)
+
+	want := CgroupStats{Version: 2, Periods: 30, Throttled: 12, ThrottledTime: 750 * time.Millisecond}
+	if r.Cgroup == nil || *r.Cgroup != want {
+		t.Errorf("read() = %+v, want %+v", r.Cgroup, want)
+	}
+
+	p.stop()
+
+	writeTree(t, root, map[string]string{"sys/fs/cgroup/cpu.stat": "nr_periods 5\nnr_throttled 1\nthrottled_usec 1000\n"})
+
+	r = Result{}
+	if p.read(&r); r.Cgroup == nil || *r.Cgroup != want {
+		t.Errorf("read() after stop = %+v, want the %+v it stopped at", r.Cgroup, want)
+	}
+
+	// A cgroup made again under the run counts from 0.
+	q := newCgroupProbe(root)
+	q.start()
+
+	writeTree(t, root, map[string]string{"sys/fs/cgroup/cpu.stat": "nr_periods 2\nnr_throttled 0\nthrottled_usec 0\n"})
+
+	r = Result{}
+	if q.read(&r); r.Cgroup != nil {
+		t.Errorf("read() of counters that went back = %+v, want nil", r.Cgroup)
+	}
+}
+
+// TestRunReadsItsRoot runs against a tree of files in place of the machine's,
+// and gets the figures the tree has: none of the machine's own.
+func TestRunReadsItsRoot(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/self/cgroup":                     "0::/\n",
+		"sys/fs/cgroup/cpu.stat":               cpuStatV2,
+		"proc/pressure/cpu":                    "some avg10=1.50 avg60=0.80 avg300=0.20 total=2500000\n",
+		"sys/class/thermal/thermal_zone0/temp": "45000\n",
+		"proc/sys/kernel/osrelease":            "6.1.0-18-amd64\n",
+	})
+	writeTree(t, root, raplPackage("intel-rapl:0", "1000\n"))
+
+	r, err := Cfg{Workers: 1, Timeout: 50 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, Root: root}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if want := (CgroupStats{Version: 2}); r.Cgroup == nil || *r.Cgroup != want {
+		t.Errorf("Cgroup = %+v, want %+v", r.Cgroup, want)
+	}
+
+	if want := (PressureStats{Avg10: 1.5, Avg60: 0.8, Avg300: 0.2}); r.Pressure == nil || *r.Pressure != want {
+		t.Errorf("Pressure = %+v, want %+v", r.Pressure, want)
+	}
+
+	// Sampled at the start and the end, with no Observer to read between.
+	if want := (ThermalStats{Samples: 2, Zones: 1, Temp: 45, NowTemp: 45}); r.Thermal == nil || *r.Thermal != want {
+		t.Errorf("Thermal = %+v, want %+v", r.Thermal, want)
+	}
+
+	if r.Host.Kernel != "6.1.0-18-amd64" {
+		t.Errorf("Host.Kernel = %q, want the tree's 6.1.0-18-amd64", r.Host.Kernel)
+	}
+
+	// The counter stood still.
+	if r.Energy == nil || r.Energy.Zones != 1 || r.Energy.Joules != 0 {
+		t.Errorf("Energy = %+v, want a zone and 0J", r.Energy)
+	}
+
+	r, err = Cfg{Workers: 1, Timeout: 50 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, Root: t.TempDir()}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Cgroup != nil || r.Pressure != nil || r.Steal != nil || r.Thermal != nil || r.Energy != nil {
+		t.Errorf("an empty root's Cgroup, Pressure, Steal, Thermal, Energy = %+v, %+v, %+v, %+v, %+v; want nil for each", r.Cgroup, r.Pressure, r.Steal, r.Thermal, r.Energy)
+	}
+}
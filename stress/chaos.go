@@ -0,0 +1,78 @@
+package stress
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// maxChaosSegments bounds how many changes a Chaos may draw over a run, which
+// is how many segments it holds and reports: a year at every=1ms is billions of
+// them, and no summary anybody reads.
+const maxChaosSegments = 1 << 20
+
+// Chaos is a load nobody planned and anybody can have again: every so often,
+// at random between MinEvery and MaxEvery, the number of workers that go on
+// changes to one between MinWorkers and MaxWorkers and the duty they keep to
+// one between MinDuty and MaxDuty, all drawn from Seed. Two runs of the same
+// Chaos and Timeout change the same way at the same times, however different
+// the loads they make of it. The zero Chaos is a run without one.
+//
+// The changes are a Shape the run draws at the start and follows as it would
+// a trace's, so the Result's Shape has them, and the Timeout is their length.
+type Chaos struct {
+	MinWorkers, MaxWorkers int
+	MinDuty, MaxDuty       float64
+	MinEvery, MaxEvery     time.Duration
+	Seed                   uint64
+}
+
+// validate reports whether ch is a Chaos a run of workers workers can keep
+// for timeout, or the zero Chaos.
+func (ch Chaos) validate(workers int, timeout time.Duration) error {
+	if ch == (Chaos{}) {
+		return nil
+	}
+
+	switch {
+	case ch.MinWorkers < 0 || ch.MaxWorkers < ch.MinWorkers || ch.MaxWorkers > workers:
+		return fmt.Errorf("chaos workers must be from 0 to the run's %d, the fewer first", workers)
+	// Written to turn NaN away too.
+	case !(ch.MinDuty >= 0 && ch.MaxDuty >= ch.MinDuty && ch.MaxDuty <= 1):
+		return fmt.Errorf("chaos duty must be from 0 to 1, the lower first")
+	case ch.MinEvery <= 0 || ch.MaxEvery < ch.MinEvery:
+		return fmt.Errorf("chaos every must be greater than 0, the shorter first")
+	// A run with no end would need changes drawn without end, and has no
+	// length to replay.
+	case timeout == 0:
+		return fmt.Errorf("chaos needs a timeout, for the changes it draws to have an end")
+	case timeout/ch.MinEvery >= maxChaosSegments:
+		return fmt.Errorf("chaos every %s would change the load up to %d times in timeout %s; give a longer every or a shorter timeout", ch.MinEvery, timeout/ch.MinEvery, timeout)
+	}
+
+	return nil
+}
+
+// segments draws the changes for a run of length, the last cut short where it
+// would outlast it.
+func (ch Chaos) segments(length time.Duration) []Segment {
+	// PCG, rather than the package's source, so the draws are Seed's alone.
+	rng := rand.New(rand.NewPCG(ch.Seed, ch.Seed))
+
+	var shape []Segment
+
+	for at := time.Duration(0); at < length; {
+		s := Segment{
+			Length:  ch.MinEvery + time.Duration(rng.Int64N(int64(ch.MaxEvery-ch.MinEvery)+1)),
+			Workers: ch.MinWorkers + rng.IntN(ch.MaxWorkers-ch.MinWorkers+1),
+			Duty:    ch.MinDuty + rng.Float64()*(ch.MaxDuty-ch.MinDuty),
+		}
+
+		s.Length = min(s.Length, length-at)
+		at += s.Length
+
+		shape = append(shape, s)
+	}
+
+	return shape
+}
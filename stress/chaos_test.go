@@ -0,0 +1,76 @@
+package stress
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChaosDrawsFromItsSeed(t *testing.T) {
+	ch := Chaos{MinWorkers: 1, MaxWorkers: 4, MinDuty: 0.2, MaxDuty: 0.9, MinEvery: 5 * time.Second, MaxEvery: 30 * time.Second, Seed: 42}
+	const length = time.Hour
+
+	got := ch.segments(length)
+
+	// The same Seed draws the same changes, which is the point of it.
+	if again := ch.segments(length); !slices.Equal(got, again) {
+		t.Fatalf("segments() drew %v, then %v; want the same from one seed", got, again)
+	}
+
+	other := ch
+	other.Seed = 43
+
+	if slices.Equal(got, other.segments(length)) {
+		t.Errorf("segments() drew the same from seeds 42 and 43, want them to differ")
+	}
+
+	var total time.Duration
+
+	for i, s := range got {
+		total += s.Length
+
+		last := i == len(got)-1
+		switch {
+		case s.Workers < ch.MinWorkers || s.Workers > ch.MaxWorkers:
+			t.Errorf("segment %d = %+v, want from %d to %d workers", i, s, ch.MinWorkers, ch.MaxWorkers)
+		case s.Duty < ch.MinDuty || s.Duty > ch.MaxDuty:
+			t.Errorf("segment %d = %+v, want a duty from %g to %g", i, s, ch.MinDuty, ch.MaxDuty)
+		// The last is cut short where it would outlast the run.
+		case s.Length > ch.MaxEvery, !last && s.Length < ch.MinEvery, s.Length <= 0:
+			t.Errorf("segment %d = %+v, want from %s to %s long", i, s, ch.MinEvery, ch.MaxEvery)
+		}
+	}
+
+	if total != length {
+		t.Errorf("segments() last %s, want the run's %s", total, length)
+	}
+}
+
+// TestRunInChaos runs a Chaos from end to end: the run follows the changes its
+// Seed draws, tells the Observer of every one, and ends with its Timeout.
+func TestRunInChaos(t *testing.T) {
+	obs := &recorder{}
+
+	cfg := Cfg{
+		Workers: 2, Timeout: 300 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, Observer: obs,
+		Chaos: Chaos{MaxWorkers: 2, MaxDuty: 1, MinEvery: 50 * time.Millisecond, MaxEvery: 100 * time.Millisecond, Seed: 7},
+	}
+
+	r, err := cfg.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	want := cfg.Chaos.segments(cfg.Timeout)
+
+	if r.Reason != StopTimeout || len(obs.segments) != len(want) || len(r.Shape) != len(want) {
+		t.Fatalf("Reason %s, %d segment events and %d segments, want %s and %d of each", r.Reason, len(obs.segments), len(r.Shape), StopTimeout, len(want))
+	}
+
+	for i, ev := range obs.segments {
+		if ev.Index != i || ev.Segments != len(want) || ev.Segment != want[i] || r.Shape[i].Segment != want[i] {
+			t.Errorf("segment %d = %+v, %+v; want %+v", i, ev, r.Shape[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package stress
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// contentionBatch is how many operations one contention unit performs. One at
+// a time, the worker loop around an increment would cost more than the
+// increment and the rate would measure the loop. A batch is a fraction of a
+// millisecond uncontended and a few milliseconds at worst, with every worker
+// queued on the one mutex or the race detector on every access, so a worker
+// still notices the end of a run or a phase well before a bcrypt worker would.
+const contentionBatch = 1 << 12
+
+// cacheLine is the span padded counters are kept apart by. 128 rather than the
+// 64 a line holds on most x86 parts, because those fetch lines in adjacent
+// pairs and Apple's cores use 128-byte lines: padding to 64 would leave
+// neighbours sharing on exactly the machines this is run on to compare.
+const cacheLine = 128
+
+// contentionStressor makes the workers fight over shared memory, where bcrypt
+// workers share nothing and every core runs out of its own cache. Each mode is
+// a different shape of that fight, and the gap between two modes' rates is the
+// cost of the cross-core traffic one has and the other does not — between
+// padded and unpadded, that is false sharing and nothing else.
+var contentionStressor = &stressor{
+	name:  "contention",
+	label: "contention",
+	unit:  "op",
+	units: "ops",
+	step:  "batch",
+	kind:  "mode",
+	variants: []variant{
+		{name: "atomic", start: startAtomic},
+		{name: "unpadded", start: startUnpadded},
+		{name: "padded", start: startPadded},
+		{name: "mutex", start: startMutex},
+	},
+}
+
+// startAtomic has every worker increment one shared counter: the line it lives
+// on moves from core to core on every operation.
+func startAtomic(int) func(int) uint64 {
+	var shared atomic.Uint64
+
+	return func(int) uint64 {
+		for range contentionBatch {
+			shared.Add(1)
+		}
+
+		return contentionBatch
+	}
+}
+
+// startUnpadded gives every worker a counter of its own, packed side by side:
+// no two workers touch the same word, and sixteen of them still share a line.
+func startUnpadded(workers int) func(int) uint64 {
+	counters := make([]atomic.Uint64, workers)
+
+	return func(id int) uint64 {
+		for range contentionBatch {
+			counters[id].Add(1)
+		}
+
+		return contentionBatch
+	}
+}
+
+// paddedCounter is a counter alone on its cache line.
+type paddedCounter struct {
+	atomic.Uint64
+	_ [cacheLine - 8]byte
+}
+
+// startPadded is startUnpadded with each counter on a line of its own, so the
+// workers share nothing and the rate is what the increments cost uncontended.
+func startPadded(workers int) func(int) uint64 {
+	counters := make([]paddedCounter, workers)
+
+	return func(id int) uint64 {
+		for range contentionBatch {
+			counters[id].Add(1)
+		}
+
+		return contentionBatch
+	}
+}
+
+// startMutex has every worker take one sync.Mutex to increment a plain counter,
+// which is the lock contention of a hot shared structure rather than the line
+// traffic alone.
+func startMutex(int) func(int) uint64 {
+	var (
+		mu     sync.Mutex
+		shared uint64
+	)
+
+	return func(int) uint64 {
+		for range contentionBatch {
+			mu.Lock()
+			shared++
+			mu.Unlock()
+		}
+
+		return contentionBatch
+	}
+}
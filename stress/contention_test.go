@@ -0,0 +1,75 @@
+package stress
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// TestContentionUnitsCountTheirBatch holds every mode to the count it reports:
+// a unit says it did contentionBatch operations, and a rate built on a count the
+// unit did not do would compare modes on nothing.
+func TestContentionUnitsCountTheirBatch(t *testing.T) {
+	const workers = 3
+
+	for _, v := range contentionStressor.variants {
+		t.Run(v.name, func(t *testing.T) {
+			unit := v.start(workers)
+
+			for id := range workers {
+				if got := unit(id); got != contentionBatch {
+					t.Errorf("%s unit for worker %d = %d, want %d", v.name, id, got, contentionBatch)
+				}
+			}
+		})
+	}
+}
+
+// TestPaddedCountersShareNoLine is what the padded mode is for: were two of its
+// counters on one line, it would measure false sharing exactly as the unpadded
+// mode does, and the gap between the two would be nothing.
+func TestPaddedCountersShareNoLine(t *testing.T) {
+	if got := unsafe.Sizeof(paddedCounter{}); got < cacheLine {
+		t.Errorf("a padded counter is %d bytes, want at least the %d-byte line it is to have to itself", got, cacheLine)
+	}
+}
+
+// TestRunContextReportsEveryContentionMode runs all four modes, which is where
+// the phases are taken in turn: each has a variant, in order, with work in it.
+//
+// The run is a whole phase per mode. Any shorter and a phase is short enough
+// for the drain at its end to matter: under the race detector the first three
+// can overrun theirs by enough to leave the last with nothing.
+func TestRunContextReportsEveryContentionMode(t *testing.T) {
+	timeout := time.Duration(len(contentionStressor.variants)) * phase
+
+	r, err := Cfg{Workers: 2, Timeout: timeout, Stressor: "contention"}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	g := r.Groups[0]
+
+	var names []string
+	var sum uint64
+
+	for _, v := range g.Variants {
+		names = append(names, v.Name)
+		sum += v.Count
+
+		// A phase each, a whole one apiece.
+		if v.Count == 0 {
+			t.Errorf("mode %s did no work, want every mode to have had its phase", v.Name)
+		}
+	}
+
+	if want := []string{"atomic", "unpadded", "padded", "mutex"}; !slices.Equal(names, want) {
+		t.Errorf("Variants = %q, want %q", names, want)
+	}
+
+	if sum != g.Count {
+		t.Errorf("the modes' counts add up to %d, want the group's %d", sum, g.Count)
+	}
+}
@@ -0,0 +1,89 @@
+package stress
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+)
+
+// errCount is the cause a run's context carries where its Count was handed out,
+// which is how Wait tells that apart from the timeout and the caller.
+var errCount = errors.New("count reached")
+
+// budget is what a run with a Count has left to hand its workers, shared by all
+// of them: each claims a unit before doing it, so no two do the last one, and
+// the run ends once nothing is left and no claim is outstanding. A worker that
+// finds nothing left while another's claim is out is turned away and tries
+// again, since that claim may yet come back unspent; the claim that finishes
+// the budget is the one that ends the run.
+//
+// A claim is one call of a unit, and what it did is taken off once it returns.
+// For a stressor that counts one at a time, bcrypt among them, that is exactly
+// Count units; for one that counts in batches a claim is a whole batch, so the
+// count can run past Count by up to a batch a worker. A call that did nothing,
+// a gc unit waiting on its AllocRate or a batch cut short as a phase ends,
+// gives its claim back.
+type budget struct {
+	// left is what is neither claimed nor done, and out the claims taken and
+	// not yet spent.
+	left, out atomic.Int64
+
+	// stop ends the run, with errCount.
+	stop func()
+}
+
+// newBudget is a budget of count units, or nil for a run with no Count; every
+// method of a nil budget is a run with no such bound.
+func newBudget(count uint64, stop func()) *budget {
+	if count == 0 {
+		return nil
+	}
+
+	b := &budget{stop: stop}
+	b.left.Store(int64(count))
+
+	return b
+}
+
+// claim takes one unit, and reports whether there was one to take.
+func (b *budget) claim() bool {
+	if b == nil {
+		return true
+	}
+
+	// Out before left, so spend never sees nothing left and nothing out
+	// while a claim is being taken.
+	b.out.Add(1)
+
+	if b.left.Add(-1) >= 0 {
+		return true
+	}
+
+	b.left.Add(1)
+	b.settle(b.out.Add(-1))
+
+	// The claim still out is a unit or two from done; nothing is gained by
+	// asking again before the scheduler has run it.
+	runtime.Gosched()
+
+	return false
+}
+
+// spend takes off what a claimed unit did beyond the one its claim took, or
+// gives the claim back where it did nothing.
+func (b *budget) spend(n uint64) {
+	if b == nil {
+		return
+	}
+
+	b.left.Add(1 - int64(n))
+	b.settle(b.out.Add(-1))
+}
+
+// settle stops the run where the budget is through: nothing left, and out,
+// what was just read of the claims outstanding, none.
+func (b *budget) settle(out int64) {
+	if out == 0 && b.left.Load() <= 0 {
+		b.stop()
+	}
+}
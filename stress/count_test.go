@@ -0,0 +1,91 @@
+package stress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestCountEndsTheRun: a run with a Count does that many units between its
+// workers and ends, reporting that it was the Count that ended it.
+func TestCountEndsTheRun(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Cfg
+
+		// slack is how far past Count the run may count: none for a stressor
+		// that counts one at a time, and up to a batch a worker for one that
+		// does not.
+		slack uint64
+	}{
+		// Fewer units than workers, so some of them find the budget empty at
+		// their first claim.
+		{name: "bcrypt", cfg: Cfg{Workers: 4, Count: 3}},
+		{name: "contention", cfg: Cfg{Workers: 2, Count: 1 << 20, Stressor: "contention", Modes: []string{"atomic"}}, slack: 2 * contentionBatch},
+		// The budget goes on across the turns the modes take.
+		{name: "syscall, two modes", cfg: Cfg{Workers: 2, Count: 1 << 16, Stressor: "syscall", Modes: []string{"getpid", "clock_gettime"}}, slack: 2 * cheapBatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.cfg.RunContext(context.Background())
+			if err != nil {
+				t.Fatalf("RunContext() error = %v, want nil", err)
+			}
+
+			if r.Reason != StopCount {
+				t.Errorf("Reason = %s, want %s", r.Reason, StopCount)
+			}
+
+			if r.Count < tt.cfg.Count || r.Count > tt.cfg.Count+tt.slack {
+				t.Errorf("Count = %d, want %d to %d", r.Count, tt.cfg.Count, tt.cfg.Count+tt.slack)
+			}
+		})
+	}
+}
+
+// TestTimeoutCapsACount: a Count the Timeout runs out first on is a run the
+// timeout ended, short of its Count.
+func TestTimeoutCapsACount(t *testing.T) {
+	r, err := Cfg{Workers: 1, Count: 1 << 40, Timeout: 50 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Reason != StopTimeout || r.Count >= 1<<40 {
+		t.Errorf("Reason, Count = %s, %d; want %s short of the count", r.Reason, r.Count, StopTimeout)
+	}
+}
+
+// TestCountGivesBackAnIdleClaim: a unit that did nothing, as a gc unit waiting
+// on its AllocRate does, gives back the claim it took, so the run does the
+// whole of its Count rather than ending short by every idle call.
+func TestCountGivesBackAnIdleClaim(t *testing.T) {
+	saved := stressors
+	t.Cleanup(func() { stressors = saved })
+
+	stressors = append(stressors[:len(stressors):len(stressors)], &stressor{
+		name: "idle", label: "idle", unit: "unit", units: "units", step: "unit",
+		start: func(workers int) func(int) uint64 {
+			calls := make([]int, workers)
+
+			// Every other call, per worker, does nothing.
+			return func(id int) uint64 {
+				calls[id]++
+
+				return uint64(calls[id] % 2)
+			}
+		},
+	})
+
+	cfg := Cfg{Workers: 3, Count: 2560, Stressor: "idle"}
+
+	r, err := cfg.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Reason != StopCount || r.Count != cfg.Count {
+		t.Errorf("Reason, Count = %s, %d; want %s, %d", r.Reason, r.Count, StopCount, cfg.Count)
+	}
+}
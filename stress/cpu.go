@@ -0,0 +1,80 @@
+package stress
+
+import (
+	"sync"
+	"time"
+)
+
+// CPUStats is the CPU time the process was given over a run, which is the
+// figure a wall-clock rate cannot show: a container held to less than its
+// workers by a CPU quota runs them for the whole run and is given a fraction
+// of it.
+//
+// The time is the whole process's, the collector and the run's own
+// goroutines included, so a run of one worker can come to a little over one
+// core. Cores is User and System over the time the run was not paused, and
+// Efficiency is Cores over the workers, 1 for every worker on a core of its
+// own the whole time.
+type CPUStats struct {
+	User, System time.Duration
+	Cores        float64
+	Efficiency   float64
+}
+
+// cpuProbe reads the process's CPU time at the start and as the run goes. It
+// is every run's where the OS says what that is.
+type cpuProbe struct {
+	workers int
+
+	// then is the time the process had been given by start, and end by
+	// stop, which is zero while the run goes.
+	mu        sync.Mutex
+	then, end cpuTimes
+	stopped   bool
+}
+
+// cpuTimes is what the OS has given the process so far.
+type cpuTimes struct {
+	user, system time.Duration
+}
+
+// newCPUProbe is the probe of a run of workers workers, or nil where this
+// build has no way to read the process's CPU time.
+func newCPUProbe(workers int) probe {
+	if _, ok := processCPU(); !ok {
+		return nil
+	}
+
+	return &cpuProbe{workers: workers}
+}
+
+func (p *cpuProbe) start() { p.then, _ = processCPU() }
+
+// stop holds the figures where the last worker left them, so a Result read
+// after it is not charged the release and the summary.
+func (p *cpuProbe) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end, _ = processCPU()
+	p.stopped = true
+}
+
+func (p *cpuProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.end
+	if !p.stopped {
+		now, _ = processCPU()
+	}
+
+	s := CPUStats{User: now.user - p.then.user, System: now.system - p.then.system}
+
+	if running := r.Elapsed - r.Paused; running > 0 {
+		s.Cores = (s.User + s.System).Seconds() / running.Seconds()
+		s.Efficiency = s.Cores / float64(p.workers)
+	}
+
+	r.CPU = &s
+}
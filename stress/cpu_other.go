@@ -0,0 +1,7 @@
+//go:build !unix && !windows
+
+package stress
+
+// processCPU reports that this build has no way to read the process's CPU
+// time, which a run then leaves out of its Result.
+func processCPU() (cpuTimes, bool) { return cpuTimes{}, false }
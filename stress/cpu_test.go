@@ -0,0 +1,41 @@
+package stress
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestRunReportsItsCPUTime runs two workers flat out and checks the CPU time
+// the Result has against the wall clock: some of it, no more than the machine
+// has cores for, and Cores and Efficiency worked out from it.
+func TestRunReportsItsCPUTime(t *testing.T) {
+	if _, ok := processCPU(); !ok {
+		t.Skipf("%s does not say what CPU time a process was given", runtime.GOOS)
+	}
+
+	r, err := Cfg{Workers: 2, Timeout: 200 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	c := r.CPU
+	if c == nil {
+		t.Fatal("CPU = nil, want the process's CPU time")
+	}
+
+	used := c.User + c.System
+
+	// The workers spin the whole run, so some of it at least; the slack over
+	// the cores is the clocks' granularity.
+	if ceiling := r.Elapsed*time.Duration(runtime.NumCPU()) + 50*time.Millisecond; used <= 0 || used > ceiling {
+		t.Errorf("CPU time = %s over %s, want some and no more than %s", used, r.Elapsed, ceiling)
+	}
+
+	if want := used.Seconds() / (r.Elapsed - r.Paused).Seconds(); math.Abs(c.Cores-want) > 1e-9 || math.Abs(c.Efficiency-want/2) > 1e-9 {
+		t.Errorf("Cores, Efficiency = %g, %g; want %g, %g", c.Cores, c.Efficiency, want, want/2)
+	}
+
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package stress
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPU is getrusage's RUSAGE_SELF: every thread of the process, those
+// that have ended included.
+func processCPU() (cpuTimes, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return cpuTimes{}, false
+	}
+
+	return cpuTimes{
+		user:   time.Duration(ru.Utime.Nano()),
+		system: time.Duration(ru.Stime.Nano()),
+	}, true
+}
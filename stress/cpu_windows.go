@@ -0,0 +1,22 @@
+package stress
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPU is GetProcessTimes' kernel and user times, which Windows keeps in
+// 100ns ticks.
+func processCPU() (cpuTimes, bool) {
+	var creation, exit, kernel, user syscall.Filetime
+
+	if err := syscall.GetProcessTimes(syscall.Handle(^uintptr(0)), &creation, &exit, &kernel, &user); err != nil {
+		return cpuTimes{}, false
+	}
+
+	ticks := func(f syscall.Filetime) time.Duration {
+		return time.Duration(int64(f.HighDateTime)<<32|int64(f.LowDateTime)) * 100
+	}
+
+	return cpuTimes{user: ticks(user), system: ticks(kernel)}, true
+}
@@ -0,0 +1,166 @@
+package stress
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaxTargetRate is the highest TargetRate a run will start on: a unit a
+// nanosecond, past which the schedule's slots are closer together than the
+// clock it is read against can tell apart.
+const MaxTargetRate = 1e9
+
+// dispatcher is TargetRate's: a schedule of slots, one every 1/TargetRate from
+// the start of the run, that the workers take their units from instead of
+// starting the next one the moment the last is done. The schedule runs on the
+// clock whatever the workers manage, which is what makes the run open loop — a
+// node that cannot keep up builds a backlog of slots that have come and not
+// been taken, rather than quietly doing less — and the time between a slot and
+// the start of the unit taken for it is the delay a request arriving then would
+// have waited for a worker.
+//
+// No goroutine issues the slots: a slot is issued by the clock reaching it, and
+// taken by the worker that wins it. A worker with no slot come sleeps until the
+// next one rather than claiming it early, so one told to stop has nothing
+// claimed to give back, and every slot is taken by a worker that was free when
+// it came or is still in the backlog.
+//
+// next is a line every worker writes to, like the budget's, and the histogram is
+// under a lock: a rate is a dispatcher, and a dispatcher is shared.
+type dispatcher struct {
+	interval float64 // between slots, in nanoseconds
+	began    time.Time
+
+	// ended is when the run was told to stop, as UnixNano, and 0 before. The
+	// schedule issues nothing past it: the drain is no time to take a slot
+	// in, and its slots would be a backlog nobody was ever going to work off.
+	ended atomic.Int64
+
+	// next is the first slot not yet taken. Slots from next to the last one
+	// due are the backlog.
+	next atomic.Uint64
+
+	mu         sync.Mutex
+	delays     latencyHistogram
+	maxBacklog uint64
+}
+
+// newDispatcher is a dispatcher issuing rate slots a second, or nil for a run
+// with no TargetRate; a nil dispatcher hands out a slot to every worker that
+// asks, which is a closed-loop run.
+func newDispatcher(rate float64) *dispatcher {
+	if rate == 0 {
+		return nil
+	}
+
+	return &dispatcher{interval: float64(time.Second) / rate}
+}
+
+// start starts the schedule at now, which is the first slot, and ends it when
+// ctx is done.
+func (d *dispatcher) start(ctx context.Context, now time.Time) {
+	if d == nil {
+		return
+	}
+
+	d.began = now
+
+	context.AfterFunc(ctx, func() { d.ended.Store(time.Now().UnixNano()) })
+}
+
+// slot is when slot n comes, for a run paused for paused so far: the time the
+// run was paused is added to every slot, as if the clock had stopped with it.
+func (d *dispatcher) slot(n uint64, paused time.Duration) time.Time {
+	return d.began.Add(paused + time.Duration(float64(n)*d.interval))
+}
+
+// due is how many slots have come by now, or by the end of the schedule where
+// that was first.
+func (d *dispatcher) due(now time.Time, paused time.Duration) uint64 {
+	if ended := d.ended.Load(); ended != 0 && ended < now.UnixNano() {
+		now = time.Unix(0, ended)
+	}
+
+	running := now.Sub(d.began) - paused
+	if running < 0 {
+		return 0
+	}
+
+	return uint64(float64(running)/d.interval) + 1
+}
+
+// take blocks until a slot has come and the worker has won it, and reports
+// false, having taken nothing, where ctx was done first.
+func (d *dispatcher) take(ctx context.Context, p *pause) bool {
+	if d == nil {
+		return true
+	}
+
+	for {
+		now := time.Now()
+		paused := p.paused(now)
+
+		n := d.next.Load()
+		at := d.slot(n, paused)
+
+		if wait := at.Sub(now); wait > 0 {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-timer.C:
+				continue
+			case <-ctx.Done():
+				timer.Stop()
+
+				return false
+			}
+		}
+
+		// Another worker took it between the load and here.
+		if !d.next.CompareAndSwap(n, n+1) {
+			continue
+		}
+
+		// The slot taken is due, but the float arithmetic of two readings of
+		// it need not quite agree that it is.
+		backlog := max(d.due(now, paused), n+1) - (n + 1)
+
+		d.mu.Lock()
+		d.delays.record(now.Sub(at))
+		d.maxBacklog = max(d.maxBacklog, backlog)
+		d.mu.Unlock()
+
+		return true
+	}
+}
+
+// read is what the dispatcher has seen as of now, for a run paused for paused.
+func (d *dispatcher) read(r *Result, now time.Time, paused time.Duration) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	taken := d.next.Load()
+	due := max(d.due(now, paused), taken)
+
+	h := &d.delays
+
+	stats := &DispatchStats{
+		Target:     float64(time.Second) / d.interval,
+		Issued:     due,
+		Started:    taken,
+		Backlog:    due - taken,
+		MaxBacklog: max(d.maxBacklog, due-taken),
+	}
+
+	if h.count > 0 {
+		stats.Min, stats.Avg, stats.P99, stats.Max = h.min, h.sum/time.Duration(h.count), h.quantile(0.99), h.max
+	}
+
+	r.Dispatch = stats
+}
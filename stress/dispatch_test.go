@@ -0,0 +1,73 @@
+package stress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestDispatchKeepsToTheRate: workers with time to spare start a unit a slot,
+// on time, and build no backlog.
+func TestDispatchKeepsToTheRate(t *testing.T) {
+	const (
+		target  = 200
+		timeout = 500 * time.Millisecond
+	)
+
+	r, err := Cfg{Workers: 2, Timeout: timeout, Stressor: "contention", Modes: []string{"atomic"}, TargetRate: target}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	d := r.Dispatch
+	if d == nil {
+		t.Fatal("Dispatch = nil on a run with a target rate, want what its schedule saw")
+	}
+
+	// The slots of the timeout and the one at its start, less any that came as
+	// it ended.
+	if want := uint64(target*timeout.Seconds()) + 1; d.Issued < want-1 || d.Issued > want {
+		t.Errorf("Issued = %d, want %d", d.Issued, want)
+	}
+
+	if d.Target != target || d.Backlog > 1 || d.Started+d.Backlog != d.Issued {
+		t.Errorf("Dispatch = %+v, want every slot issued started on time", *d)
+	}
+
+	// A unit a slot, and no unit without one.
+	if r.Count != d.Started*contentionBatch {
+		t.Errorf("Count = %d, want %d: a batch for each of the %d units started", r.Count, d.Started*contentionBatch, d.Started)
+	}
+}
+
+// TestDispatchBuildsABacklog: a rate the workers cannot keep up with is a
+// backlog, and a delay that grows with it, rather than a lower rate.
+func TestDispatchBuildsABacklog(t *testing.T) {
+	// A slot a microsecond is many more than one worker doing a batch of
+	// contention at a time can start.
+	r, err := Cfg{Workers: 1, Timeout: 100 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, TargetRate: 1e6}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	d := r.Dispatch
+	if d.Backlog < d.Issued/2 || d.MaxBacklog < d.Backlog || r.Count != d.Started*contentionBatch {
+		t.Errorf("Dispatch = %+v with a Count of %d, want a backlog of most of the slots", *d, r.Count)
+	}
+
+	if d.Max < 10*time.Millisecond || d.Min > d.Avg || d.Avg > d.Max {
+		t.Errorf("delays = %s min, %s avg, %s max; want the last units started well after their slots", d.Min, d.Avg, d.Max)
+	}
+}
+
+// TestClosedLoopHasNoDispatch: a run without a target rate reports no schedule.
+func TestClosedLoopHasNoDispatch(t *testing.T) {
+	r, err := Cfg{Workers: 1, Timeout: 10 * time.Millisecond, Stressor: "contention"}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Dispatch != nil {
+		t.Errorf("Dispatch = %+v, want nil", *r.Dispatch)
+	}
+}
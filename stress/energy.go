@@ -0,0 +1,174 @@
+package stress
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// energyEvery is how often a run reads the energy counters between its reads
+// of them, so that none wraps twice unseen: RAPL's package counter runs to
+// about 262kJ, which a package drawing 400W goes through in 11 minutes, and a
+// run with no Observer reads it at its start and its end alone.
+const energyEvery = 10 * time.Second
+
+// EnergyStats is the energy the machine's CPU packages drew over a run, as
+// Intel's RAPL counts it and powercap has it under /sys: Joules in all, and
+// Watts that over the run's time, paused or not. It is the packages' alone,
+// every core and cache in them, and neither the DRAM nor the rest of the
+// machine, so it is a figure to compare one machine's CPUs against another's
+// by, not a wall socket's.
+//
+// Zones is how many packages were read.
+type EnergyStats struct {
+	Zones  int
+	Joules float64
+	Watts  float64
+}
+
+// energyProbe reads every package's energy counter at the start of a run, at
+// every read and every energyEvery between, and at its end.
+type energyProbe struct {
+	zones []energyZone
+
+	// used is the microjoules every zone has counted since start, and done
+	// ends the goroutine that reads between the reads.
+	mu      sync.Mutex
+	used    uint64
+	stopped bool
+	done    chan struct{}
+}
+
+// energyZone is one package's counter: the file, the value it wraps at, and
+// what it read last.
+type energyZone struct {
+	path       string
+	wrap, last uint64
+}
+
+// newEnergyProbe is the probe of the RAPL packages under root, or nil where
+// there are none to read: a machine without RAPL, a VM, and since 5.10 any
+// user but root, energy_uj being readable by root alone there.
+func newEnergyProbe(root string) probe {
+	var zones []energyZone
+
+	dirs, _ := filepath.Glob(filepath.Join(root, "sys/class/powercap/intel-rapl:*"))
+	for _, dir := range dirs {
+		// A package's zone is intel-rapl:0; intel-rapl:0:0 is its cores, a
+		// part of it, and psys the whole platform, the packages in it.
+		if strings.Count(filepath.Base(dir), ":") != 1 {
+			continue
+		}
+
+		if name, err := os.ReadFile(filepath.Join(dir, "name")); err != nil || !strings.HasPrefix(string(name), "package") {
+			continue
+		}
+
+		now, ok := readEnergy(filepath.Join(dir, "energy_uj"))
+		if !ok {
+			continue
+		}
+
+		wrap, ok := readEnergy(filepath.Join(dir, "max_energy_range_uj"))
+		if !ok {
+			continue
+		}
+
+		zones = append(zones, energyZone{path: filepath.Join(dir, "energy_uj"), wrap: wrap, last: now})
+	}
+
+	if len(zones) == 0 {
+		return nil
+	}
+
+	return &energyProbe{zones: zones, done: make(chan struct{})}
+}
+
+func (p *energyProbe) start() {
+	p.mu.Lock()
+	for i := range p.zones {
+		if now, ok := readEnergy(p.zones[i].path); ok {
+			p.zones[i].last = now
+		}
+	}
+	p.mu.Unlock()
+
+	go func() {
+		tick := time.NewTicker(energyEvery)
+		defer tick.Stop()
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-tick.C:
+				p.mu.Lock()
+				p.sample()
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (p *energyProbe) stop() {
+	close(p.done)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sample()
+	p.stopped = true
+}
+
+func (p *energyProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.stopped {
+		p.sample()
+	}
+
+	s := EnergyStats{Zones: len(p.zones), Joules: float64(p.used) / 1e6}
+	if r.Elapsed > 0 {
+		s.Watts = s.Joules / r.Elapsed.Seconds()
+	}
+
+	r.Energy = &s
+}
+
+// sample adds what every zone has counted since it last read. A counter lower
+// than it was has wrapped, past its max and on from 0; one that cannot be read
+// this time is read again the next.
+func (p *energyProbe) sample() {
+	for i := range p.zones {
+		z := &p.zones[i]
+
+		now, ok := readEnergy(z.path)
+		if !ok {
+			continue
+		}
+
+		if now >= z.last {
+			p.used += now - z.last
+		} else if z.last <= z.wrap {
+			p.used += z.wrap - z.last + now
+		}
+
+		z.last = now
+	}
+}
+
+// readEnergy reads a powercap counter, in microjoules.
+func readEnergy(path string) (uint64, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+
+	return n, err == nil
+}
@@ -0,0 +1,129 @@
+package stress
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// raplPackage is a package zone's files, under powercap, counting from energy.
+func raplPackage(dir, energy string) map[string]string {
+	return map[string]string{
+		"sys/class/powercap/" + dir + "/name":                "package-0\n",
+		"sys/class/powercap/" + dir + "/energy_uj":           energy,
+		"sys/class/powercap/" + dir + "/max_energy_range_uj": "262143328850\n",
+	}
+}
+
+func TestNewEnergyProbe(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{name: "a package", files: raplPackage("intel-rapl:0", "1000\n"), want: 1},
+		{
+			name: "its cores and the platform left out",
+			files: merge(
+				raplPackage("intel-rapl:0", "1000\n"),
+				raplPackage("intel-rapl:1", "1000\n"),
+				map[string]string{
+					"sys/class/powercap/intel-rapl:0:0/name":                "core\n",
+					"sys/class/powercap/intel-rapl:0:0/energy_uj":           "500\n",
+					"sys/class/powercap/intel-rapl:0:0/max_energy_range_uj": "262143328850\n",
+					"sys/class/powercap/intel-rapl:2/name":                  "psys\n",
+					"sys/class/powercap/intel-rapl:2/energy_uj":             "9000\n",
+					"sys/class/powercap/intel-rapl:2/max_energy_range_uj":   "262143328850\n",
+				},
+			),
+			want: 2,
+		},
+		{
+			name:  "no counter",
+			files: map[string]string{"sys/class/powercap/intel-rapl:0/name": "package-0\n"},
+		},
+		{name: "no powercap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, tt.files)
+
+			p := newEnergyProbe(root)
+
+			got := 0
+			if p != nil {
+				got = len(p.(*energyProbe).zones)
+			}
+
+			if got != tt.want {
+				t.Errorf("newEnergyProbe() read %d zones, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEnergyProbeRead covers what a counter adds between reads, through a
+// wrap, and the figure at stop however the counter goes on after.
+func TestEnergyProbeRead(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, raplPackage("intel-rapl:0", "262143000000\n"))
+
+	p := newEnergyProbe(root)
+	if p == nil {
+		t.Fatal("newEnergyProbe() = nil, want the probe of the tree's package")
+	}
+
+	p.start()
+
+	// 328850uJ to the wrap, and 9671150uJ on from 0: 10J.
+	writeTree(t, root, map[string]string{"sys/class/powercap/intel-rapl:0/energy_uj": "9671150\n"})
+
+	r := Result{Elapsed: 2 * time.Second}
+	p.read(&r)
+
+	want := EnergyStats{Zones: 1, Joules: 10, Watts: 5}
+	if r.Energy == nil || *r.Energy != want {
+		t.Errorf("read() = %+v, want %+v", r.Energy, want)
+	}
+
+	writeTree(t, root, map[string]string{"sys/class/powercap/intel-rapl:0/energy_uj": "29671150\n"})
+	p.stop()
+	writeTree(t, root, map[string]string{"sys/class/powercap/intel-rapl:0/energy_uj": "99671150\n"})
+
+	r = Result{Elapsed: 3 * time.Second}
+	p.read(&r)
+
+	want = EnergyStats{Zones: 1, Joules: 30, Watts: 10}
+	if r.Energy == nil || *r.Energy != want {
+		t.Errorf("read() after stop = %+v, want the %+v it stopped at", r.Energy, want)
+	}
+}
+
+func TestReadEnergy(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"good": "262143328850\n", "bad": "lots\n"})
+
+	if n, ok := readEnergy(filepath.Join(root, "good")); !ok || n != 262143328850 {
+		t.Errorf("readEnergy() = %d, %t; want 262143328850, true", n, ok)
+	}
+
+	for _, name := range []string{"bad", "missing"} {
+		if _, ok := readEnergy(filepath.Join(root, name)); ok {
+			t.Errorf("readEnergy() of %s = ok, want not", name)
+		}
+	}
+}
+
+// merge is every map's entries in one.
+func merge(ms ...map[string]string) map[string]string {
+	all := map[string]string{}
+
+	for _, m := range ms {
+		maps.Copy(all, m)
+	}
+
+	return all
+}
@@ -0,0 +1,268 @@
+package stress
+
+import (
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// gcBatch is how many objects one gc unit allocates: tens of microseconds of
+// work, so the rate limit below is kept to a batch's worth of precision and a
+// worker notices the end of a run well inside a millisecond.
+const gcBatch = 256
+
+// gcNap is the longest a worker ahead of AllocRate sleeps in one unit. A
+// unit that slept until it was due would, at a rate of a few KiB a second,
+// sleep for a minute, and the drain with it.
+const gcNap = 10 * time.Millisecond
+
+// gcSizes are the sizes the objects a worker allocates cycle through: small,
+// as most of what a Go service allocates is, and spread over enough size
+// classes that the allocator is not exercising one span alone.
+var gcSizes = []int{16, 64, 256, 1024}
+
+// gcLongLived is one allocation in how many that is kept, replacing the oldest
+// the worker is holding, rather than dropped at once. The kept ones are what
+// the collector has to mark every cycle, and what HeapTarget sizes.
+const gcLongLived = 16
+
+// DefaultHeapTarget is the live heap a gc run holds where HeapTarget is 0:
+// enough that marking it is most of what a cycle costs, and comfortably inside
+// the memory a small pod has.
+const DefaultHeapTarget = 64 << 20
+
+// gcStressor allocates and drops objects as fast as the workers can, or at
+// AllocRate, while holding a live heap of HeapTarget: the churn that
+// makes a Go service's tail latency a property of its collector. It is the one
+// stressor that loads this process's own runtime rather than the machine, and
+// its summary says what the collector did about it.
+var gcStressor = &stressor{
+	name:  "gc",
+	label: "GC",
+	unit:  "allocation",
+	units: "allocations",
+	step:  "batch",
+	tuned: func(c Cfg) variant {
+		target := c.HeapTarget
+		if target == 0 {
+			target = DefaultHeapTarget
+		}
+
+		return variant{start: func(workers int) func(int) uint64 {
+			return startGC(workers, target, c.AllocRate)
+		}}
+	},
+	watch: func() probe { return &gcProbe{} },
+}
+
+// gcWorker is what one worker keeps between units: the objects it is holding
+// live, where the next one kept goes, and how many bytes it has allocated since
+// it began, which is what its share of AllocRate is measured against.
+type gcWorker struct {
+	kept  [][]byte
+	next  int
+	last  []byte
+	began time.Time
+	bytes int
+}
+
+// startGC returns the gc unit for workers that hold heapTarget bytes live
+// between them and allocate rate bytes a second, or as fast as they can where
+// rate is 0. Each worker holds a share of the heap of its own, so no two touch
+// the same slot and nothing needs a lock.
+func startGC(workers, heapTarget, rate int) func(int) uint64 {
+	var average int
+	for _, size := range gcSizes {
+		average += size
+	}
+
+	average /= len(gcSizes)
+
+	state := make([]gcWorker, workers)
+	for id := range state {
+		state[id].kept = make([][]byte, heapTarget/average/workers)
+	}
+
+	// The share of the rate each worker allocates at, in bytes a second.
+	share := float64(rate) / float64(workers)
+
+	return func(id int) uint64 {
+		w := &state[id]
+
+		if w.began.IsZero() {
+			w.began = time.Now()
+		}
+
+		// Ahead of its share, a worker naps rather than allocating, and says it
+		// did nothing. Measured from the worker's first unit rather than its
+		// last, so a late wake-up is made up rather than carried forward.
+		if share > 0 {
+			due := w.began.Add(time.Duration(float64(w.bytes) / share * float64(time.Second)))
+			if wait := time.Until(due); wait > 0 {
+				time.Sleep(min(wait, gcNap))
+
+				return 0
+			}
+		}
+
+		w.batch()
+
+		return gcBatch
+	}
+}
+
+// batch allocates one unit's objects, keeping one in gcLongLived of them.
+func (w *gcWorker) batch() {
+	for i := range gcBatch {
+		// Shifted by one every gcLongLived, so the objects kept are every
+		// size in turn rather than always the one that lines up with them.
+		size := gcSizes[(i+i/gcLongLived)%len(gcSizes)]
+
+		// Not constant-sized, so it is allocated on the heap; written to
+		// last, so the compiler cannot prove it unused.
+		obj := make([]byte, size)
+		w.last = obj
+
+		if len(w.kept) > 0 && i%gcLongLived == 0 {
+			w.kept[w.next] = obj
+			w.next = (w.next + 1) % len(w.kept)
+		}
+
+		w.bytes += size
+	}
+}
+
+// gcSample is how often the gc probe reads the heap for its peak. The peak is
+// between collections, so a sample once a cycle would miss it; at this rate a
+// cycle of a few milliseconds still lands a sample or two near its top.
+const gcSample = 10 * time.Millisecond
+
+// The runtime/metrics keys the gc probe reads.
+const (
+	gcCycles = "/gc/cycles/total:gc-cycles"
+	gcPauses = "/sched/pauses/total/gc:seconds"
+	gcHeap   = "/memory/classes/heap/objects:bytes"
+)
+
+// gcProbe is what the collector did over a run: cycles and pause time as the
+// difference between two readings, and the heap's peak from sampling between.
+type gcProbe struct {
+	// begin is the reading start took and end the one stop took, which a
+	// read from Snapshot may want as stop takes it.
+	mu      sync.Mutex
+	begin   []metrics.Sample
+	end     []metrics.Sample
+	stopped bool
+	peak    uint64
+
+	quit chan struct{}
+	done sync.WaitGroup
+}
+
+func readGC() []metrics.Sample {
+	samples := []metrics.Sample{{Name: gcCycles}, {Name: gcPauses}, {Name: gcHeap}}
+	metrics.Read(samples)
+
+	return samples
+}
+
+func (p *gcProbe) start() {
+	begin := readGC()
+
+	p.mu.Lock()
+	p.begin, p.peak = begin, begin[2].Value.Uint64()
+	p.mu.Unlock()
+
+	p.quit = make(chan struct{})
+
+	p.done.Add(1)
+
+	go func() {
+		defer p.done.Done()
+
+		ticker := time.NewTicker(gcSample)
+		defer ticker.Stop()
+
+		heap := []metrics.Sample{{Name: gcHeap}}
+
+		for {
+			select {
+			case <-p.quit:
+				return
+			case <-ticker.C:
+				metrics.Read(heap)
+
+				p.mu.Lock()
+				p.peak = max(p.peak, heap[0].Value.Uint64())
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (p *gcProbe) stop() {
+	close(p.quit)
+	p.done.Wait()
+
+	end := readGC()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end, p.stopped = end, true
+}
+
+// read is the collector's figures from the start of the run to now, or to its
+// end once stop has taken the last reading.
+func (p *gcProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	end := p.end
+	if !p.stopped {
+		end = readGC()
+	}
+
+	peak := max(p.peak, end[2].Value.Uint64())
+
+	total, longest := pauses(p.begin[1].Value.Float64Histogram(), end[1].Value.Float64Histogram())
+
+	r.GC = &GCStats{
+		Cycles:     end[0].Value.Uint64() - p.begin[0].Value.Uint64(),
+		PauseTotal: total,
+		PauseMax:   longest,
+		HeapPeak:   peak,
+	}
+}
+
+// pauses is the total and the longest of the pauses between two readings of a
+// pause histogram. The runtime keeps bucket counts rather than durations, so
+// each pause is taken at the middle of its bucket and the longest at the top of
+// the highest bucket with one in it: both are bounds as close as the histogram
+// can give, and a bucket is a few percent wide.
+func pauses(before, after *metrics.Float64Histogram) (total, longest time.Duration) {
+	var seconds float64
+
+	for i, n := range after.Counts {
+		n -= before.Counts[i]
+		if n == 0 {
+			continue
+		}
+
+		low, high := after.Buckets[i], after.Buckets[i+1]
+
+		// The open-ended buckets at either end have one edge to go on.
+		switch {
+		case math.IsInf(low, -1):
+			low = high
+		case math.IsInf(high, 1):
+			high = low
+		}
+
+		seconds += float64(n) * (low + high) / 2
+		longest = time.Duration(high * float64(time.Second))
+	}
+
+	return time.Duration(seconds * float64(time.Second)), longest
+}
@@ -0,0 +1,152 @@
+package stress
+
+import (
+	"context"
+	"math"
+	"runtime/metrics"
+	"testing"
+	"time"
+)
+
+// TestGCUnitAllocatesItsBatch holds the unit to the count it reports.
+func TestGCUnitAllocatesItsBatch(t *testing.T) {
+	const workers = 2
+
+	unit := startGC(workers, 1<<20, 0)
+
+	for id := range workers {
+		if got := unit(id); got != gcBatch {
+			t.Errorf("gc unit for worker %d = %d, want %d", id, got, gcBatch)
+		}
+	}
+}
+
+// TestGCBatchKeepsEverySize: kept on the same stride the sizes cycle on, every
+// object held live would be 16 bytes, and --heap-target a twentieth of what it
+// says.
+func TestGCBatchKeepsEverySize(t *testing.T) {
+	w := gcWorker{kept: make([][]byte, gcBatch)}
+	w.batch()
+
+	held := map[int]int{}
+	for _, obj := range w.kept[:w.next] {
+		held[len(obj)]++
+	}
+
+	if w.next != gcBatch/gcLongLived {
+		t.Errorf("a batch kept %d objects, want one in %d of %d", w.next, gcLongLived, gcBatch)
+	}
+
+	for _, size := range gcSizes {
+		if held[size] == 0 {
+			t.Errorf("a batch kept %v objects of each size, want some of every size in gcSizes", held)
+		}
+	}
+}
+
+// TestGCUnitKeepsToItsRate: a worker that is ahead of its share naps instead of
+// allocating, and never for longer than gcNap, so a slow rate does not make a
+// slow drain.
+func TestGCUnitKeepsToItsRate(t *testing.T) {
+	// A byte a second: the first batch puts the worker days ahead.
+	unit := startGC(1, 0, 1)
+
+	if got := unit(0); got != gcBatch {
+		t.Fatalf("the first gc unit = %d, want a batch before any pacing", got)
+	}
+
+	began := time.Now()
+
+	if got := unit(0); got != 0 {
+		t.Errorf("a gc unit ahead of its rate = %d, want 0", got)
+	}
+
+	// Generous, for a loaded runner; what matters is that it is not days.
+	if took := time.Since(began); took > 50*gcNap {
+		t.Errorf("a gc unit ahead of its rate took %s, want a nap of about %s", took, gcNap)
+	}
+}
+
+// TestPauses covers the arithmetic over histogram buckets: the difference
+// between two readings, each pause at the middle of its bucket, and the longest
+// at the top of the highest bucket there is one in.
+func TestPauses(t *testing.T) {
+	buckets := []float64{math.Inf(-1), 0.001, 0.002, 0.004, math.Inf(1)}
+
+	tests := []struct {
+		name        string
+		before      []uint64
+		after       []uint64
+		wantTotal   time.Duration
+		wantLongest time.Duration
+	}{
+		{name: "no pauses", before: []uint64{0, 1, 2, 0}, after: []uint64{0, 1, 2, 0}},
+		// Two at 1.5ms and one at 3ms, counted from the first reading.
+		{name: "pauses in two buckets", before: []uint64{0, 1, 0, 0}, after: []uint64{0, 3, 1, 0}, wantTotal: 6 * time.Millisecond, wantLongest: 4 * time.Millisecond},
+		// The open-ended buckets are taken at the edge they have.
+		{name: "pauses off either end", after: []uint64{1, 0, 0, 1}, wantTotal: 5 * time.Millisecond, wantLongest: 4 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := &metrics.Float64Histogram{Counts: tt.before, Buckets: buckets}
+			if before.Counts == nil {
+				before.Counts = make([]uint64, len(tt.after))
+			}
+
+			after := &metrics.Float64Histogram{Counts: tt.after, Buckets: buckets}
+
+			total, longest := pauses(before, after)
+
+			// To the microsecond: the bucket edges are float seconds.
+			if total.Round(time.Microsecond) != tt.wantTotal || longest.Round(time.Microsecond) != tt.wantLongest {
+				t.Errorf("pauses() = %s, %s; want %s, %s", total, longest, tt.wantTotal, tt.wantLongest)
+			}
+		})
+	}
+}
+
+// TestRunContextReportsTheCollector runs gc: its probe's figures come back
+// beside the count, and a heap this size cannot get through without a cycle.
+func TestRunContextReportsTheCollector(t *testing.T) {
+	r, err := Cfg{Workers: 2, Timeout: 200 * time.Millisecond, Stressor: "gc", HeapTarget: 8 << 20}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.GC == nil {
+		t.Fatal("GC = nil, want the collector's figures from a gc run")
+	}
+
+	if r.GC.Cycles == 0 || r.GC.HeapPeak == 0 {
+		t.Errorf("GC = %+v, want the collector to have run", *r.GC)
+	}
+}
+
+// TestSnapshotDuringAGCRunsWait reads a gc run while Wait stops its probe, which
+// is a race the race detector finds where the probe's last reading is taken
+// without its lock.
+func TestSnapshotDuringAGCRunsWait(t *testing.T) {
+	run, err := Cfg{Workers: 1, Timeout: 50 * time.Millisecond, Stressor: "gc", HeapTarget: 8 << 20}.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+
+	waited := make(chan Result)
+	go func() { waited <- run.Wait() }()
+
+	for {
+		select {
+		case r := <-waited:
+			if s := run.Snapshot(); s.GC == nil || *s.GC != *r.GC {
+				t.Errorf("Snapshot().GC after Wait() = %+v, want Wait()'s %+v", s.GC, *r.GC)
+			}
+
+			return
+		default:
+			if s := run.Snapshot(); s.GC == nil {
+				t.Fatal("Snapshot().GC = nil during a gc run, want the collector's figures")
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package stress
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Host is the machine a run ran on, as far as the process can tell from inside
+// it, so that a Result read beside another's says what each machine was: two
+// rates from a 4-core VM and a 64-core host are not a regression.
+//
+// Model, Cores and Threads are the machine's CPUs, as /proc/cpuinfo has them:
+// the model name, the physical cores and the logical CPUs, "" or 0 where it
+// does not say, as it does not on any OS but Linux, and Cores does not on most
+// arm machines. CPUs is how many of those the process may run on, and
+// GOMAXPROCS how many the Go runtime runs it on, either of which a container
+// or taskset can hold below Threads.
+//
+// CPULimit is the cores the process's cgroup's CPU quota allows it, and
+// MemoryLimit the bytes its memory limit does, either 0 for none, or none to
+// read.
+type Host struct {
+	Model          string
+	Cores, Threads int
+	CPUs           int
+	GOMAXPROCS     int
+
+	OS, Arch string
+	Kernel   string // the kernel's release, as uname -r has it, and "" off Linux
+	Go       string // the Go the binary was built with, "go1.25.0"
+
+	CPULimit    float64
+	MemoryLimit uint64
+}
+
+// readHost is the Host the process is on, with /proc and /sys read under root.
+func readHost(root string) Host {
+	h := Host{
+		CPUs:       runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Go:         runtime.Version(),
+	}
+
+	// The toolchain's, where the binary records it, which is the version a
+	// release says it was built with.
+	if info, ok := debug.ReadBuildInfo(); ok && info.GoVersion != "" {
+		h.Go = info.GoVersion
+	}
+
+	h.Model, h.Cores, h.Threads = readCPUInfo(filepath.Join(root, "proc/cpuinfo"))
+
+	if b, err := os.ReadFile(filepath.Join(root, "proc/sys/kernel/osrelease")); err == nil {
+		h.Kernel = strings.TrimSpace(string(b))
+	}
+
+	h.CPULimit = cgroupCPULimit(root)
+	h.MemoryLimit = cgroupMemoryLimit(root)
+
+	return h
+}
+
+// readCPUInfo reads the model name, and the cores and logical CPUs, from a
+// /proc/cpuinfo: a logical CPU is a "processor" entry, and a core each
+// physical id and core id pair, which x86 has and most arm does not. An arm
+// machine names its model in "Model", where it names it at all.
+func readCPUInfo(path string) (model string, cores, threads int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, 0
+	}
+	defer f.Close()
+
+	var (
+		pkg      string
+		physical = map[[2]string]bool{}
+	)
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "processor":
+			threads++
+		case "model name":
+			if model == "" {
+				model = value
+			}
+		case "Model":
+			if model == "" {
+				model = value
+			}
+		case "physical id":
+			pkg = value
+		case "core id":
+			physical[[2]string{pkg, value}] = true
+		}
+	}
+
+	return model, len(physical), threads
+}
+
+// cgroupCPULimit is the cores the cgroup's CPU quota allows, its quota over its
+// period: v2's cpu.max reads "200000 100000" for two, and "max 100000" for no
+// quota, and v1 keeps the two in files of their own, -1 for none.
+func cgroupCPULimit(root string) float64 {
+	path, version := cgroupFile(root, "cpu", "cpu.cfs_quota_us", "cpu.max")
+
+	var quota, period string
+
+	switch version {
+	case 2:
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return 0
+		}
+
+		quota, period, _ = strings.Cut(strings.TrimSpace(string(b)), " ")
+	case 1:
+		q, err := os.ReadFile(path)
+		if err != nil {
+			return 0
+		}
+
+		p, err := os.ReadFile(filepath.Join(filepath.Dir(path), "cpu.cfs_period_us"))
+		if err != nil {
+			return 0
+		}
+
+		quota, period = strings.TrimSpace(string(q)), strings.TrimSpace(string(p))
+	default:
+		return 0
+	}
+
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+
+	return q / p
+}
+
+// cgroupMemoryLimit is the bytes the cgroup's memory limit allows: v2's
+// memory.max, "max" for none, or v1's memory.limit_in_bytes, which has no word
+// for none and reads a page short of the largest int64 instead.
+func cgroupMemoryLimit(root string) uint64 {
+	path, _ := cgroupFile(root, "memory", "memory.limit_in_bytes", "memory.max")
+	if path == "" {
+		return 0
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil || n >= 1<<62 {
+		return 0
+	}
+
+	return n
+}
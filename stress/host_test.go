@@ -0,0 +1,145 @@
+package stress
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// cpuinfoX86 is a machine of one package with two cores of two threads each.
+const cpuinfoX86 = `processor	: 0
+model name	: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+physical id	: 0
+core id		: 0
+
+processor	: 1
+model name	: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+physical id	: 0
+core id		: 1
+
+processor	: 2
+model name	: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+physical id	: 0
+core id		: 0
+
+processor	: 3
+model name	: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+physical id	: 0
+core id		: 1
+`
+
+func TestReadCPUInfo(t *testing.T) {
+	tests := []struct {
+		name           string
+		cpuinfo        string
+		model          string
+		cores, threads int
+	}{
+		{name: "x86, hyperthreaded", cpuinfo: cpuinfoX86, model: "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz", cores: 2, threads: 4},
+		{
+			name:    "two packages",
+			cpuinfo: "processor : 0\nphysical id : 0\ncore id : 0\n\nprocessor : 1\nphysical id : 1\ncore id : 0\n",
+			cores:   2, threads: 2,
+		},
+		{
+			name:    "arm, with no cores said",
+			cpuinfo: "processor\t: 0\nBogoMIPS\t: 108.00\n\nprocessor\t: 1\nBogoMIPS\t: 108.00\n\nModel\t\t: Raspberry Pi 4 Model B Rev 1.4\n",
+			model:   "Raspberry Pi 4 Model B Rev 1.4", threads: 2,
+		},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, map[string]string{"cpuinfo": tt.cpuinfo})
+
+			model, cores, threads := readCPUInfo(filepath.Join(root, "cpuinfo"))
+			if model != tt.model || cores != tt.cores || threads != tt.threads {
+				t.Errorf("readCPUInfo() = %q, %d, %d; want %q, %d, %d", model, cores, threads, tt.model, tt.cores, tt.threads)
+			}
+		})
+	}
+}
+
+func TestCgroupLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		cpu    float64
+		memory uint64
+	}{
+		{
+			name: "v2",
+			files: map[string]string{
+				"proc/self/cgroup":         "0::/\n",
+				"sys/fs/cgroup/cpu.max":    "150000 100000\n",
+				"sys/fs/cgroup/memory.max": "4294967296\n",
+			},
+			cpu: 1.5, memory: 4 << 30,
+		},
+		{
+			name: "v2, no limits",
+			files: map[string]string{
+				"proc/self/cgroup":         "0::/\n",
+				"sys/fs/cgroup/cpu.max":    "max 100000\n",
+				"sys/fs/cgroup/memory.max": "max\n",
+			},
+		},
+		{
+			name: "v1",
+			files: map[string]string{
+				"proc/self/cgroup": "5:memory:/docker/abc\n3:cpu,cpuacct:/docker/abc\n",
+				"sys/fs/cgroup/cpu,cpuacct/docker/abc/cpu.cfs_quota_us":  "200000\n",
+				"sys/fs/cgroup/cpu,cpuacct/docker/abc/cpu.cfs_period_us": "100000\n",
+				"sys/fs/cgroup/memory/docker/abc/memory.limit_in_bytes":  "536870912\n",
+			},
+			cpu: 2, memory: 512 << 20,
+		},
+		{
+			name: "v1, no limits",
+			files: map[string]string{
+				"proc/self/cgroup":                            "5:memory:/\n3:cpu,cpuacct:/\n",
+				"sys/fs/cgroup/cpu,cpuacct/cpu.cfs_quota_us":  "-1\n",
+				"sys/fs/cgroup/cpu,cpuacct/cpu.cfs_period_us": "100000\n",
+				"sys/fs/cgroup/memory/memory.limit_in_bytes":  "9223372036854771712\n",
+			},
+		},
+		{name: "no cgroup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, tt.files)
+
+			if cpu, memory := cgroupCPULimit(root), cgroupMemoryLimit(root); cpu != tt.cpu || memory != tt.memory {
+				t.Errorf("cgroupCPULimit(), cgroupMemoryLimit() = %g, %d; want %g, %d", cpu, memory, tt.cpu, tt.memory)
+			}
+		})
+	}
+}
+
+// TestReadHost covers a Host read from a tree: what the tree has, and the
+// runtime's own figures, which no tree can stand in for.
+func TestReadHost(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/cpuinfo":              cpuinfoX86,
+		"proc/sys/kernel/osrelease": "6.1.0-18-amd64\n",
+		"proc/self/cgroup":          "0::/\n",
+		"sys/fs/cgroup/cpu.max":     "200000 100000\n",
+	})
+
+	h := readHost(root)
+
+	want := Host{
+		Model: "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz", Cores: 2, Threads: 4,
+		CPUs: runtime.NumCPU(), GOMAXPROCS: runtime.GOMAXPROCS(0),
+		OS: runtime.GOOS, Arch: runtime.GOARCH, Kernel: "6.1.0-18-amd64", Go: runtime.Version(),
+		CPULimit: 2,
+	}
+	if h != want {
+		t.Errorf("readHost() = %+v, want %+v", h, want)
+	}
+}
@@ -0,0 +1,176 @@
+package stress
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// LatencyFloor is the shortest LatencyProbe interval a run will start on.
+// Below it the probe is a busy loop rather than a sleeper, and what it measures
+// is the cost of arming a timer rather than of being woken by one.
+const LatencyFloor = 100 * time.Microsecond
+
+// latencyProbe is LatencyProbe's: a goroutine that sleeps for a fixed interval
+// over and over and records how late each wakeup was, which is the question
+// cyclictest asks of a kernel, asked here of a node under the load the workers
+// put on it. What it measures is everything between a timer firing and code
+// running — the kernel's scheduler, and the Go runtime's, which has to find the
+// goroutine a P among busy workers.
+//
+// locked pins the goroutine to an OS thread of its own with LockOSThread, so a
+// wakeup never waits for the runtime to find it a thread; the P it needs is
+// still shared with the workers.
+type latencyProbe struct {
+	interval time.Duration
+	locked   bool
+
+	mu    sync.Mutex
+	hist  latencyHistogram
+	quit  chan struct{}
+	ended sync.WaitGroup
+}
+
+func newLatencyProbe(interval time.Duration, locked bool) *latencyProbe {
+	return &latencyProbe{interval: interval, locked: locked}
+}
+
+func (p *latencyProbe) start() {
+	p.quit = make(chan struct{})
+
+	p.ended.Add(1)
+
+	go func() {
+		defer p.ended.Done()
+
+		if p.locked {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+		}
+
+		// Absolute deadlines, as cyclictest keeps, so the time a wakeup takes
+		// to be handled is not added to the next sleep. A wakeup so late it
+		// has passed the next deadline starts the schedule again from now
+		// rather than recording a run of sleeps that never slept.
+		next := time.Now().Add(p.interval)
+
+		// A timer rather than time.Sleep, so the end of a run is not kept
+		// waiting out the interval.
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-p.quit:
+				return
+			case <-timer.C:
+			}
+
+			late := time.Since(next)
+
+			p.mu.Lock()
+			p.hist.record(late)
+			p.mu.Unlock()
+
+			next = next.Add(p.interval)
+			if now := time.Now(); next.Before(now) {
+				next = now.Add(p.interval)
+			}
+
+			timer.Reset(time.Until(next))
+		}
+	}()
+}
+
+func (p *latencyProbe) stop() {
+	close(p.quit)
+	p.ended.Wait()
+}
+
+// read is every wakeup so far, as the run's own count is.
+func (p *latencyProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := &p.hist
+
+	stats := &LatencyStats{Interval: p.interval, Locked: p.locked, Wakeups: h.count}
+	if h.count > 0 {
+		stats.Min, stats.Avg, stats.P99, stats.Max = h.min, h.sum/time.Duration(h.count), h.quantile(0.99), h.max
+	}
+
+	r.Latency = stats
+}
+
+// latencySub is how many buckets each power of two is split into: 16, for a
+// bucket no wider than a sixteenth of the latencies in it, which is as close as
+// a p99 needs to be and leaves a histogram of a thousand counts however long
+// the run. Keeping every sample instead is memory an indefinite run grows
+// without bound.
+const latencySub = 16
+
+// latencyHistogram is a log-linear histogram of latencies in nanoseconds, and
+// the exact minimum, maximum and sum beside it.
+type latencyHistogram struct {
+	counts   [64 * latencySub]uint64
+	count    uint64
+	sum      time.Duration
+	min, max time.Duration
+}
+
+// latencyBucket is the bucket ns falls in: itself below latencySub, and past
+// that its power of two and the top bits under it.
+func latencyBucket(ns uint64) int {
+	if ns < latencySub {
+		return int(ns)
+	}
+
+	shift := bits.Len64(ns) - bits.Len64(latencySub)
+
+	return shift*latencySub + int(ns>>shift)
+}
+
+// latencyBound is the largest latency bucket i holds.
+func latencyBound(i int) time.Duration {
+	if i < 2*latencySub {
+		return time.Duration(i)
+	}
+
+	shift := i/latencySub - 1
+	top := i%latencySub + latencySub
+
+	return time.Duration((uint64(top+1) << shift) - 1)
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	// A wakeup cannot be early, but a clock read a nanosecond apart can say it
+	// was; it is on time.
+	d = max(d, 0)
+
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+
+	h.max = max(h.max, d)
+	h.sum += d
+	h.count++
+	h.counts[latencyBucket(uint64(d))]++
+}
+
+// quantile is the bound of the bucket the q-th latency falls in: no latency
+// below it is past q, and the bucket is narrow enough that it is close.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	rank := uint64(q * float64(h.count))
+
+	var seen uint64
+
+	for i, n := range h.counts {
+		seen += n
+		if seen > rank {
+			return min(latencyBound(i), h.max)
+		}
+	}
+
+	return h.max
+}
@@ -0,0 +1,84 @@
+package stress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestLatencyBucketsAreContiguous holds the histogram's arithmetic together:
+// every latency lands in a bucket whose bound is at or above it, and past the
+// previous bucket's, so a quantile read off the bounds is never below the
+// latency it stands for and never more than a bucket past it.
+func TestLatencyBucketsAreContiguous(t *testing.T) {
+	for _, ns := range []uint64{0, 1, 15, 16, 17, 31, 32, 33, 1000, 999999, 1 << 40, 1<<63 - 1} {
+		i := latencyBucket(ns)
+
+		if bound := latencyBound(i); uint64(bound) < ns {
+			t.Errorf("latencyBucket(%d) = %d, whose bound %d is below it", ns, i, bound)
+		}
+
+		if i > 0 {
+			if prev := latencyBound(i - 1); uint64(prev) >= ns {
+				t.Errorf("latencyBucket(%d) = %d, but bucket %d's bound %d already holds it", ns, i, i-1, prev)
+			}
+		}
+	}
+}
+
+// TestLatencyProbeRead covers the figures read off the histogram: none before
+// the first wakeup, and the p99 a bucket's bound rather than the latency itself.
+func TestLatencyProbeRead(t *testing.T) {
+	p := newLatencyProbe(time.Millisecond, true)
+
+	var r Result
+
+	p.read(&r)
+
+	if want := (LatencyStats{Interval: time.Millisecond, Locked: true}); r.Latency == nil || *r.Latency != want {
+		t.Errorf("read() of no wakeups = %+v, want %+v", r.Latency, want)
+	}
+
+	// 99 wakeups 50us late and one 5ms late: the p99 is the last of the 50s.
+	for range 99 {
+		p.hist.record(50 * time.Microsecond)
+	}
+
+	p.hist.record(5 * time.Millisecond)
+
+	// A clock read out of order is on time, not early.
+	p.hist.record(-time.Microsecond)
+
+	p.read(&r)
+
+	want := LatencyStats{
+		Interval: time.Millisecond,
+		Locked:   true,
+		Wakeups:  101,
+		Min:      0,
+		Avg:      98514 * time.Nanosecond,
+		P99:      51199 * time.Nanosecond,
+		Max:      5 * time.Millisecond,
+	}
+
+	if *r.Latency != want {
+		t.Errorf("read() = %+v, want %+v", *r.Latency, want)
+	}
+}
+
+// TestRunContextReportsWakeupLatency: the probe's figures come back, locked to
+// its thread as asked, with wakeups at the interval it was given.
+func TestRunContextReportsWakeupLatency(t *testing.T) {
+	r, err := Cfg{Workers: 1, Timeout: 200 * time.Millisecond, LatencyProbe: time.Millisecond, LatencyLocked: true}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Latency == nil {
+		t.Fatal("Latency = nil, want the probe's figures")
+	}
+
+	if l := *r.Latency; l.Interval != time.Millisecond || !l.Locked || l.Wakeups == 0 || l.Min > l.P99 || l.P99 > l.Max+l.Max/latencySub {
+		t.Errorf("Latency = %+v, want a locked probe's wakeups every 1ms, in order", l)
+	}
+}
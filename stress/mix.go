@@ -0,0 +1,194 @@
+package stress
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Group is one stressor in a Mix and the workers it has to itself: the
+// bcrypt:4 of the stressy command's `--mix bcrypt:4,cache:2`.
+type Group struct {
+	Stressor string
+	Workers  int
+}
+
+// group is one stressor's share of a run as a Run holds it: the configuration it
+// was given, the variants it takes turns between, the units they were started
+// as and the tally of what they did. A run without a mix is one group, whose
+// configuration is the run's own.
+type group struct {
+	cfg      Cfg
+	s        *stressor
+	variants []variant
+	units    []func(id int) uint64
+	done     *tally
+
+	// busy is the unit each worker is on, for a run with a StallTimeout, and
+	// nil for any other.
+	busy *busy
+}
+
+// newGroup readies the group c configures, short of starting its units, which
+// is Start's to do because starting one can fail. c has been validated, so
+// neither lookup can.
+func newGroup(c Cfg) *group {
+	variants, _ := c.variants()
+
+	return &group{
+		cfg:      c,
+		s:        c.stressor(),
+		variants: variants,
+		units:    make([]func(id int) uint64, len(variants)),
+		done:     newTally(len(variants)),
+	}
+}
+
+// read is what the group has done over running, the time the run has gone
+// less the time it was paused.
+func (g *group) read(running time.Duration) GroupResult {
+	count := g.done.total()
+
+	res := GroupResult{
+		Stressor: g.s.name,
+		Workers:  g.cfg.Workers,
+		Count:    count,
+		Rate:     rate(count, running),
+	}
+
+	if g.s.kind == "" {
+		return res
+	}
+
+	res.Variants = make([]VariantResult, len(g.variants))
+
+	for i, v := range g.variants {
+		count := g.done.counts[i].Load()
+		spent := time.Duration(g.done.spent[i].Load())
+
+		res.Variants[i] = VariantResult{Name: v.name, Count: count, Spent: spent, Rate: rate(count, spent)}
+	}
+
+	return res
+}
+
+// groups is the configuration of every group a run has: the run itself where
+// there is no mix, and otherwise one per entry, each with its own stressor and
+// workers and the settings of the run's its stressor takes. Only those, so the
+// cache group of `--mix bcrypt:2,cache:2 --working-set 1MiB` gets the working
+// set and the bcrypt group is not told it takes none.
+func (c Cfg) groups() []Cfg {
+	if len(c.Mix) == 0 {
+		return []Cfg{c}
+	}
+
+	cfgs := make([]Cfg, len(c.Mix))
+
+	for i, m := range c.Mix {
+		g := Cfg{
+			Workers:  m.Workers,
+			Timeout:  c.Timeout,
+			Stressor: m.Stressor,
+		}
+
+		s := g.stressor()
+
+		if len(s.variants) > 0 {
+			g.Modes = c.Modes
+		}
+
+		if s.sized != nil {
+			g.WorkingSets = c.WorkingSets
+		}
+
+		if s == gcStressor {
+			g.AllocRate, g.HeapTarget = c.AllocRate, c.HeapTarget
+		}
+
+		if s == syscallStressor {
+			g.Exec = c.Exec
+		}
+
+		cfgs[i] = g
+	}
+
+	return cfgs
+}
+
+// validateMix is Validate for a run with a mix: the run's own settings as a
+// run without one would have them checked, then the entries, then every group
+// as the configuration it will run as, each error naming the group it is in.
+func (c Cfg) validateMix() error {
+	// The groups count in units of their own, hashes and ops and calls, and a
+	// budget of all of them added up measures none.
+	if c.Count > 0 {
+		return errors.New("count is in one stressor's units, and a mix has several; give a count to a run of one stressor")
+	}
+
+	// A schedule's units are as much one stressor's as a budget's.
+	if c.TargetRate > 0 {
+		return errors.New("target rate is in one stressor's units, and a mix has several; give a target rate to a run of one stressor")
+	}
+
+	// A segment's workers are some of one group's, and a mix has several.
+	if len(c.Shape) > 0 {
+		return errors.New("shape drives one group's workers, and a mix has several; give a shape to a run of one stressor")
+	}
+
+	if c.Chaos != (Chaos{}) {
+		return errors.New("chaos drives one group's workers, and a mix has several; give chaos to a run of one stressor")
+	}
+
+	// Workers 1 stands in for the groups', which are checked below.
+	run := Cfg{Workers: 1, Timeout: c.Timeout, Report: c.Report, LatencyProbe: c.LatencyProbe, LatencyLocked: c.LatencyLocked, Burst: c.Burst, Nice: c.Nice, Sched: c.Sched, MaxTemp: c.MaxTemp, StallTimeout: c.StallTimeout}
+	if err := run.Validate(); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+
+	for _, m := range c.Mix {
+		// An empty name is bcrypt everywhere else; in a mix it is a group
+		// nobody could tell apart from a typo.
+		if _, ok := lookupStressor(m.Stressor); !ok || m.Stressor == "" {
+			return fmt.Errorf("mix stressor must be one of %s", stressorNames())
+		}
+
+		// Two groups of one stressor are one group with their workers added,
+		// and two summary lines nobody could tell apart.
+		if seen[m.Stressor] {
+			return fmt.Errorf("mix has %s twice; give it one group with the workers of both", m.Stressor)
+		}
+
+		seen[m.Stressor] = true
+	}
+
+	// A setting no group takes would be dropped where a run without a mix
+	// refuses it, so it is refused here too.
+	takes := func(has func(s *stressor) bool) bool {
+		for _, m := range c.Mix {
+			if s, _ := lookupStressor(m.Stressor); has(s) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch {
+	case len(c.Modes) > 0 && !takes(func(s *stressor) bool { return len(s.variants) > 0 }):
+		return errors.New("no stressor in the mix has modes")
+	case len(c.WorkingSets) > 0 && !takes(func(s *stressor) bool { return s.sized != nil }):
+		return errors.New("no stressor in the mix takes a working set")
+	case (c.AllocRate != 0 || c.HeapTarget != 0) && !takes(func(s *stressor) bool { return s == gcStressor }):
+		return errors.New("no stressor in the mix takes an alloc rate or a heap target")
+	}
+
+	for _, g := range c.groups() {
+		if err := g.Validate(); err != nil {
+			return fmt.Errorf("mix %s: %w", g.Stressor, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package stress
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateMix(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Cfg
+		wantErr string
+	}{
+		{name: "two groups", cfg: Cfg{Mix: []Group{{"bcrypt", 4}, {"cache", 2}}}},
+		// Workers and Stressor are not read where there is a mix.
+		{name: "the run's own workers left at 0", cfg: Cfg{Workers: 0, Stressor: "disk", Mix: []Group{{"gc", 1}}}},
+		{name: "settings for the groups that take them", cfg: Cfg{Mix: []Group{{"contention", 1}, {"cache", 1}, {"gc", 1}}, Modes: []string{"mutex"}, WorkingSets: []int{1 << 20}, AllocRate: 1 << 20}},
+		{name: "a stressor there is none of", cfg: Cfg{Mix: []Group{{"memory", 2}}}, wantErr: "mix stressor must be one of bcrypt, contention, cache, gc, syscall"},
+		{name: "a group with no name", cfg: Cfg{Mix: []Group{{"", 2}}}, wantErr: "mix stressor must be one of "},
+		{name: "a stressor twice", cfg: Cfg{Mix: []Group{{"cache", 1}, {"cache", 2}}}, wantErr: "mix has cache twice; give it one group with the workers of both"},
+		{name: "a group with no workers", cfg: Cfg{Mix: []Group{{"bcrypt", 1}, {"cache", 0}}}, wantErr: "mix cache: workers must be 1 or greater"},
+		{name: "modes nobody has", cfg: Cfg{Mix: []Group{{"bcrypt", 1}, {"cache", 1}}, Modes: []string{"atomic"}}, wantErr: "no stressor in the mix has modes"},
+		{name: "a mode of the wrong group", cfg: Cfg{Mix: []Group{{"contention", 1}, {"syscall", 1}}, Modes: []string{"atomic"}}, wantErr: `mix syscall: stressor syscall has no mode "atomic"`},
+		{name: "a working set nobody takes", cfg: Cfg{Mix: []Group{{"bcrypt", 1}}, WorkingSets: []int{1 << 20}}, wantErr: "no stressor in the mix takes a working set"},
+		{name: "a heap target nobody takes", cfg: Cfg{Mix: []Group{{"bcrypt", 1}}, HeapTarget: 1 << 20}, wantErr: "no stressor in the mix takes an alloc rate or a heap target"},
+		// The run's own settings are checked as the run's, not as a group's.
+		{name: "a negative timeout", cfg: Cfg{Timeout: -time.Second, Mix: []Group{{"bcrypt", 1}}}, wantErr: "timeout must be 0 (indefinite) or greater"},
+		// Hashes and calls added up are neither.
+		{name: "a burst with no on period", cfg: Cfg{Burst: Burst{Off: time.Second}, Mix: []Group{{"bcrypt", 1}, {"syscall", 1}}}, wantErr: "burst on and off must both be greater than 0"},
+		{name: "a shape", cfg: Cfg{Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: 1}}, Mix: []Group{{"bcrypt", 1}, {"syscall", 1}}}, wantErr: "shape drives one group's workers, and a mix has several"},
+		{name: "chaos", cfg: Cfg{Timeout: time.Minute, Chaos: Chaos{MaxWorkers: 1, MaxDuty: 1, MinEvery: time.Second, MaxEvery: time.Second}, Mix: []Group{{"bcrypt", 1}, {"syscall", 1}}}, wantErr: "chaos drives one group's workers, and a mix has several"},
+		{name: "a target rate", cfg: Cfg{TargetRate: 50, Mix: []Group{{"bcrypt", 1}, {"syscall", 1}}}, wantErr: "target rate is in one stressor's units"},
+		{name: "a count", cfg: Cfg{Count: 100, Mix: []Group{{"bcrypt", 1}, {"syscall", 1}}}, wantErr: "count is in one stressor's units"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want it to start with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestMixGroupsGetTheirSettings: each group is given the settings its stressor
+// takes and no others, which is what lets one command line carry a working set
+// for cache and a mode for contention.
+func TestMixGroupsGetTheirSettings(t *testing.T) {
+	cfg := Cfg{
+		Timeout:     time.Minute,
+		Mix:         []Group{{"contention", 3}, {"cache", 2}, {"gc", 1}},
+		Modes:       []string{"mutex"},
+		WorkingSets: []int{1 << 20},
+		HeapTarget:  8 << 20,
+	}
+
+	groups := cfg.groups()
+
+	if len(groups) != 3 {
+		t.Fatalf("groups() = %d groups, want 3", len(groups))
+	}
+
+	contention, cache, gc := groups[0], groups[1], groups[2]
+
+	if contention.Workers != 3 || !slices.Equal(contention.Modes, []string{"mutex"}) || contention.WorkingSets != nil || contention.HeapTarget != 0 {
+		t.Errorf("the contention group = %+v, want 3 workers and the mode alone", contention)
+	}
+
+	if cache.Workers != 2 || cache.Modes != nil || !slices.Equal(cache.WorkingSets, []int{1 << 20}) {
+		t.Errorf("the cache group = %+v, want 2 workers and the working set alone", cache)
+	}
+
+	if gc.Workers != 1 || gc.Modes != nil || gc.HeapTarget != 8<<20 {
+		t.Errorf("the gc group = %+v, want 1 worker and the heap target alone", gc)
+	}
+
+	for _, g := range groups {
+		if g.Timeout != time.Minute {
+			t.Errorf("the %s group's timeout = %s, want the run's", g.Stressor, g.Timeout)
+		}
+	}
+}
+
+// TestRunContextReportsEveryGroup runs a mix: a group each, in order, each with
+// its own workers and count, and the gc group's probe beside them.
+func TestRunContextReportsEveryGroup(t *testing.T) {
+	cfg := Cfg{Timeout: 200 * time.Millisecond, Mix: []Group{{"contention", 1}, {"gc", 1}}, Modes: []string{"padded"}, HeapTarget: 4 << 20}
+
+	r, err := cfg.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if len(r.Groups) != 2 {
+		t.Fatalf("Groups = %+v, want 2", r.Groups)
+	}
+
+	contention, gc := r.Groups[0], r.Groups[1]
+
+	if contention.Stressor != "contention" || len(contention.Variants) != 1 || contention.Variants[0].Name != "padded" {
+		t.Errorf("Groups[0] = %+v, want contention in the padded mode alone", contention)
+	}
+
+	if gc.Stressor != "gc" || gc.Workers != 1 || gc.Variants != nil {
+		t.Errorf("Groups[1] = %+v, want gc on its one worker", gc)
+	}
+
+	if r.Count != contention.Count+gc.Count {
+		t.Errorf("Count = %d, want the groups' %d and %d added", r.Count, contention.Count, gc.Count)
+	}
+
+	if r.GC == nil {
+		t.Error("GC = nil, want the gc group's probe")
+	}
+}
@@ -0,0 +1,223 @@
+package stress
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Observer is told what a run is doing as it does it, which is what a caller
+// that wants more than the Result at the end implements: a live dashboard, a
+// test that waits for the first progress report, a log in a format of its own.
+// The stressy command's lines are one, and are built from nothing these calls
+// do not carry.
+//
+// A run makes its calls one at a time and in order — OnStart, an OnProgress
+// every Report, OnShutdown and OnSummary, and those of PauseObserver,
+// BurstObserver and SegmentObserver where the Observer is one — from
+// goroutines of its own, so an Observer needs no locking against itself. A
+// call that blocks holds up the run's next one, and OnShutdown holds up the
+// drain's summary, but neither holds up a worker.
+//
+// The interface is the four calls every run makes, and stays that: an event
+// added since is an interface of its own, which a run looks for on its
+// Observer, so an Observer written against an earlier release still is one.
+type Observer interface {
+	// OnStart is called once every worker is running.
+	OnStart(StartEvent)
+
+	// OnProgress is called every Report while the run goes, and wherever
+	// Run.Report asks for one, with what it has done so far, as Snapshot has
+	// it.
+	OnProgress(Result)
+
+	// OnShutdown is called once the run has been told to stop, before the
+	// workers have drained.
+	OnShutdown(ShutdownEvent)
+
+	// OnSummary is called once they have, with what Wait returns.
+	OnSummary(Result)
+}
+
+// PauseObserver is an Observer that is also told of Pause and Resume: OnPause
+// once Run.Pause has held the workers, and OnResume once Run.Resume has let
+// them go on.
+type PauseObserver interface {
+	Observer
+	OnPause(PauseEvent)
+	OnResume(PauseEvent)
+}
+
+// BurstObserver is an Observer that is also told of a Burst's turns: OnBurst
+// each time the run turns off, once the workers are told to hold, and on
+// again, once they are let go.
+type BurstObserver interface {
+	Observer
+	OnBurst(BurstEvent)
+}
+
+// SegmentObserver is an Observer that is also told of a Shape's segments:
+// OnSegment as a run with a Shape or a Chaos starts each one, the first
+// included.
+type SegmentObserver interface {
+	Observer
+	OnSegment(SegmentEvent)
+}
+
+// StartEvent is what OnStart is told: the configuration the run started with,
+// its groups as they stand before any work is done, which is where the
+// variants each takes turns between are named, and the machine it runs on.
+type StartEvent struct {
+	Cfg    Cfg
+	Groups []GroupResult
+	Host   Host
+}
+
+// ShutdownEvent is what OnShutdown is told: why the run is stopping, and how
+// long it had run when it was told to.
+type ShutdownEvent struct {
+	Reason StopReason
+
+	// Cause is context.Cause of the context Start was given where that is what
+	// ended the run, and nil where the timeout did. A caller that cancels with
+	// a cause of its own — context.WithCancelCause — is handed it back here.
+	Cause error
+
+	Elapsed time.Duration
+}
+
+// PauseEvent is what OnPause and OnResume are told: how long the run had gone,
+// paused time and all, and for a resume how long the pause it ends was held.
+type PauseEvent struct {
+	Elapsed time.Duration
+	Held    time.Duration
+}
+
+// BurstEvent is what OnBurst is told: whether the run turned on or off, and
+// how long it had gone when it did.
+type BurstEvent struct {
+	On      bool
+	Elapsed time.Duration
+}
+
+// Report asks the Observer for a progress report now, between the ones every
+// Report makes, or where Report makes none. It does not wait for the report,
+// and does nothing without an Observer or once the run has been told to stop.
+func (r *Run) Report() {
+	r.told.Lock()
+	defer r.told.Unlock()
+
+	if r.ctx.Err() == nil {
+		r.tell(func(obs Observer) { obs.OnProgress(r.progress()) })
+	}
+}
+
+// tell asks watch to make a call to the Observer, after any asked for before it.
+// The caller holds told, which is what keeps the calls in the order asked.
+func (r *Run) tell(call func(Observer)) {
+	if r.obs == nil {
+		return
+	}
+
+	r.asked = append(r.asked, call)
+
+	// One nudge waiting is enough: watch makes every call asked for by the
+	// time it has one.
+	select {
+	case r.nudge <- struct{}{}:
+	default:
+	}
+}
+
+// answer makes the calls to the Observer asked for so far, in the order asked.
+func (r *Run) answer(obs Observer) {
+	r.told.Lock()
+	asked := r.asked
+	r.asked = nil
+	r.told.Unlock()
+
+	for _, call := range asked {
+		call(obs)
+	}
+}
+
+// watch is the goroutine that calls the Observer while the run goes: a progress
+// report on every tick, the calls Report, Pause and Resume ask for, and the
+// shutdown once the run's context is done. It is the one that makes every call
+// so that one still running has returned before the next is made.
+func (r *Run) watch(obs Observer, every time.Duration) {
+	// nil where Report is off, and a receive from a nil channel blocks forever,
+	// so a run without it waits on its context alone.
+	var tick <-chan time.Time
+
+	if every > 0 {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			// Asked for before the run was told to stop, and so owed before
+			// the shutdown.
+			r.answer(obs)
+
+			reason, cause := r.reason()
+			obs.OnShutdown(ShutdownEvent{Reason: reason, Cause: cause, Elapsed: time.Since(r.began)})
+
+			return
+		case <-tick:
+			// Measured when the report is made rather than at the time the
+			// tick carries: a late tick carries the time it fired, which is
+			// the elapsed time the report would have had if the process were
+			// healthy — hiding exactly the pathology a caller asks for
+			// reports to see.
+			obs.OnProgress(r.progress())
+		case <-r.nudge:
+			r.answer(obs)
+		}
+	}
+}
+
+// progress is the Result a progress report is given: a Snapshot, with what is
+// measured since the report before put against that report's figures. Only
+// watch calls it, which is what makes reported its alone.
+func (r *Run) progress() Result {
+	res := r.Snapshot()
+
+	if res.Steal != nil {
+		res.Steal.since(r.reported)
+
+		reported := *res.Steal
+		r.reported = &reported
+	}
+
+	return res
+}
+
+// reason is why the run's context is done, and the cause to report with it: the
+// timeout, the Count, or the context Start was given.
+func (r *Run) reason() (StopReason, error) {
+	cause := context.Cause(r.ctx)
+
+	switch cause {
+	case errTimeout:
+		return StopTimeout, nil
+	case errCount:
+		return StopCount, nil
+	}
+
+	var hot *TempError
+	if errors.As(cause, &hot) {
+		return StopTemp, hot
+	}
+
+	var stalled *StallError
+	if errors.As(cause, &stalled) {
+		return StopStall, stalled
+	}
+
+	return StopCanceled, cause
+}
@@ -0,0 +1,224 @@
+package stress
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is an Observer that keeps the name of every call, in the order the
+// run made them, and the events that carry something to check.
+type recorder struct {
+	mu       sync.Mutex
+	calls    []string
+	start    StartEvent
+	resumed  PauseEvent
+	bursts   []BurstEvent
+	segments []SegmentEvent
+	shutdown ShutdownEvent
+	summary  Result
+}
+
+func (o *recorder) OnStart(ev StartEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "start")
+	o.start = ev
+}
+
+func (o *recorder) OnProgress(Result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// One name for any number of reports, so the order reads the same however
+	// many ticks a loaded runner fits in.
+	if o.calls[len(o.calls)-1] != "progress" {
+		o.calls = append(o.calls, "progress")
+	}
+}
+
+func (o *recorder) OnPause(PauseEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "pause")
+}
+
+func (o *recorder) OnResume(ev PauseEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "resume")
+	o.resumed = ev
+}
+
+func (o *recorder) OnBurst(ev BurstEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "burst")
+	o.bursts = append(o.bursts, ev)
+}
+
+func (o *recorder) OnSegment(ev SegmentEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "segment")
+	o.segments = append(o.segments, ev)
+}
+
+func (o *recorder) OnShutdown(ev ShutdownEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "shutdown")
+	o.shutdown = ev
+}
+
+func (o *recorder) OnSummary(r Result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, "summary")
+	o.summary = r
+}
+
+// TestObserverIsToldInOrder covers the calls a run makes, for each of the ways
+// it can stop: every one once but the progress reports, in the order the
+// interface promises, and the shutdown carrying the cause a caller cancelled
+// with.
+func TestObserverIsToldInOrder(t *testing.T) {
+	errDone := errors.New("done with it")
+
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		cancel     error
+		wantReason StopReason
+		wantCause  error
+	}{
+		{name: "the timeout", timeout: 150 * time.Millisecond, wantReason: StopTimeout},
+		{name: "a cause of the caller's", cancel: errDone, wantReason: StopCanceled, wantCause: errDone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+
+			if tt.cancel != nil {
+				time.AfterFunc(150*time.Millisecond, func() { cancel(tt.cancel) })
+			}
+
+			obs := &recorder{}
+
+			r, err := Cfg{Workers: 2, Timeout: tt.timeout, Report: 20 * time.Millisecond, Observer: obs}.RunContext(ctx)
+			if err != nil {
+				t.Fatalf("RunContext() error = %v, want nil", err)
+			}
+
+			want := []string{"start", "progress", "shutdown", "summary"}
+			if len(obs.calls) != len(want) {
+				t.Fatalf("the run made calls %v, want %v", obs.calls, want)
+			}
+
+			for i := range want {
+				if obs.calls[i] != want[i] {
+					t.Fatalf("the run made calls %v, want %v", obs.calls, want)
+				}
+			}
+
+			if len(obs.start.Groups) != 1 || obs.start.Groups[0].Workers != 2 || obs.start.Cfg.Observer != obs {
+				t.Errorf("StartEvent = %+v, want the run's one group of 2 workers and its Cfg", obs.start)
+			}
+
+			if ev := obs.shutdown; ev.Reason != tt.wantReason || ev.Cause != tt.wantCause || ev.Elapsed <= 0 {
+				t.Errorf("ShutdownEvent = %+v, want Reason %s, Cause %v and the time run", ev, tt.wantReason, tt.wantCause)
+			}
+
+			if obs.summary.Reason != r.Reason || obs.summary.Count != r.Count {
+				t.Errorf("OnSummary() got %+v, want what RunContext returned, %+v", obs.summary, r)
+			}
+		})
+	}
+}
+
+// fourCalls is an Observer of the four calls every run makes and nothing more,
+// as one written before the others were added is.
+type fourCalls struct {
+	rec *recorder
+}
+
+func (o fourCalls) OnStart(ev StartEvent)       { o.rec.OnStart(ev) }
+func (o fourCalls) OnProgress(r Result)         { o.rec.OnProgress(r) }
+func (o fourCalls) OnShutdown(ev ShutdownEvent) { o.rec.OnShutdown(ev) }
+func (o fourCalls) OnSummary(r Result)          { o.rec.OnSummary(r) }
+
+// TestObserverOfTheFourCalls: a run in bursts, a shaped one and a paused one
+// each tell an Observer that takes none of their events what it does take, and
+// nothing else.
+func TestObserverOfTheFourCalls(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Cfg
+		pause bool
+	}{
+		{name: "bursts", cfg: Cfg{Burst: Burst{On: 50 * time.Millisecond, Off: 50 * time.Millisecond}}},
+		{name: "a shape", cfg: Cfg{Shape: []Segment{{Length: 80 * time.Millisecond, Workers: 1, Duty: 1}, {Length: 80 * time.Millisecond, Workers: 1, Duty: 0.5}}}},
+		{name: "a pause", pause: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+
+			cfg := tt.cfg
+			cfg.Workers, cfg.Stressor, cfg.Modes, cfg.Observer = 1, "contention", []string{"atomic"}, fourCalls{rec}
+			if len(cfg.Shape) == 0 {
+				cfg.Timeout = 250 * time.Millisecond
+			}
+
+			run, err := cfg.Start(context.Background())
+			if err != nil {
+				t.Fatalf("Start() error = %v, want nil", err)
+			}
+
+			if tt.pause {
+				time.Sleep(50 * time.Millisecond)
+
+				if !run.Pause() {
+					t.Fatal("Pause() = false on a running run, want true")
+				}
+
+				time.Sleep(50 * time.Millisecond)
+
+				if !run.Resume() {
+					t.Fatal("Resume() = false on a paused run, want true")
+				}
+			}
+
+			r := run.Wait()
+			if r.Reason != StopTimeout {
+				t.Errorf("Reason = %s, want %s", r.Reason, StopTimeout)
+			}
+
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+
+			if n := len(rec.calls); n < 3 || rec.calls[0] != "start" || rec.calls[n-2] != "shutdown" || rec.calls[n-1] != "summary" {
+				t.Errorf("the run made calls %v, want start, any progress, shutdown and summary", rec.calls)
+			}
+
+			for _, c := range rec.calls[1 : len(rec.calls)-2] {
+				if c != "progress" {
+					t.Errorf("the run made calls %v, want none but the four", rec.calls)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package stress
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pause is what holds a run's workers between the units they repeat while the
+// run is paused, and keeps the time it has been, which every rate the run
+// reports leaves out.
+type pause struct {
+	// resume is nil while the run goes, and while it is paused a channel that
+	// Resume closes. A pointer read on every unit rather than a lock taken on
+	// one: the contention stressor measures exactly the cost of a line every
+	// worker writes to, and a mutex here would be one.
+	resume atomic.Pointer[chan struct{}]
+
+	mu    sync.Mutex
+	since time.Time     // when the pause under way began
+	total time.Duration // every pause that has ended, added up
+}
+
+// wait blocks while the run is paused, until it resumes or ctx is done, and
+// reports whether it was ctx: the worker's cue to stop rather than go on.
+func (p *pause) wait(ctx context.Context) bool {
+	ch := p.resume.Load()
+	if ch == nil {
+		return false
+	}
+
+	select {
+	case <-*ch:
+		return false
+	case <-ctx.Done():
+		return true
+	}
+}
+
+// begin pauses the run at now, and reports false where it already was.
+func (p *pause) begin(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resume.Load() != nil {
+		return false
+	}
+
+	ch := make(chan struct{})
+	p.resume.Store(&ch)
+	p.since = now
+
+	return true
+}
+
+// end resumes the run at now, and returns how long the pause lasted; false
+// where it was not paused.
+func (p *pause) end(now time.Time) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := p.resume.Load()
+	if ch == nil {
+		return 0, false
+	}
+
+	held := now.Sub(p.since)
+	p.total += held
+
+	p.resume.Store(nil)
+	close(*ch)
+
+	return held, true
+}
+
+// paused is the time the run has spent paused as of now, the pause under way
+// included.
+func (p *pause) paused(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resume.Load() == nil {
+		return p.total
+	}
+
+	return p.total + now.Sub(p.since)
+}
+
+// Pause holds every worker once it has finished the unit it is on, until Resume
+// — for a look at the machine without the load on it, and without giving up
+// the run. The time paused counts toward Timeout, which is the wall clock's, and
+// toward no rate: a Result's rates divide by Elapsed less Paused. The unit each
+// worker is on when the run pauses is counted, and the time it takes to finish
+// is paused time, so a rate across a pause is high by up to a unit a worker.
+//
+// It reports false, and does nothing, where the run is paused already or has
+// been told to stop. Where the Observer is a PauseObserver, it is told with
+// OnPause.
+func (r *Run) Pause() bool {
+	r.told.Lock()
+	defer r.told.Unlock()
+
+	now := time.Now()
+
+	if r.ctx.Err() != nil || !r.paused.begin(now) {
+		return false
+	}
+
+	ev := PauseEvent{Elapsed: now.Sub(r.began)}
+	r.tell(func(obs Observer) {
+		if o, ok := obs.(PauseObserver); ok {
+			o.OnPause(ev)
+		}
+	})
+
+	return true
+}
+
+// Resume lets the workers go on after Pause. It reports false, and does
+// nothing, where the run is not paused or has been told to stop; a run told to
+// stop while paused stops without resuming. Where the Observer is a
+// PauseObserver, it is told with OnResume.
+func (r *Run) Resume() bool {
+	r.told.Lock()
+	defer r.told.Unlock()
+
+	if r.ctx.Err() != nil {
+		return false
+	}
+
+	now := time.Now()
+
+	held, ok := r.paused.end(now)
+	if !ok {
+		return false
+	}
+
+	ev := PauseEvent{Elapsed: now.Sub(r.began), Held: held}
+	r.tell(func(obs Observer) {
+		if o, ok := obs.(PauseObserver); ok {
+			o.OnResume(ev)
+		}
+	})
+
+	return true
+}
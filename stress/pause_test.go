@@ -0,0 +1,119 @@
+package stress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestPauseHoldsTheWorkers covers a pause from end to end: nothing counted while
+// it holds, the time it held left out of the rate, and the Observer told of it
+// in order with a report asked for in between.
+func TestPauseHoldsTheWorkers(t *testing.T) {
+	const hold = 150 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obs := &recorder{}
+
+	// atomic, for a unit short enough that the one in flight at the pause is
+	// over long before the hold is.
+	run, err := Cfg{Workers: 2, Stressor: "contention", Modes: []string{"atomic"}, Observer: obs}.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !run.Pause() {
+		t.Fatal("Pause() = false on a running run, want true")
+	}
+
+	if run.Pause() {
+		t.Error("Pause() = true on a paused run, want false")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	held := run.Snapshot()
+
+	time.Sleep(hold)
+
+	if s := run.Snapshot(); s.Count != held.Count || s.Paused < hold {
+		t.Errorf("Snapshot() over a pause of %s went from %d to %d, Paused %s; want no count and the time held", hold, held.Count, s.Count, s.Paused)
+	}
+
+	run.Report()
+
+	if !run.Resume() {
+		t.Fatal("Resume() = false on a paused run, want true")
+	}
+
+	if run.Resume() {
+		t.Error("Resume() = true on a running run, want false")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if s := run.Snapshot(); s.Count == held.Count {
+		t.Errorf("Snapshot().Count = %d after Resume(), want the workers counting again", s.Count)
+	}
+
+	cancel()
+	r := run.Wait()
+
+	if run.Pause() {
+		t.Error("Pause() = true on a stopped run, want false")
+	}
+
+	if r.Paused < hold+20*time.Millisecond || r.Paused >= r.Elapsed {
+		t.Errorf("Paused = %s of %s, want the %s held", r.Paused, r.Elapsed, hold+20*time.Millisecond)
+	}
+
+	if want := rate(r.Count, r.Elapsed-r.Paused); r.Rate != want {
+		t.Errorf("Rate = %.1f, want %.1f: the count over the time not paused", r.Rate, want)
+	}
+
+	want := []string{"start", "pause", "progress", "resume", "shutdown", "summary"}
+	if len(obs.calls) != len(want) {
+		t.Fatalf("the run made calls %v, want %v", obs.calls, want)
+	}
+
+	for i := range want {
+		if obs.calls[i] != want[i] {
+			t.Fatalf("the run made calls %v, want %v", obs.calls, want)
+		}
+	}
+
+	if obs.resumed.Held < hold || obs.resumed.Elapsed < obs.resumed.Held {
+		t.Errorf("OnResume() got %+v, want the pause held at least %s", obs.resumed, hold)
+	}
+}
+
+// TestAPausedRunStops: a run paused when it is told to stop drains and returns
+// rather than waiting on a Resume nobody is going to make.
+func TestAPausedRunStops(t *testing.T) {
+	run, err := Cfg{Workers: 2, Timeout: 50 * time.Millisecond, Stressor: "contention"}.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+
+	run.Pause()
+
+	done := make(chan Result, 1)
+	go func() { done <- run.Wait() }()
+
+	select {
+	case r := <-done:
+		if r.Reason != StopTimeout {
+			t.Errorf("Reason = %s, want %s", r.Reason, StopTimeout)
+		}
+	case <-time.After(stopBudget):
+		t.Fatalf("Wait() did not return within %s of a paused run's timeout", stopBudget)
+	}
+
+	if run.Resume() {
+		t.Error("Resume() = true on a stopped run, want false")
+	}
+}
@@ -0,0 +1,147 @@
+package stress
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PressureStats is the machine's CPU pressure, as the kernel's pressure stall
+// information has it: the share of the time something runnable was kept
+// waiting for a CPU, by anything, the run's workers among them. A run's own
+// workers past the cores keep each other waiting, so pressure is the figure for
+// whatever else the machine runs, against a run that fits.
+//
+// Some is that share over the run, from the kernel's total of the time stalled
+// at either end of it. Avg10, Avg60 and Avg300 are the kernel's own averages,
+// in percent, over the 10s, 60s and 300s up to the last read, which for a run
+// shorter than them take in the time before it.
+type PressureStats struct {
+	Some                 float64
+	Avg10, Avg60, Avg300 float64
+}
+
+// pressureProbe reads /proc/pressure/cpu at the start of a run and as it goes.
+// It is every run's on a kernel that keeps one.
+type pressureProbe struct {
+	path string
+
+	// then is the kernel's at start, and end the one at stop, which is what
+	// read reports from once stopped is set.
+	mu        sync.Mutex
+	then, end cpuPressure
+	stopped   bool
+}
+
+// cpuPressure is the "some" line of /proc/pressure/cpu: the averages in
+// percent, and the total time stalled since boot.
+type cpuPressure struct {
+	avg10, avg60, avg300 float64
+	total                time.Duration
+}
+
+// newPressureProbe is the probe of /proc/pressure/cpu under root, or nil where
+// there is none: a kernel before 4.20, or one booted without psi.
+func newPressureProbe(root string) probe {
+	path := filepath.Join(root, "proc/pressure/cpu")
+	if _, ok := readPressure(path); !ok {
+		return nil
+	}
+
+	return &pressureProbe{path: path}
+}
+
+func (p *pressureProbe) start() { p.then, _ = readPressure(p.path) }
+
+func (p *pressureProbe) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end, _ = readPressure(p.path)
+	p.stopped = true
+}
+
+func (p *pressureProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now, ok := p.end, true
+	if !p.stopped {
+		now, ok = readPressure(p.path)
+	}
+
+	if !ok || now.total < p.then.total {
+		return
+	}
+
+	s := PressureStats{Avg10: now.avg10, Avg60: now.avg60, Avg300: now.avg300}
+
+	// Over the whole of the run's time, paused or not: the machine was
+	// stalled or it was not, whatever the workers were doing.
+	if r.Elapsed > 0 {
+		s.Some = min(100*(now.total-p.then.total).Seconds()/r.Elapsed.Seconds(), 100)
+	}
+
+	r.Pressure = &s
+}
+
+// readPressure reads the "some" line of a PSI file, which reads, in full:
+//
+//	some avg10=1.53 avg60=0.87 avg300=0.22 total=2513735
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//
+// with the total in microseconds. The "full" line, which a kernel since 5.13
+// writes for the CPU as well, is always 0 for the machine as a whole, and is
+// not read.
+func readPressure(path string) (cpuPressure, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return cpuPressure{}, false
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+
+		var (
+			p     cpuPressure
+			found int
+		)
+
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return cpuPressure{}, false
+			}
+
+			switch key {
+			case "avg10":
+				p.avg10 = n
+			case "avg60":
+				p.avg60 = n
+			case "avg300":
+				p.avg300 = n
+			case "total":
+				p.total = time.Duration(n) * time.Microsecond
+			default:
+				continue
+			}
+
+			found++
+		}
+
+		return p, found == 4
+	}
+
+	return cpuPressure{}, false
+}
@@ -0,0 +1,76 @@
+package stress
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadPressure(t *testing.T) {
+	tests := []struct {
+		name string
+		psi  string
+		want cpuPressure
+		ok   bool
+	}{
+		{
+			name: "some and full",
+			psi:  "some avg10=1.53 avg60=0.87 avg300=0.22 total=2513735\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+			want: cpuPressure{avg10: 1.53, avg60: 0.87, avg300: 0.22, total: 2513735 * time.Microsecond},
+			ok:   true,
+		},
+		{
+			name: "some alone, as before 5.13",
+			psi:  "some avg10=0.00 avg60=0.00 avg300=0.00 total=12\n",
+			want: cpuPressure{total: 12 * time.Microsecond},
+			ok:   true,
+		},
+		{name: "full alone", psi: "full avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"},
+		{name: "a field short", psi: "some avg10=0.00 avg60=0.00 total=12\n"},
+		{name: "not a number", psi: "some avg10=high avg60=0.00 avg300=0.00 total=12\n"},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, map[string]string{"cpu": tt.psi})
+
+			got, ok := readPressure(filepath.Join(root, "cpu"))
+			if ok != tt.ok || ok && got != tt.want {
+				t.Errorf("readPressure() = %+v, %t; want %+v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestPressureProbeRead covers the share of the run stalled, from the totals
+// at either end over the run's time, beside the kernel's averages at the end.
+func TestPressureProbeRead(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"proc/pressure/cpu": "some avg10=0.00 avg60=0.00 avg300=0.00 total=1000000\n"})
+
+	p := newPressureProbe(root)
+	if p == nil {
+		t.Fatal("newPressureProbe() = nil, want the probe of the tree's file")
+	}
+
+	p.start()
+
+	// 500ms stalled over a 2s run.
+	writeTree(t, root, map[string]string{"proc/pressure/cpu": "some avg10=24.10 avg60=6.20 avg300=1.30 total=1500000\n"})
+
+	p.stop()
+
+	r := Result{Elapsed: 2 * time.Second}
+	p.read(&r)
+
+	want := PressureStats{Some: 25, Avg10: 24.1, Avg60: 6.2, Avg300: 1.3}
+	if r.Pressure == nil || *r.Pressure != want {
+		t.Errorf("read() = %+v, want %+v", r.Pressure, want)
+	}
+
+	if newPressureProbe(t.TempDir()) != nil {
+		t.Error("newPressureProbe() of a tree without the file is a probe, want nil")
+	}
+}
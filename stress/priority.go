@@ -0,0 +1,86 @@
+package stress
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+)
+
+// scheds are the scheduling policies a Cfg's Sched may name, each Linux's
+// SCHED_ policy of the same name: other is the default time-sharing one,
+// batch the same for work that is never waited on interactively, and idle
+// runs only when nothing else on the CPU wants to.
+var scheds = []string{"other", "batch", "idle"}
+
+// Scheds lists the scheduling policies a Cfg's Sched may name.
+func Scheds() []string { return slices.Clone(scheds) }
+
+// validatePriority reports whether Nice and Sched are a priority the workers'
+// threads can be given here.
+func (c Cfg) validatePriority() error {
+	if c.Nice == 0 && c.Sched == "" {
+		return nil
+	}
+
+	switch {
+	// Linux's per-thread nice and policy are what put the workers, and not
+	// the watcher or the latency probe, where the caller asked; another OS
+	// would take the whole process with them.
+	case !havePriority:
+		return fmt.Errorf("nice and sched set the workers' threads' priority on Linux, and this is %s", runtime.GOOS)
+	case c.Nice < -20 || c.Nice > 19:
+		return fmt.Errorf("nice must be from -20 to 19")
+	case c.Sched != "" && !slices.Contains(scheds, c.Sched):
+		return fmt.Errorf("sched must be one of other, batch, idle")
+	}
+
+	return nil
+}
+
+// priority is Nice and Sched as a worker's thread is given them.
+type priority struct {
+	nice  int
+	sched string
+}
+
+// set reports whether p changes anything, and so whether a worker needs a
+// thread of its own to take it.
+func (p priority) set() bool { return p.nice != 0 || p.sched != "" }
+
+// lock pins the calling goroutine to its thread and gives the thread p, where
+// p changes anything. The goroutine never unlocks, so the thread ends with it
+// rather than going back to the runtime's pool carrying p to other work.
+func (p priority) lock() error {
+	if !p.set() {
+		return nil
+	}
+
+	runtime.LockOSThread()
+
+	return p.apply()
+}
+
+// check gives p to a thread of its own and lets the thread go, and reports
+// what the OS said: a run that cannot have the priority it asked for fails at
+// the start rather than in every worker. The usual refusal is a nice below 0
+// without CAP_SYS_NICE.
+func (p priority) check() error {
+	errs := make(chan error, 1)
+
+	go func() { errs <- p.lock() }()
+
+	if err := <-errs; err != nil {
+		return fmt.Errorf("nice %d, sched %s: %w", p.nice, p.schedName(), err)
+	}
+
+	return nil
+}
+
+// schedName is the policy p asks for, as a Cfg names one, or "unchanged".
+func (p priority) schedName() string {
+	if p.sched == "" {
+		return "unchanged"
+	}
+
+	return p.sched
+}
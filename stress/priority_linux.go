@@ -0,0 +1,38 @@
+package stress
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// havePriority is true where a thread's nice and scheduling policy are its
+// own, which on Linux they are.
+const havePriority = true
+
+// schedPolicies are the SCHED_ constants from linux/sched.h, which the
+// syscall package does not have.
+var schedPolicies = map[string]int{"other": 0, "batch": 3, "idle": 5}
+
+// apply gives the calling thread p: the policy first, because the nice a
+// SCHED_OTHER or SCHED_BATCH thread runs at is kept across a change of policy,
+// then the nice. setpriority with PRIO_PROCESS and a thread id sets that
+// thread's alone, which is Linux's and what the workers want.
+func (p priority) apply() error {
+	tid := syscall.Gettid()
+
+	if p.sched != "" {
+		// The static priority, which every policy here takes as 0.
+		var param struct{ priority int32 }
+
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETSCHEDULER, uintptr(tid), uintptr(schedPolicies[p.sched]), uintptr(unsafe.Pointer(&param)))
+		if errno != 0 {
+			return errno
+		}
+	}
+
+	if p.nice != 0 {
+		return syscall.Setpriority(syscall.PRIO_PROCESS, tid, p.nice)
+	}
+
+	return nil
+}
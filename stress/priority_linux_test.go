@@ -0,0 +1,80 @@
+package stress
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestPriorityIsTheThreadsAlone gives a thread nice 10 under SCHED_BATCH and
+// reads both back, from that thread and from the test's, which is left as it
+// was.
+func TestPriorityIsTheThreadsAlone(t *testing.T) {
+	type read struct {
+		nice, policy int
+		err          error
+	}
+
+	// Read from the calling thread: getpriority answers 20 - nice, to keep its
+	// return clear of the error range.
+	readThread := func() read {
+		tid := syscall.Gettid()
+
+		prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, tid)
+		if err != nil {
+			return read{err: err}
+		}
+
+		policy, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETSCHEDULER, uintptr(tid), 0, 0)
+		if errno != 0 {
+			return read{err: errno}
+		}
+
+		return read{nice: 20 - prio, policy: int(policy)}
+	}
+
+	before := readThread()
+
+	reads := make(chan read, 1)
+
+	go func() {
+		if err := (priority{nice: 10, sched: "batch"}).lock(); err != nil {
+			reads <- read{err: err}
+
+			return
+		}
+
+		reads <- readThread()
+	}()
+
+	got := <-reads
+	if got.err != nil {
+		t.Fatalf("lock() error = %v, want nil", got.err)
+	}
+
+	if got.nice != 10 || got.policy != schedPolicies["batch"] {
+		t.Errorf("the locked thread runs at nice %d, policy %d; want 10, %d", got.nice, got.policy, schedPolicies["batch"])
+	}
+
+	if after := readThread(); after != before {
+		t.Errorf("the test's thread went from %+v to %+v, want it left as it was", before, after)
+	}
+}
+
+// TestStartRefusesAPriorityTheOSDoes asks for nice -5 without the privilege,
+// and wants the refusal from Start rather than silence from every worker.
+func TestStartRefusesAPriorityTheOSDoes(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root may raise a thread's priority, so nothing is refused")
+	}
+
+	_, err := Cfg{Workers: 1, Nice: -5}.Start(t.Context())
+	if err == nil {
+		t.Fatal("Start() error = nil, want the OS's refusal")
+	}
+
+	if want := "nice -5, sched unchanged: "; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Start() error = %q, want one starting %q", err, want)
+	}
+}
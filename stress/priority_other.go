@@ -0,0 +1,10 @@
+//go:build !linux
+
+package stress
+
+// havePriority is false where nice and the scheduling policy are the
+// process's rather than a thread's, or are not there at all.
+const havePriority = false
+
+// apply is never called here; Validate turns away a Cfg that would.
+func (p priority) apply() error { return nil }
@@ -0,0 +1,44 @@
+package stress
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestValidatePriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		nice    int
+		sched   string
+		wantErr string
+	}{
+		{name: "unset"},
+		{name: "the lowest priority", nice: 19, sched: "idle"},
+		{name: "the highest nice", nice: -20},
+		{name: "batch alone", sched: "batch"},
+		{name: "nice past 19", nice: 20, wantErr: "nice must be from -20 to 19"},
+		{name: "nice under -20", nice: -21, wantErr: "nice must be from -20 to 19"},
+		// SCHED_FIFO and SCHED_RR would take a core from the kernel's own
+		// threads, which is no load test.
+		{name: "a real-time policy", sched: "fifo", wantErr: "sched must be one of other, batch, idle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.wantErr
+			if !havePriority && (tt.nice != 0 || tt.sched != "") {
+				want = "nice and sched set the workers' threads' priority on Linux, and this is " + runtime.GOOS
+			}
+
+			err := Cfg{Workers: 1, Timeout: time.Second, Nice: tt.nice, Sched: tt.sched}.Validate()
+
+			switch {
+			case want == "" && err != nil:
+				t.Errorf("Validate() error = %v, want nil", err)
+			case want != "" && (err == nil || err.Error() != want):
+				t.Errorf("Validate() error = %v, want %q", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package stress
+
+import "time"
+
+// Result is what a run did: a count and a rate for the run, for each group of
+// workers in it and for each variant a group took turns between, and what the
+// run's probes measured beside them. It is what Wait returns once a run is over
+// and what Snapshot returns while it goes.
+//
+// Counts are in the stressor's unit: hashes for bcrypt, which is every run that
+// names no stressor, and Stressors says what the others count.
+type Result struct {
+	// Reason is why the run ended, and StopNone in a Snapshot.
+	Reason StopReason
+
+	// Elapsed is the time from the workers starting to the last of them
+	// draining, which is longer than the timeout by the drain. Paused is how
+	// much of it the run spent paused, which no rate counts.
+	Elapsed time.Duration
+	Paused  time.Duration
+
+	// Count is every unit the run did, and Rate is Count a second over
+	// Elapsed less Paused. For a mix they add up every group's, which is a
+	// figure only where the groups count the same unit; Groups has each on its
+	// own.
+	Count uint64
+	Rate  float64
+
+	// Groups has one entry for each group of workers: the run's one stressor,
+	// or each entry of the Mix in order.
+	Groups []GroupResult
+
+	// GC is what the collector did over a run with a gc group, and nil for any
+	// other. Latency is what LatencyProbe measured, and nil without one;
+	// Dispatch is what TargetRate's schedule saw, and nil without one; Burst
+	// is the on periods of a run with a Burst, and nil for a steady one.
+	GC       *GCStats
+	Latency  *LatencyStats
+	Dispatch *DispatchStats
+	Burst    *BurstStats
+
+	// CPU is the CPU time the process was given over the run, and nil where
+	// the OS does not say.
+	CPU *CPUStats
+
+	// Cgroup is what the process's cgroup's CPU quota held it to over the
+	// run, and nil where there is no cpu.stat to read. Pressure is the
+	// machine's CPU pressure, and nil where the kernel keeps none. Steal is
+	// the machine's CPU time a hypervisor or I/O took, and nil where there is
+	// no /proc/stat.
+	Cgroup   *CgroupStats
+	Pressure *PressureStats
+	Steal    *StealStats
+
+	// Thermal is what the CPUs were clocked at and how hot the machine got,
+	// sampled at every read, and nil where it has neither cpufreq nor
+	// thermal zones.
+	Thermal *ThermalStats
+
+	// Energy is what the CPU packages drew over the run, and nil where there
+	// is no RAPL counter to read.
+	Energy *EnergyStats
+
+	// Host is the machine the run ran on, the same in every Result of the
+	// run, so that one read beside another, a node's beside the next, says
+	// what each was.
+	Host Host
+
+	// Shape has an entry for each segment of the Shape begun, in order, or of
+	// the one a Chaos drew, and none for a run without either.
+	Shape []SegmentResult
+}
+
+// GroupResult is what one group of workers did.
+type GroupResult struct {
+	Stressor string // its name, as Stressors has it
+	Workers  int
+
+	// Count is every unit the group did, and Rate is Count a second over the
+	// run's Elapsed less its Paused.
+	Count uint64
+	Rate  float64
+
+	// Variants has an entry for each mode or working set the group took turns
+	// between, in the order it took them, and none for a stressor with no
+	// variants.
+	Variants []VariantResult
+}
+
+// VariantResult is what one variant of a stressor did in the phases it had the
+// workers.
+type VariantResult struct {
+	Name string // the mode, or the working set as a size such as 32KiB
+
+	// Count is every unit done in the variant's phases. Spent is how long
+	// those phases ran, less any time paused in them, and Rate is Count a
+	// second over Spent rather than over the run, which is what makes two
+	// variants comparable however the phases fell.
+	Count uint64
+	Spent time.Duration
+	Rate  float64
+}
+
+// GCStats is what the collector did over a run: the difference between the
+// runtime's readings at either end of it, and the heap's peak from sampling it
+// in between.
+type GCStats struct {
+	Cycles uint64
+
+	// PauseTotal is the time the program spent stopped for the collector, and
+	// PauseMax is a bound on the longest single pause. The runtime keeps a
+	// histogram of pauses rather than their durations, so both are read off its
+	// buckets, which are a few percent wide.
+	PauseTotal time.Duration
+	PauseMax   time.Duration
+
+	// HeapPeak is the most bytes the heap held in objects at any sample.
+	HeapPeak uint64
+}
+
+// LatencyStats is what LatencyProbe measured: how late each wakeup of a
+// goroutine sleeping Interval at a time was.
+type LatencyStats struct {
+	Interval time.Duration
+	Locked   bool // whether the probe had an OS thread of its own
+
+	// Wakeups is how many there were. Min, Avg and Max are exact; P99 is read
+	// off a histogram, and is an upper bound within a sixteenth of itself. All
+	// four are 0 where there were no wakeups.
+	Wakeups            uint64
+	Min, Avg, P99, Max time.Duration
+}
+
+// DispatchStats is what TargetRate's schedule saw: the slots it issued, the
+// units started for them, and how long after its slot each one started. A
+// backlog that grows and stays is a node that cannot keep up with the rate.
+type DispatchStats struct {
+	Target float64 // slots a second, as TargetRate gave it
+
+	// Issued is the slots that have come, Started the ones a worker took, and
+	// Backlog the rest. MaxBacklog is the most there were at any unit's start.
+	Issued, Started     uint64
+	Backlog, MaxBacklog uint64
+
+	// Min, Avg and Max of the delay between a slot and its unit's start are
+	// exact; P99 is read off a histogram, as LatencyStats' is. All four are 0
+	// before the first unit starts.
+	Min, Avg, P99, Max time.Duration
+}
+
+// BurstStats is what a run with a Burst did while it was on. Set against the
+// run's own Rate, which is over the off periods too, OnRate is what the load
+// was while it was there.
+type BurstStats struct {
+	Burst Burst // as the Cfg gave it
+
+	// Periods is the on periods begun, the first at the start of the run.
+	// OnTime is the time they ran, less any of it the run was paused, and
+	// OnRate is the run's Count a second over it: every unit is done on, but
+	// for those a worker was finishing as an off period began.
+	Periods int
+	OnTime  time.Duration
+	OnRate  float64
+}
+
+// StopReason is why a run ended.
+type StopReason int
+
+const (
+	// StopNone is the Reason of a run still going: a Snapshot's.
+	StopNone StopReason = iota
+
+	// StopTimeout is a run that served the whole of its Timeout.
+	StopTimeout
+
+	// StopCanceled is a run that ended because the context it was started
+	// with was done — a signal, to the stressy command.
+	StopCanceled
+
+	// StopCount is a run that did the whole of its Count. Its Elapsed is the
+	// time the work took, the drain included.
+	StopCount
+
+	// StopTemp is a run that ended because a thermal zone reached its
+	// MaxTemp. The cause OnShutdown is told is a *TempError that says which.
+	StopTemp
+
+	// StopStall is a run that ended because a worker was on one unit for
+	// longer than its StallTimeout. The cause OnShutdown is told is a
+	// *StallError that says which.
+	StopStall
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopNone:
+		return "running"
+	case StopTimeout:
+		return "timeout"
+	case StopCanceled:
+		return "canceled"
+	case StopCount:
+		return "count"
+	case StopTemp:
+		return "temperature"
+	case StopStall:
+		return "stall"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,400 @@
+package stress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Segment is one stretch of a shaped run: for Length, Workers of the run's
+// workers go on and the rest are held, and each of those spends Duty of its
+// time in a unit, from 0 for none of it to 1 for all. Cores is the load that
+// comes to.
+//
+// The duty cycle is kept over the segment rather than within any window of it,
+// because a unit cannot be cut short: a worker at Duty 0.5 that has been in a
+// unit for 180ms, a bcrypt hash, rests for 180ms before its next. A unit longer
+// than the segment makes the segment's load lumpier, not wrong.
+type Segment struct {
+	Length  time.Duration
+	Workers int
+	Duty    float64
+}
+
+// Cores is the load the segment asks for, in workers kept busy: 2.5 is two of
+// them the whole time and a third half of it, or five half of it.
+func (s Segment) Cores() float64 { return float64(s.Workers) * s.Duty }
+
+// SegmentResult is what one segment of a shaped run did: the segment as the
+// Cfg gave it, when it began and how long it ran, and the load it came to.
+type SegmentResult struct {
+	Segment
+
+	// Start is how far into the run the segment began. Ran is how long it
+	// lasted, less any time the run was paused in it; for the last segment of
+	// a Result, that is up to the Result, the drain included.
+	Start time.Duration
+	Ran   time.Duration
+
+	// Busy is the wall-clock time the workers spent in units during the
+	// segment, added up, and Offered is Busy over Ran, in cores as Cores is. A
+	// unit that runs on from one segment into the next counts in each for its
+	// time there, so a long bcrypt hash is not all its own segment's load.
+	//
+	// Neither is CPU time: a worker the machine had no core for counts as busy
+	// while it waited for one, so Offered is the load the run put on the
+	// machine, and what the OS gave the process of it is Result.CPU's to say.
+	Busy    time.Duration
+	Offered float64
+
+	// Count is the units started in the segment, a unit that ran on past it
+	// included.
+	Count uint64
+}
+
+// SegmentEvent is what OnSegment is told: which segment the run has gone on
+// to, counting from 0, of how many, how long it had gone when it did, and the
+// segment. Segments is the Shape's length, or for a Chaos the number it drew.
+type SegmentEvent struct {
+	Index    int
+	Segments int
+	Elapsed  time.Duration
+	Segment  Segment
+}
+
+// shapeLength is how long the Shape lasts, and 0 for a run without one.
+func (c Cfg) shapeLength() time.Duration {
+	var total time.Duration
+	for _, s := range c.Shape {
+		total += s.Length
+	}
+
+	return total
+}
+
+// segments is the Shape the run follows: the Cfg's, or the one its Chaos draws
+// over the Timeout.
+func (c Cfg) segments() []Segment {
+	if c.Chaos != (Chaos{}) {
+		return c.Chaos.segments(c.Timeout)
+	}
+
+	return c.Shape
+}
+
+// validateShape reports whether every segment of the Shape is one the run's
+// workers can keep. Segments are counted from 1 in what it says, as a person
+// reading a file of them would.
+func (c Cfg) validateShape() error {
+	for i, s := range c.Shape {
+		switch {
+		case s.Length <= 0:
+			return fmt.Errorf("shape segment %d: length must be greater than 0", i+1)
+		case s.Workers < 0 || s.Workers > c.Workers:
+			return fmt.Errorf("shape segment %d: workers must be from 0 to the run's %d", i+1, c.Workers)
+		// Written to turn NaN away too.
+		case !(s.Duty >= 0 && s.Duty <= 1):
+			return fmt.Errorf("shape segment %d: duty must be from 0 to 1", i+1)
+		}
+	}
+
+	// A run outlasting its shape would have no load to put on after it.
+	if total := c.shapeLength(); len(c.Shape) > 0 && c.Timeout > total {
+		return fmt.Errorf("timeout %s is longer than the shape's %s, so the run would outlast it", c.Timeout, total)
+	}
+
+	return nil
+}
+
+// shaper is a run's Shape as it goes: the segment under way, which each worker
+// reads before every unit, and what each segment has had of the workers.
+//
+// Each worker keeps its own account of the time it has been in units in the
+// segment, and before a unit waits until the segment has run long enough for
+// that to be its Duty. Nothing is shared between the workers but the segment
+// and the totals, so a worker waits on its own clock rather than on a lock.
+type shaper struct {
+	segments []Segment
+
+	// now is the segment under way, swapped whole at every turn; a worker
+	// waiting on it wakes when its changed is closed.
+	now atomic.Pointer[shapeState]
+
+	// workers is each worker's account, by id. A worker's is written only by
+	// the goroutine running it, and load runs one at a time for each id.
+	workers []shapeWorker
+
+	// busy and counts are every segment's totals, in nanoseconds and units.
+	busy   []atomic.Int64
+	counts []atomic.Uint64
+
+	// begun is when each segment began and how long the run had been paused
+	// by then, up to the one under way.
+	mu    sync.Mutex
+	begun []shapeState
+}
+
+// shapeState is a segment under way.
+type shapeState struct {
+	index      int
+	began      time.Time
+	pausedThen time.Duration
+	changed    chan struct{}
+}
+
+// shapeWorker is one worker's account of the segment it is in.
+type shapeWorker struct {
+	index int
+	busy  time.Duration
+}
+
+// newShaper is the shaper of segments for workers workers, starting on the
+// first at now, or nil for a run without a Shape.
+func newShaper(segments []Segment, workers int, now time.Time) *shaper {
+	if len(segments) == 0 {
+		return nil
+	}
+
+	s := &shaper{
+		segments: segments,
+		workers:  make([]shapeWorker, workers),
+		busy:     make([]atomic.Int64, len(segments)),
+		counts:   make([]atomic.Uint64, len(segments)),
+	}
+
+	s.turn(0, now, 0)
+
+	return s
+}
+
+// turn goes on to segment i at now, for a run paused for paused so far, and
+// wakes every worker waiting on the one before.
+func (s *shaper) turn(i int, now time.Time, paused time.Duration) {
+	st := shapeState{index: i, began: now, pausedThen: paused, changed: make(chan struct{})}
+
+	s.mu.Lock()
+	s.begun = append(s.begun, st)
+	s.mu.Unlock()
+
+	if old := s.now.Swap(&st); old != nil {
+		close(old.changed)
+	}
+}
+
+// take blocks until worker id may start a unit in the segment under way, and
+// reports false where ctx was done first. Time the run is paused is no time the
+// segment has run, so a worker does not come back from a pause owed a burst.
+func (s *shaper) take(ctx context.Context, id int, p *pause) bool {
+	if s == nil {
+		return true
+	}
+
+	w := &s.workers[id]
+
+	for {
+		st := s.now.Load()
+		seg := s.segments[st.index]
+
+		if w.index != st.index {
+			w.index, w.busy = st.index, 0
+		}
+
+		// Held until the segment changes, for a worker the segment has no
+		// place for.
+		wait := time.Duration(-1)
+
+		if id < seg.Workers && seg.Duty > 0 {
+			now := time.Now()
+			running := now.Sub(st.began) - (p.paused(now) - st.pausedThen)
+
+			earned := time.Duration(float64(w.busy) / seg.Duty)
+			if earned <= running {
+				return true
+			}
+
+			wait = earned - running
+		}
+
+		if !sleep(ctx, st.changed, wait) {
+			return false
+		}
+	}
+}
+
+// sleep waits for d, or without end for a negative d, until changed is closed
+// or ctx is done, and reports false where it was ctx.
+func sleep(ctx context.Context, changed <-chan struct{}, d time.Duration) bool {
+	var timeout <-chan time.Time
+
+	if d >= 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case <-timeout:
+	case <-changed:
+	case <-ctx.Done():
+		return false
+	}
+
+	return true
+}
+
+// clock is when a unit starts, for done; the zero time for a run without a
+// Shape, which has no use for it and so is spared reading the clock.
+func (s *shaper) clock() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+// done charges worker id's unit, started at start and n units long: its count
+// to the segment it was started in, and its time to every segment it ran in,
+// each for its part. The worker's own account has all of the time, being what
+// its duty in the segment it started in is kept against.
+func (s *shaper) done(id int, start time.Time, n uint64) {
+	if s == nil {
+		return
+	}
+
+	w := &s.workers[id]
+	end := time.Now()
+
+	w.busy += end.Sub(start)
+	s.counts[w.index].Add(n)
+
+	// The unit ended in the segment it began in, as all but one a segment's
+	// worth of them do.
+	if s.now.Load().index == w.index {
+		s.busy[w.index].Add(int64(end.Sub(start)))
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := w.index; i < len(s.begun); i++ {
+		until := end
+		if i+1 < len(s.begun) && s.begun[i+1].began.Before(end) {
+			until = s.begun[i+1].began
+		}
+
+		if until.After(start) {
+			s.busy[i].Add(int64(until.Sub(start)))
+			start = until
+		}
+	}
+}
+
+// cycle goes on to every segment after the first as its time comes, until the
+// run's context is done, and tells the Observer of each, the first included.
+// The times are the Shape's from the start of the run rather than from each
+// turn, so they do not drift; the run's timeout, which is the Shape's length,
+// ends the last.
+func (s *shaper) cycle(r *Run) {
+	r.told.Lock()
+	first := SegmentEvent{Segments: len(s.segments), Segment: s.segments[0]}
+	r.tell(func(obs Observer) {
+		if o, ok := obs.(SegmentObserver); ok {
+			o.OnSegment(first)
+		}
+	})
+	r.told.Unlock()
+
+	at := r.began
+
+	for i := 1; i < len(s.segments); i++ {
+		at = at.Add(s.segments[i-1].Length)
+
+		timer := time.NewTimer(time.Until(at))
+
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		// Under told, as a Burst's turns are, so none is told after the
+		// shutdown.
+		r.told.Lock()
+
+		if r.ctx.Err() != nil {
+			r.told.Unlock()
+
+			return
+		}
+
+		now := time.Now()
+		s.turn(i, now, r.paused.paused(now))
+
+		ev := SegmentEvent{Index: i, Segments: len(s.segments), Elapsed: now.Sub(r.began), Segment: s.segments[i]}
+		r.tell(func(obs Observer) {
+			if o, ok := obs.(SegmentObserver); ok {
+				o.OnSegment(ev)
+			}
+		})
+
+		r.told.Unlock()
+	}
+}
+
+// read is every segment begun as of now, which is the run's began plus
+// elapsed.
+func (s *shaper) read(r *Result, began, now time.Time, p *pause) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.Shape = make([]SegmentResult, len(s.begun))
+
+	for i, st := range s.begun {
+		end, pausedEnd := now, p.paused(now)
+		if i+1 < len(s.begun) {
+			end, pausedEnd = s.begun[i+1].began, s.begun[i+1].pausedThen
+		}
+
+		ran := end.Sub(st.began) - (pausedEnd - st.pausedThen)
+		busy := time.Duration(s.busy[i].Load())
+
+		offered := 0.0
+		if ran > 0 {
+			offered = busy.Seconds() / ran.Seconds()
+		}
+
+		r.Shape[i] = SegmentResult{
+			Segment: s.segments[i],
+			Start:   st.began.Sub(began),
+			Ran:     ran,
+			Busy:    busy,
+			Offered: offered,
+			Count:   s.counts[i].Load(),
+		}
+	}
+}
+
+// NewSegment is the segment of length that asks for cores of load: as few
+// workers as can carry it, each for the same share of the time. 2.5 cores is
+// three workers at a duty of 0.83 rather than five at 0.5, which is closer to
+// the steady load a trace of utilisation describes; 0 holds every worker.
+func NewSegment(length time.Duration, cores float64) Segment {
+	workers := int(math.Ceil(cores - 1e-9))
+	if workers <= 0 {
+		return Segment{Length: length}
+	}
+
+	return Segment{Length: length, Workers: workers, Duty: min(cores/float64(workers), 1)}
+}
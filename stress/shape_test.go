@@ -0,0 +1,85 @@
+package stress
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestShapeFollowsItsSegments runs three segments — both workers flat out, one
+// at half its time, and none — and checks the load each came to, and that the
+// run ended with the last.
+func TestShapeFollowsItsSegments(t *testing.T) {
+	// Long enough that the workers' start and a late turn are a small part of
+	// a segment: at 150ms, on a runner of one core, they took the first
+	// segment's two workers down to 1.73 about one run in ten.
+	const length = 300 * time.Millisecond
+
+	obs := &recorder{}
+
+	cfg := Cfg{
+		Workers: 2, Stressor: "contention", Modes: []string{"atomic"}, Observer: obs,
+		Shape: []Segment{{Length: length, Workers: 2, Duty: 1}, {Length: length, Workers: 1, Duty: 0.5}, {Length: length}},
+	}
+
+	r, err := cfg.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Reason != StopTimeout || r.Elapsed < 3*length {
+		t.Errorf("Reason, Elapsed = %s, %s; want the run to end with its shape", r.Reason, r.Elapsed)
+	}
+
+	if len(obs.segments) != 3 {
+		t.Fatalf("OnSegment() was called %d times, want 3: %v", len(obs.segments), obs.calls)
+	}
+
+	for i, ev := range obs.segments {
+		if ev.Index != i || ev.Segments != 3 || ev.Segment != cfg.Shape[i] || ev.Elapsed < time.Duration(i)*length {
+			t.Errorf("segment event %d = %+v, want segment %d at %s or later", i, ev, i, time.Duration(i)*length)
+		}
+	}
+
+	if len(r.Shape) != 3 {
+		t.Fatalf("Shape has %d segments, want 3", len(r.Shape))
+	}
+
+	// Wall-clock busy time, so a loaded runner short of cores still keeps
+	// both workers busy in the first.
+	for i, want := range []float64{2, 0.5, 0} {
+		s := r.Shape[i]
+
+		if math.Abs(s.Offered-want) > 0.25 || s.Segment != cfg.Shape[i] {
+			t.Errorf("segment %d = %+v, want %.2f cores offered", i, s, want)
+		}
+	}
+
+	if r.Shape[2].Count != 0 || r.Shape[0].Count == 0 {
+		t.Errorf("counts = %d, %d, %d; want none in the last segment", r.Shape[0].Count, r.Shape[1].Count, r.Shape[2].Count)
+	}
+}
+
+func TestNewSegment(t *testing.T) {
+	tests := []struct {
+		cores       float64
+		wantWorkers int
+		wantDuty    float64
+	}{
+		{cores: 0},
+		{cores: 0.25, wantWorkers: 1, wantDuty: 0.25},
+		{cores: 1, wantWorkers: 1, wantDuty: 1},
+		// As few workers as carry it, rather than more at a lower duty.
+		{cores: 2.5, wantWorkers: 3, wantDuty: 2.5 / 3},
+		{cores: 4, wantWorkers: 4, wantDuty: 1},
+	}
+
+	for _, tt := range tests {
+		s := NewSegment(time.Minute, tt.cores)
+
+		if s.Length != time.Minute || s.Workers != tt.wantWorkers || math.Abs(s.Duty-tt.wantDuty) > 1e-9 || math.Abs(s.Cores()-tt.cores) > 1e-9 {
+			t.Errorf("NewSegment(1m, %g) = %+v, want %d workers at %g", tt.cores, s, tt.wantWorkers, tt.wantDuty)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package stress
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// StallError is the cause of a run its StallTimeout ended: the worker that had
+// been on one unit for longer than it, and for how long.
+type StallError struct {
+	Stressor string // the worker's group's, as Stressors has it
+	Worker   int    // from 0, within its group
+	For      time.Duration
+	Timeout  time.Duration
+}
+
+func (e *StallError) Error() string {
+	return fmt.Sprintf("%s worker %d on one unit for %s, past the stall timeout of %s", e.Stressor, e.Worker, e.For.Round(time.Millisecond), e.Timeout)
+}
+
+// busy is when each of a group's workers started the unit it is on, as the
+// monotonic time since the run's groups were built, and 0 for one between
+// units: held by a pause, waiting on a schedule, or done. The wall clock is no
+// measure of it, a step of NTP's being a stall or hiding one. Only a run with a
+// StallTimeout keeps one, the clock it reads around every unit being a cost no
+// other run pays.
+type busy struct {
+	from time.Time
+	on   []atomic.Int64
+}
+
+func newBusy(workers int) *busy {
+	return &busy{from: time.Now(), on: make([]atomic.Int64, workers)}
+}
+
+// enter and leave are worker id's unit starting and finishing; both do nothing
+// on a nil busy.
+func (b *busy) enter(id int) {
+	if b != nil {
+		// At least 1, which is not between units, on a clock too coarse to
+		// have moved since from.
+		b.on[id].Store(max(int64(time.Since(b.from)), 1))
+	}
+}
+
+func (b *busy) leave(id int) {
+	if b != nil {
+		b.on[id].Store(0)
+	}
+}
+
+// since is how long worker id has been on its unit at now, and false where it
+// is on none.
+func (b *busy) since(id int, now time.Time) (time.Duration, bool) {
+	at := b.on[id].Load()
+	if at == 0 {
+		return 0, false
+	}
+
+	return now.Sub(b.from) - time.Duration(at), true
+}
+
+// watchStalls looks over every group's workers until ctx is done, and calls
+// stop with a *StallError for the first it finds on one unit for timeout or
+// longer. A worker held, or waiting its turn, is on no unit, so neither a pause
+// nor a Burst's off period nor a TargetRate's schedule is a stall.
+func watchStalls(ctx context.Context, groups []*group, timeout time.Duration, stop func(error)) {
+	// Often enough that a stall is caught within a tenth of the timeout past
+	// it.
+	tick := time.NewTicker(max(timeout/10, 10*time.Millisecond))
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-tick.C:
+			for _, g := range groups {
+				for id := range g.busy.on {
+					on, ok := g.busy.since(id, now)
+					if !ok || on < timeout {
+						continue
+					}
+
+					stop(&StallError{Stressor: g.s.name, Worker: id, For: on, Timeout: timeout})
+
+					return
+				}
+			}
+		}
+	}
+}
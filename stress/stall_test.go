@@ -0,0 +1,89 @@
+package stress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// withHangingStressor adds a stressor whose worker 1 never finishes its first
+// unit, as a hash on a core that hung would not, until the test is over. Its
+// other workers take a millisecond a unit.
+func withHangingStressor(t *testing.T) {
+	t.Helper()
+
+	hung := make(chan struct{})
+	saved := stressors
+
+	stressors = append(stressors[:len(stressors):len(stressors)], &stressor{
+		name: "hang", label: "hang", unit: "unit", units: "units", step: "unit",
+		start: func(int) func(int) uint64 {
+			return func(id int) uint64 {
+				if id == 1 {
+					<-hung
+				}
+
+				time.Sleep(time.Millisecond)
+
+				return 1
+			}
+		},
+	})
+
+	t.Cleanup(func() {
+		stressors = saved
+		close(hung)
+	})
+}
+
+// TestStallTimeoutEndsTheRun covers a worker that never comes back: the run
+// ends on StallTimeout rather than its own timeout, names the worker, and Wait
+// returns without it once the others have drained.
+func TestStallTimeoutEndsTheRun(t *testing.T) {
+	withHangingStressor(t)
+
+	obs := &recorder{}
+	cfg := Cfg{Workers: 2, Timeout: time.Minute, Stressor: "hang", StallTimeout: 100 * time.Millisecond, Observer: obs}
+
+	start := time.Now()
+
+	r, err := cfg.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	// The stall at 100ms, and 100ms more for a drain that never ends.
+	if took := time.Since(start); took > 10*time.Second {
+		t.Errorf("RunContext() took %s, want it over soon after the stall timeout", took)
+	}
+
+	if r.Reason != StopStall || obs.shutdown.Reason != StopStall {
+		t.Errorf("Reason = %s, OnShutdown's %s; want %s for both", r.Reason, obs.shutdown.Reason, StopStall)
+	}
+
+	var stalled *StallError
+	if !errors.As(obs.shutdown.Cause, &stalled) || stalled.Stressor != "hang" || stalled.Worker != 1 || stalled.For < cfg.StallTimeout {
+		t.Errorf("OnShutdown's Cause = %v, want worker 1 of hang on one unit past %s", obs.shutdown.Cause, cfg.StallTimeout)
+	}
+
+	if r.Count == 0 {
+		t.Error("Count = 0, want the units worker 0 did")
+	}
+}
+
+// TestPauseIsNoStall: a worker held is on no unit, however long it is held for.
+func TestPauseIsNoStall(t *testing.T) {
+	run, err := Cfg{Workers: 1, Timeout: 400 * time.Millisecond, Stressor: "contention", Modes: []string{"atomic"}, StallTimeout: 50 * time.Millisecond}.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+
+	run.Pause()
+	time.Sleep(200 * time.Millisecond)
+	run.Resume()
+
+	if r := run.Wait(); r.Reason != StopTimeout {
+		t.Errorf("Reason = %s, want %s: a pause is not a stall", r.Reason, StopTimeout)
+	}
+}
@@ -0,0 +1,166 @@
+package stress
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StealStats is the share of the machine's CPU time that was not its to use:
+// Steal the time a hypervisor ran another guest on a CPU this one was ready to
+// run on, and IOWait the time a CPU sat idle with I/O outstanding. Both are in
+// percent of every CPU's time, as top has them. A rate that drops while Steal
+// climbs is the host's neighbours, not a slower CPU.
+//
+// Steal and IOWait are over the run. RecentSteal and RecentIOWait are, in the
+// Result an Observer's OnProgress is given, since the progress report before
+// it, and in the first report and any other Result over the run as well.
+type StealStats struct {
+	Steal, IOWait             float64
+	RecentSteal, RecentIOWait float64
+
+	// ticks is the CPU time the figures were read at, which the next progress
+	// report's Recent figures are taken since.
+	ticks cpuTicks
+}
+
+// stealProbe reads the machine's CPU time from /proc/stat at the start of a run
+// and at every read after. It is every run's where there is a /proc/stat.
+type stealProbe struct {
+	path string
+
+	// then is the CPU time at start and end at stop, which is what read
+	// reports to once stopped is set.
+	mu        sync.Mutex
+	then, end cpuTicks
+	stopped   bool
+}
+
+// cpuTicks is the "cpu" line of /proc/stat: every CPU's time since boot, in
+// clock ticks, in all and in the two states StealStats has.
+type cpuTicks struct {
+	total, iowait, steal uint64
+}
+
+// newStealProbe is the probe of /proc/stat under root, or nil where there is
+// none.
+func newStealProbe(root string) probe {
+	path := filepath.Join(root, "proc/stat")
+	if _, ok := readCPUTicks(path); !ok {
+		return nil
+	}
+
+	return &stealProbe{path: path}
+}
+
+func (p *stealProbe) start() {
+	p.then, _ = readCPUTicks(p.path)
+}
+
+func (p *stealProbe) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end, _ = readCPUTicks(p.path)
+	p.stopped = true
+}
+
+func (p *stealProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now, ok := p.end, true
+	if !p.stopped {
+		now, ok = readCPUTicks(p.path)
+	}
+
+	if !ok || !now.since(p.then) {
+		return
+	}
+
+	s := StealStats{ticks: now}
+	s.Steal, s.IOWait = now.shares(p.then)
+	s.RecentSteal, s.RecentIOWait = s.Steal, s.IOWait
+
+	r.Steal = &s
+}
+
+// since takes s's Recent figures over the time since prev, the progress report
+// before s's, where there was one and the counters have not gone back since.
+func (s *StealStats) since(prev *StealStats) {
+	if prev == nil || !s.ticks.since(prev.ticks) {
+		return
+	}
+
+	s.RecentSteal, s.RecentIOWait = s.ticks.shares(prev.ticks)
+}
+
+// since is whether no counter of t's is behind before's, which one that wrapped
+// or a /proc/stat that changed under the run would be.
+func (t cpuTicks) since(before cpuTicks) bool {
+	return t.total >= before.total && t.steal >= before.steal && t.iowait >= before.iowait
+}
+
+// shares is the steal and iowait, in percent, of the time between since and t,
+// and 0 where no tick has passed.
+func (t cpuTicks) shares(since cpuTicks) (steal, iowait float64) {
+	total := t.total - since.total
+	if total == 0 {
+		return 0, 0
+	}
+
+	return 100 * float64(t.steal-since.steal) / float64(total), 100 * float64(t.iowait-since.iowait) / float64(total)
+}
+
+// readCPUTicks reads the "cpu" line of /proc/stat, which is every CPU's added
+// up:
+//
+//	cpu  user nice system idle iowait irq softirq steal guest guest_nice
+//
+// The guest times are in user and nice already, and are not counted twice. A
+// kernel before 2.6.11 has no steal, and is read as having none.
+func readCPUTicks(path string) (cpuTicks, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return cpuTicks{}, false
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] != "cpu" {
+			continue
+		}
+
+		// user to iowait are in every kernel this runs on.
+		if len(fields) < 6 {
+			return cpuTicks{}, false
+		}
+
+		var t cpuTicks
+
+		for i, field := range fields[1:min(len(fields), 9)] {
+			n, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return cpuTicks{}, false
+			}
+
+			t.total += n
+
+			switch i {
+			case 4:
+				t.iowait = n
+			case 7:
+				t.steal = n
+			}
+		}
+
+		return t, true
+	}
+
+	return cpuTicks{}, false
+}
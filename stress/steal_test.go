@@ -0,0 +1,122 @@
+package stress
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestReadCPUTicks(t *testing.T) {
+	tests := []struct {
+		name string
+		stat string
+		want cpuTicks
+		ok   bool
+	}{
+		{
+			name: "every field",
+			stat: "cpu  100 10 50 800 20 0 5 15 40 0\ncpu0 50 5 25 400 10 0 3 7 20 0\nintr 12345\n",
+			want: cpuTicks{total: 1000, iowait: 20, steal: 15},
+			ok:   true,
+		},
+		{
+			name: "no steal, as before 2.6.11",
+			stat: "cpu  100 10 50 820 20 0 0\n",
+			want: cpuTicks{total: 1000, iowait: 20},
+			ok:   true,
+		},
+		{name: "too few fields", stat: "cpu  100 10 50\n"},
+		{name: "not a number", stat: "cpu  100 ten 50 800 20 0 5 15\n"},
+		{name: "no cpu line", stat: "cpu0 100 10 50 800 20 0 5 15\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, map[string]string{"stat": tt.stat})
+
+			got, ok := readCPUTicks(filepath.Join(root, "stat"))
+			if ok != tt.ok || ok && got != tt.want {
+				t.Errorf("readCPUTicks() = %+v, %t; want %+v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestStealProbeRead covers the span a read reports, the run so far, and that
+// reading changes nothing: the Recent figures of a read are the run's too, and
+// only a progress report puts them against the report before.
+func TestStealProbeRead(t *testing.T) {
+	root := t.TempDir()
+
+	stat := func(total, iowait, steal int) {
+		// user makes up the rest of the total, beside a fixed 100 idle.
+		writeTree(t, root, map[string]string{"proc/stat": "cpu  " + strconv.Itoa(total-iowait-steal-100) + " 0 0 100 " + strconv.Itoa(iowait) + " 0 0 " + strconv.Itoa(steal) + " 0 0\n"})
+	}
+
+	stat(1000, 0, 0)
+
+	p := newStealProbe(root)
+	if p == nil {
+		t.Fatal("newStealProbe() = nil, want the probe of the tree's /proc/stat")
+	}
+
+	p.start()
+
+	read := func() StealStats {
+		var r Result
+
+		p.read(&r)
+
+		if r.Steal == nil {
+			t.Fatal("read() left Steal nil, want the figures of the tree's /proc/stat")
+		}
+
+		return *r.Steal
+	}
+
+	// exported is s without the counters it was read at.
+	exported := func(s StealStats) StealStats {
+		s.ticks = cpuTicks{}
+
+		return s
+	}
+
+	// 100 ticks since the start, 10 of them stolen and 2 waiting, read twice.
+	stat(1100, 2, 10)
+	first := read()
+
+	want := StealStats{Steal: 10, IOWait: 2, RecentSteal: 10, RecentIOWait: 2}
+	if got := exported(first); got != want {
+		t.Errorf("the first read() = %+v, want %+v", got, want)
+	}
+
+	if got := exported(read()); got != want {
+		t.Errorf("read() again = %+v, want %+v, the read before having changed nothing", got, want)
+	}
+
+	// 100 more, none stolen: over the run, and since the first as a report.
+	stat(1200, 2, 10)
+	second := read()
+
+	if got, want := exported(second), (StealStats{Steal: 5, IOWait: 1, RecentSteal: 5, RecentIOWait: 1}); got != want {
+		t.Errorf("the second read() = %+v, want %+v", got, want)
+	}
+
+	second.since(&first)
+
+	if got, want := exported(second), (StealStats{Steal: 5, IOWait: 1}); got != want {
+		t.Errorf("the second read() since the first = %+v, want %+v", got, want)
+	}
+
+	// 200 more, half of them stolen, and the end where stop left it.
+	stat(1400, 2, 110)
+	p.stop()
+	stat(5000, 2, 4000)
+	last := read()
+	last.since(&second)
+
+	if got, want := exported(last), (StealStats{Steal: 27.5, IOWait: 0.5, RecentSteal: 50}); got != want {
+		t.Errorf("read() after stop, since the second = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,782 @@
+// Package stress is the load stressy puts on a machine, as a Go API: the
+// stressors the stressy command runs, for a program or an integration test that
+// wants load in the background without a process to start or lines to parse.
+// A Cfg says what load, on how many workers and for how long; RunContext runs
+// it and returns a Result, and Start does the same with a handle on the run
+// while it goes.
+//
+// Nothing here prints and nothing here handles a signal. The stressy command is
+// both of those layered over this package — its lines are built from the
+// Results a Run hands back — so what a command line measures and what a caller
+// of RunContext measures are one implementation.
+//
+// What this package exports is held to the stability the command line is: a
+// 1.x release adds to it and renames or removes nothing.
+package stress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/felipeneuwald/stressy/internal/units"
+)
+
+// hashCost is the bcrypt cost every worker hashes at. bcrypt doubles its work
+// per increment, so the cost sets how long one uninterruptible
+// GenerateFromPassword call runs, and so how long a worker takes to notice
+// cancellation: ~0.18s per hash at cost 12, against ~26 hours at bcrypt.MaxCost,
+// which pegs a core no harder and leaves the cancellation check unreachable.
+const hashCost = 12
+
+// Cfg is a configured stress test, ready to run. The zero value is not: it has
+// no workers. Cfg{Workers: 1} is a bcrypt run on one worker that lasts until its
+// context is done.
+type Cfg struct {
+	Workers int           // number of parallel worker goroutines
+	Timeout time.Duration // how long to run (0 for until the context is done)
+
+	// Count, where it is set, is how many of the stressor's units the run
+	// does before it ends, shared between the workers, so the question a run
+	// answers is how long the machine takes over a fixed amount of work rather
+	// than how much it does in a fixed time. Timeout, where both are set, is a
+	// cap: the run ends on whichever comes first, and its Reason says which.
+	Count uint64
+
+	// TargetRate, where it is set, is how many units a second the run starts,
+	// on a schedule that keeps to the clock however far behind the workers
+	// fall, and the Result's Dispatch says how far that was. 0 is a run whose
+	// workers start the next unit as soon as the last is done, which measures
+	// how much a node can do rather than whether it keeps up with a demand. A
+	// unit is one call of the stressor's, which for one that counts in batches
+	// is a batch.
+	TargetRate float64
+
+	// Burst, where it is set, runs the workers in on periods with off periods
+	// between, in which every one of them is held.
+	Burst Burst
+
+	// Shape, where it is set, is the load over the run a segment at a time:
+	// how many of the Workers go on in each and how much of the time, as a
+	// trace of a production node's utilisation has it. The run lasts as long
+	// as the Shape, and a Timeout, where one is set, can only end it sooner.
+	Shape []Segment
+
+	// Chaos, where it is set, draws a Shape of its own from its Seed, for a
+	// load that changes at random and the same way on every run of it. It
+	// takes a Timeout, which is how long the Shape it draws lasts.
+	Chaos Chaos
+
+	// Stressor names the load the workers put on, one of Stressors; "" is
+	// bcrypt, the one every run had before there was a choice. Modes picks the
+	// variants of it to run, in turn, and empty runs all of them.
+	Stressor string
+	Modes    []string
+
+	// WorkingSets is the sizes, in bytes, the cache stressor walks a ring of,
+	// one variant each; empty is cacheTiers. No other stressor takes one.
+	WorkingSets []int
+
+	// AllocRate is the bytes a second the gc stressor's workers allocate
+	// between them, and 0 as many as they can. HeapTarget is the live heap
+	// they hold while they do, and 0 is DefaultHeapTarget. No other stressor
+	// takes either.
+	AllocRate  int
+	HeapTarget int
+
+	// Mix runs several stressors at once, each a group with workers of its
+	// own, and where it is set Stressor and Workers are not read. Modes,
+	// WorkingSets, AllocRate and HeapTarget go to the groups whose stressor
+	// takes them.
+	Mix []Group
+
+	// LatencyProbe is how often a goroutine beside the workers wakes to measure
+	// how late it was woken, and 0 runs none. LatencyLocked pins it to an OS
+	// thread of its own.
+	LatencyProbe  time.Duration
+	LatencyLocked bool
+
+	// Nice, where it is set, is the nice value every worker's OS thread runs
+	// at, from -20 to 19, and Sched, where it is set, the scheduling policy,
+	// one of Scheds: a run at 19 under idle soaks up what the machine has
+	// spare and gives it back the moment anything else wants it. The rest of
+	// the process is left as it was, so the latency probe measures the
+	// machine rather than its own priority. Both are Linux's, and a run below
+	// nice 0 needs CAP_SYS_NICE.
+	Nice  int
+	Sched string
+
+	// MaxTemp, where it is set, is the temperature in degrees Celsius at
+	// which the run ends itself: every thermal zone is read every second,
+	// and the first at MaxTemp or past it stops the run as its timeout
+	// would, with StopTemp for the Reason. It is for a run nobody watches on
+	// hardware a failed fan could cook, and a run with one fails to start on
+	// a machine with no thermal zone to read.
+	MaxTemp float64
+
+	// StallTimeout, where it is set, is how long a worker may be on one unit
+	// before the run is ended as stalled, with StopStall for the Reason: a
+	// hash that never returns is a core that hung, which a run would
+	// otherwise wait on forever. The drain that follows waits for the other
+	// workers for no longer than StallTimeout again, and Wait returns without
+	// the stalled worker, which is left to the process's exit.
+	StallTimeout time.Duration
+
+	// Observer, where it is set, is told what the run is doing as it does it,
+	// with a progress report every Report; Report 0 makes none. Neither is
+	// needed for the Result. The Observer is left out of a Cfg encoded as
+	// JSON, being code rather than configuration.
+	Observer Observer `json:"-"`
+	Report   time.Duration
+
+	// Root is the directory the run reads /proc and /sys under, for the
+	// figures a Result has of the machine rather than of the load: "" is /,
+	// and a test's is a tree of files made to look like them. It is left out
+	// of a Cfg encoded as JSON too, being the machine's rather than the run's.
+	Root string `json:"-"`
+
+	// Exec is the command the syscall stressor's exec mode runs, once a unit,
+	// as a path and its arguments: a process that exits as soon as it is up,
+	// and empty is true(1) from the PATH. The stressy command runs itself, so
+	// the mode needs nothing its image does not have. Left out of a Cfg
+	// encoded as JSON, for Root's reason.
+	Exec []string `json:"-"`
+}
+
+// RunContext runs the configured workers until the timeout expires, the Count
+// is done or ctx is, whichever is first, and returns what they did once every
+// one of them has finished the unit it was on — the drain, which for bcrypt is a
+// hash.
+//
+// That drain is one unit long only while the workers fit in GOMAXPROCS. Past it
+// the units in flight finish in series, so the drain runs for roughly
+// Workers/GOMAXPROCS of them: measured on 18 cores against a timeout of 1s, 18
+// workers ended about 0.2s past the deadline and 2000 about 20s past it. Nothing
+// caps Workers against the machine, because nothing here reads the machine
+// (#104); the stressy command says what the wait is for instead (#122).
+//
+// It returns an error, and no Result, where the configuration is invalid or a
+// variant cannot be started; a run ctx ended is not an error, and the Result's
+// Reason says it was that rather than the timeout.
+func (c Cfg) RunContext(ctx context.Context) (Result, error) {
+	r, err := c.Start(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return r.Wait(), nil
+}
+
+// Run is a run in progress: what Start hands back, to be read while the workers
+// run and waited on once they are told to stop.
+type Run struct {
+	groups   []*group
+	probes   []probe
+	releases []func()
+
+	// dispatch is TargetRate's schedule, and nil on a closed-loop run; bursts
+	// is Burst's cycle, and nil on a steady one.
+	dispatch *dispatcher
+	bursts   *bursts
+
+	// shape is Shape's segments as they go, and nil on a run without one.
+	shape *shaper
+
+	// began is when the clock started, after every variant was readied.
+	began time.Time
+
+	// host is the machine the run is on, read once at Start.
+	host Host
+
+	// stallTimeout is StallTimeout, which bounds the drain of a run it ended.
+	stallTimeout time.Duration
+
+	// ctx is done once the run is told to stop, by its timeout or by the
+	// context Start was given; cancel is for Wait, which releases it.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	drained sync.WaitGroup
+	waited  sync.Once
+	result  Result
+
+	// obs is the Observer the run calls, if any, and watched is done once the
+	// goroutine calling it has made its OnShutdown call. reported is the Steal
+	// of the last progress report it made, which the next one's Recent figures
+	// are taken since.
+	obs      Observer
+	watched  sync.WaitGroup
+	reported *StealStats
+
+	// paused holds the workers while the run is paused. told is held by
+	// Pause, Resume and Report while they change the run and ask watch, with
+	// nudge, for the call to the Observer that says so; asked is those calls.
+	paused pause
+	told   sync.Mutex
+	asked  []func(Observer)
+	nudge  chan struct{}
+}
+
+// errTimeout is the cause a run's context carries where its own timeout ended
+// it, which is how Wait tells that apart from the caller's context ending it.
+var errTimeout = errors.New("timeout expired")
+
+// Start validates the configuration, readies every variant it runs and starts
+// the workers, returning once they are running. Each variant is readied before
+// the clock starts, so what it takes to set one up — a ring of 128MiB to
+// shuffle, say — is not charged to its rate.
+//
+// Readying one can fail where it needs something of the machine's, a directory
+// to open files in under a FROM scratch image with no /tmp, and that fails the
+// run before any worker starts: nothing was measured. Anything already readied
+// is given back first.
+//
+// The run ends when the timeout expires or ctx is done. Wait is what collects
+// it, and must be called, because it is also what gives back what the variants
+// took.
+func (c Cfg) Start(ctx context.Context) (*Run, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Before anything is readied, so a priority the OS refuses has nothing
+	// to give back.
+	prio := priority{nice: c.Nice, sched: c.Sched}
+	if err := prio.check(); err != nil {
+		return nil, err
+	}
+
+	root := c.Root
+	if root == "" {
+		root = "/"
+	}
+
+	// A limit nothing can read is a run nobody is kept safe from, and one
+	// that would say nothing of it until the board was cooked.
+	var zones []string
+	if c.MaxTemp > 0 {
+		if zones = thermalZones(root); len(zones) == 0 {
+			return nil, fmt.Errorf("max temp has no thermal zone to read under %s", filepath.Join(root, "sys/class/thermal"))
+		}
+	}
+
+	r := &Run{obs: c.Observer, nudge: make(chan struct{}, 1), dispatch: newDispatcher(c.TargetRate), bursts: newBursts(c.Burst), host: readHost(root)}
+
+	// Validate has already turned away a stressor or a mode that does not
+	// exist, so no lookup can fail here.
+	for _, gc := range c.groups() {
+		g := newGroup(gc)
+		if c.StallTimeout > 0 {
+			g.busy = newBusy(gc.Workers)
+		}
+
+		for i, v := range g.variants {
+			unit, release, err := v.begin(gc)
+			if err != nil {
+				r.release()
+
+				return nil, fmt.Errorf("%s %s %s: %w", g.s.name, g.s.kind, v.name, err)
+			}
+
+			r.releases = append(r.releases, release)
+			g.units[i] = unit
+		}
+
+		r.groups = append(r.groups, g)
+	}
+
+	// What the run measures besides its count: the stressors' own probes, and
+	// LatencyProbe's.
+	for _, g := range r.groups {
+		if g.s.watch != nil {
+			r.probes = append(r.probes, g.s.watch())
+		}
+	}
+
+	if c.LatencyProbe > 0 {
+		r.probes = append(r.probes, newLatencyProbe(c.LatencyProbe, c.LatencyLocked))
+	}
+
+	// Every run's, where the OS says, against the workers of every group.
+	var workers int
+	for _, gc := range c.groups() {
+		workers += gc.Workers
+	}
+
+	if p := newCPUProbe(workers); p != nil {
+		r.probes = append(r.probes, p)
+	}
+
+	if p := newCgroupProbe(root); p != nil {
+		r.probes = append(r.probes, p)
+	}
+
+	if p := newPressureProbe(root); p != nil {
+		r.probes = append(r.probes, p)
+	}
+
+	if p := newStealProbe(root); p != nil {
+		r.probes = append(r.probes, p)
+	}
+
+	if p := newThermalProbe(root); p != nil {
+		r.probes = append(r.probes, p)
+	}
+
+	if p := newEnergyProbe(root); p != nil {
+		r.probes = append(r.probes, p)
+	}
+
+	for _, p := range r.probes {
+		p.start()
+	}
+
+	// Started before the deadline is set, so the elapsed time a Result reports
+	// is never less than the timeout the caller asked for.
+	r.began = time.Now()
+
+	// The Count's end is one the workers reach rather than one a clock does,
+	// so it cancels a context of its own under the timeout's.
+	counted, stop := context.WithCancelCause(ctx)
+	b := newBudget(c.Count, func() { stop(errCount) })
+
+	// A Shape with no Timeout is one as long as itself; Validate has turned
+	// away a Timeout longer than it.
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = c.shapeLength()
+	}
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		r.ctx, cancel = context.WithTimeoutCause(counted, timeout, errTimeout)
+	} else {
+		r.ctx, cancel = context.WithCancel(counted)
+	}
+
+	r.cancel = func() {
+		cancel()
+		stop(nil)
+	}
+
+	r.dispatch.start(r.ctx, r.began)
+	r.shape = newShaper(c.segments(), c.Workers, r.began)
+
+	// Every group under the one context, so one shutdown ends them all and the
+	// drain is over when the slowest group's is.
+	gate := &gate{paused: &r.paused, off: r.bursts.hold(), dispatch: r.dispatch, budget: b, shape: r.shape, priority: prio}
+
+	for _, g := range r.groups {
+		r.drained.Go(func() {
+			load(r.ctx, g.cfg.Workers, g.units, g.cfg.turn(len(g.units)), g.done, g.busy, gate)
+		})
+	}
+
+	// Done with the run's context, and so before Wait is through with it.
+	if r.bursts != nil {
+		r.drained.Go(func() { r.bursts.cycle(r) })
+	}
+
+	if r.shape != nil {
+		r.drained.Go(func() { r.shape.cycle(r) })
+	}
+
+	if zones != nil {
+		r.drained.Go(func() { watchTemp(r.ctx, zones, c.MaxTemp, stop) })
+	}
+
+	if c.StallTimeout > 0 {
+		r.stallTimeout = c.StallTimeout
+		r.drained.Go(func() { watchStalls(r.ctx, r.groups, c.StallTimeout, stop) })
+	}
+
+	if r.obs != nil {
+		r.obs.OnStart(StartEvent{Cfg: c, Groups: r.read(0).Groups, Host: r.host})
+
+		r.watched.Go(func() { r.watch(r.obs, c.Report) })
+	}
+
+	return r, nil
+}
+
+// Done is closed once the run has been told to stop, by its timeout or by the
+// context Start was given. The workers are still draining when it is: Wait is
+// what returns once they are through.
+func (r *Run) Done() <-chan struct{} { return r.ctx.Done() }
+
+// Snapshot is what the run has done so far, with the Reason StopNone. A variant
+// is charged the time it has had the workers in the phases it has finished, so
+// its Rate lags its Count by up to a phase; the run's and each group's do not.
+func (r *Run) Snapshot() Result {
+	return r.read(time.Since(r.began))
+}
+
+// Wait blocks until the run has stopped and every worker has drained, stops the
+// probes, gives back what the variants took and returns what the run did. It
+// can be called more than once, and from more than one goroutine; every call
+// returns the same Result.
+func (r *Run) Wait() Result {
+	r.waited.Do(func() {
+		abandoned := r.drain()
+
+		// Measured rather than the configured timeout echoed back, because a
+		// run ends past its deadline by the drain, and the rate divides by the
+		// time that actually passed.
+		elapsed := time.Since(r.began)
+
+		for _, p := range r.probes {
+			p.stop()
+		}
+
+		r.result = r.read(elapsed)
+
+		// Read before cancel, which would make every run look cancelled.
+		r.result.Reason, _ = r.reason()
+
+		r.cancel()
+
+		// What a stalled worker is using is left to it: it may yet touch it.
+		if !abandoned {
+			r.release()
+		}
+
+		// After OnShutdown, which the drain does not wait for.
+		if r.obs != nil {
+			r.watched.Wait()
+			r.obs.OnSummary(r.result)
+		}
+	})
+
+	return r.result
+}
+
+// drain waits for every worker to finish the unit it is on, and reports
+// whether it gave up on one: a run StallTimeout ended has a worker that may
+// never finish, and waits StallTimeout again for the others before it goes on
+// without them.
+func (r *Run) drain() bool {
+	if r.stallTimeout == 0 {
+		r.drained.Wait()
+
+		return false
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		r.drained.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return false
+	case <-r.ctx.Done():
+	}
+
+	var stalled *StallError
+	if !errors.As(context.Cause(r.ctx), &stalled) {
+		<-done
+
+		return false
+	}
+
+	timer := time.NewTimer(r.stallTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// release gives back what the variants readied so far took, last first.
+func (r *Run) release() {
+	for i := len(r.releases) - 1; i >= 0; i-- {
+		r.releases[i]()
+	}
+
+	r.releases = nil
+}
+
+// read is the Result of the run as it stands elapsed after it began.
+func (r *Run) read(elapsed time.Duration) Result {
+	res := Result{
+		Host:    r.host,
+		Elapsed: elapsed,
+		Paused:  r.paused.paused(r.began.Add(elapsed)),
+		Groups:  make([]GroupResult, len(r.groups)),
+	}
+
+	for i, g := range r.groups {
+		res.Groups[i] = g.read(elapsed - res.Paused)
+		res.Count += res.Groups[i].Count
+	}
+
+	res.Rate = rate(res.Count, elapsed-res.Paused)
+
+	for _, p := range r.probes {
+		p.read(&res)
+	}
+
+	r.dispatch.read(&res, r.began.Add(elapsed), res.Paused)
+	r.bursts.read(&res, r.began.Add(elapsed), &r.paused)
+	r.shape.read(&res, r.began, r.began.Add(elapsed), &r.paused)
+
+	return res
+}
+
+// rate is the rate every Result quotes. The guard is why it is worth a function:
+// dividing by a zero elapsed time would report +Inf.
+func rate(count uint64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(count) / elapsed.Seconds()
+}
+
+// Validate reports whether a run can be started with this configuration.
+// Timeout 0 is indefinite and has no upper bound, because any length is one the
+// caller asked for.
+//
+// Workers has the one ceiling the program cannot do without. sync.WaitGroup
+// counts in an int32, so a run's wg.Add wrapped negative at 2^31 and the run
+// died on `panic: sync: negative WaitGroup counter`: a stack trace, no summary,
+// and an exit code the command's README does not carry (#143). That is a
+// property of the library rather than of the host, so #104's "nothing here
+// reads the machine" stands.
+//
+// It is also the whole of the ceiling. 500000 workers for a nanosecond complete
+// cleanly, so any lower bound would be invented, and the number it would have
+// to be measured against is GOMAXPROCS — which is the machine. A count far
+// above the cores on offer is slow to shut down rather than wrong.
+//
+// Start calls it, so a caller need not; the stressy command calls it as well so
+// that a rejected command line is reported before anything else is printed.
+// Workers 0 would start no goroutines and idle forever.
+func (c Cfg) Validate() error {
+	// A mix is validated a group at a time, each group a configuration of its
+	// own that comes back through here.
+	if len(c.Mix) > 0 {
+		return c.validateMix()
+	}
+
+	switch {
+	case c.Workers < 1:
+		return fmt.Errorf("workers must be 1 or greater")
+	// The number is said to whoever exceeded it and nowhere else — an int32
+	// width in the help or the flag list is the program reading its arithmetic
+	// aloud at everyone, which is what #129 took out of the parser. Unreachable
+	// on a 32-bit build, where int is that width already and nothing can get
+	// past it to overflow; every published binary is 64-bit.
+	case c.Workers > math.MaxInt32:
+		return fmt.Errorf("workers must be %d or fewer", math.MaxInt32)
+	case c.Timeout < 0:
+		return fmt.Errorf("timeout must be 0 (indefinite) or greater")
+	// The budget is an int64 the claims count down. Nobody waits out 2^63
+	// hashes, so the bound is the budget's rather than one anybody meets.
+	case c.Count > math.MaxInt64:
+		return fmt.Errorf("count must be %d or fewer", uint64(math.MaxInt64))
+	// Written to turn NaN away too, which no comparison is true of.
+	case !(c.TargetRate >= 0):
+		return fmt.Errorf("target rate must be 0 (closed loop) or greater")
+	case c.TargetRate > MaxTargetRate:
+		return fmt.Errorf("target rate must be %.0f a second or lower", float64(MaxTargetRate))
+	// The schedule would go on issuing through the off periods, and the
+	// backlog they built would be the burst's rather than the node's.
+	case c.TargetRate > 0 && c.Burst != (Burst{}):
+		return fmt.Errorf("target rate and burst both say when units start; give one of them")
+	case c.TargetRate > 0 && len(c.Shape) > 0:
+		return fmt.Errorf("target rate and shape both say when units start; give one of them")
+	case c.Burst != (Burst{}) && len(c.Shape) > 0:
+		return fmt.Errorf("burst and shape both say when the workers run; give one of them")
+	case c.TargetRate > 0 && c.Chaos != (Chaos{}):
+		return fmt.Errorf("target rate and chaos both say when units start; give one of them")
+	case c.Burst != (Burst{}) && c.Chaos != (Chaos{}):
+		return fmt.Errorf("burst and chaos both say when the workers run; give one of them")
+	case len(c.Shape) > 0 && c.Chaos != (Chaos{}):
+		return fmt.Errorf("shape and chaos both say how many workers run; give one of them")
+	}
+
+	if err := c.Burst.validate(); err != nil {
+		return err
+	}
+
+	if err := c.validateShape(); err != nil {
+		return err
+	}
+
+	if err := c.Chaos.validate(c.Workers, c.Timeout); err != nil {
+		return err
+	}
+
+	if err := c.validatePriority(); err != nil {
+		return err
+	}
+
+	// A Shape with no Timeout ends with itself, and bounds what a Timeout
+	// would below.
+	if c.Timeout == 0 {
+		c.Timeout = c.shapeLength()
+	}
+
+	switch {
+	// A negative Report panics inside time.NewTicker. One past the timeout is
+	// a report that never comes, which is the shape of 1m typed where 1s was
+	// meant (#115); equal is allowed, the deadline being what ends that run.
+	case c.Report < 0:
+		return fmt.Errorf("report must be 0 (off) or greater")
+	case c.Timeout > 0 && c.Report > c.Timeout:
+		return fmt.Errorf("report %s is longer than timeout %s, so no progress would be reported", c.Report, c.Timeout)
+	}
+
+	// The command's flag already refuses a name it has no stressor for; this
+	// is for a Cfg nothing parsed. A mode is checked only here, because which
+	// modes exist depends on the stressor, and the two flags can come in either
+	// order.
+	if _, ok := lookupStressor(c.Stressor); !ok {
+		return fmt.Errorf("stressor must be one of %s", stressorNames())
+	}
+
+	if _, err := c.variants(); err != nil {
+		return err
+	}
+
+	// Bounded like the command's --report, and for its reasons: below the
+	// floor the probe spins rather than sleeps, and past the timeout it never
+	// wakes at all.
+	switch {
+	case c.LatencyProbe < 0, c.LatencyProbe > 0 && c.LatencyProbe < LatencyFloor:
+		return fmt.Errorf("latency probe must be 0 (off) or %s or greater", units.FormatDuration(LatencyFloor))
+	case c.Timeout > 0 && c.LatencyProbe > c.Timeout:
+		return fmt.Errorf("latency probe %s is longer than timeout %s, so no wakeup would be measured", units.FormatDuration(c.LatencyProbe), c.Timeout)
+	case c.LatencyLocked && c.LatencyProbe == 0:
+		return fmt.Errorf("latency locked is set with no latency probe to lock")
+	// Written to turn NaN away too.
+	case !(c.MaxTemp >= 0):
+		return fmt.Errorf("max temp must be 0 (none) or greater")
+	case c.StallTimeout < 0:
+		return fmt.Errorf("stall timeout must be 0 (none) or greater")
+	}
+
+	switch {
+	case c.AllocRate < 0:
+		return fmt.Errorf("alloc rate must be 0 (unlimited) or greater")
+	case c.HeapTarget < 0:
+		return fmt.Errorf("heap target must be 0 (%s) or greater", units.FormatSize(DefaultHeapTarget))
+	case c.AllocRate > 0 && c.stressor() != gcStressor:
+		return fmt.Errorf("stressor %s takes no alloc rate", c.stressor().name)
+	case c.HeapTarget > 0 && c.stressor() != gcStressor:
+		return fmt.Errorf("stressor %s takes no heap target", c.stressor().name)
+	}
+
+	// The floor is two nodes, which is the smallest ring there is to walk; the
+	// ceiling is the uint32 a node's next is, which is a property of the ring
+	// rather than of the host, like the workers' int32.
+	for _, size := range c.WorkingSets {
+		switch {
+		case size < minWorkingSet:
+			return fmt.Errorf("working set must be %s or larger", units.FormatSize(minWorkingSet))
+		case size > maxWorkingSet:
+			return fmt.Errorf("working set must be %s or smaller", units.FormatSize(maxWorkingSet))
+		}
+	}
+
+	return nil
+}
+
+// variants is what a run of this configuration cycles through: the modes of a
+// stressor that has them, a ring per working set for one sized by WorkingSets,
+// the one a stressor tuned by settings of its own builds from them, and for any
+// other the stressor alone. It is where a setting given to a stressor that
+// takes no such thing is caught.
+func (c Cfg) variants() ([]variant, error) {
+	s := c.stressor()
+
+	if s.sized == nil {
+		if len(c.WorkingSets) > 0 {
+			return nil, fmt.Errorf("stressor %s takes no working set", s.name)
+		}
+
+		variants, err := s.pick(c.Modes)
+		if err == nil && s.tuned != nil {
+			variants = []variant{s.tuned(c)}
+		}
+
+		return variants, err
+	}
+
+	if len(c.Modes) > 0 {
+		return nil, fmt.Errorf("stressor %s has no modes", s.name)
+	}
+
+	sizes := c.WorkingSets
+	if len(sizes) == 0 {
+		sizes = cacheTiers
+	}
+
+	variants := make([]variant, len(sizes))
+	for i, size := range sizes {
+		variants[i] = s.sized(size)
+	}
+
+	return variants, nil
+}
+
+// stressor returns the stressor this configuration names. Validate is what
+// rejects a name there is no stressor for; past it, the lookup cannot fail.
+func (c Cfg) stressor() *stressor {
+	s, _ := lookupStressor(c.Stressor)
+
+	return s
+}
+
+// turn is how long each phase of a run with n variants lasts: phase, or less
+// where the run is too short to give every variant a phase that long.
+func (c Cfg) turn(n int) time.Duration {
+	if c.Timeout > 0 {
+		return min(phase, c.Timeout/time.Duration(n))
+	}
+
+	return phase
+}
+
+// bcryptStressor is the load stressy was written for, and the default: every
+// worker hashing bcrypt at hashCost, and so a core apiece pegged in user space.
+var bcryptStressor = &stressor{
+	name:  "bcrypt",
+	label: "CPU",
+	unit:  "hash",
+	units: "hashes",
+	step:  "hash",
+	start: func(int) func(int) uint64 {
+		return func(int) uint64 { return hash() }
+	},
+}
+
+// hash computes one bcrypt hash at hashCost and counts it. That is the whole of
+// the bcrypt load: bcrypt salts every call itself, so nothing outside the hash
+// has to vary for the work to be real.
+func hash() uint64 {
+	// Unreachable in practice: the cost is a valid constant and the password is
+	// seven bytes, which leaves salt generation as the only error source, and
+	// crypto/rand no longer reports failure.
+	if _, err := bcrypt.GenerateFromPassword(password, hashCost); err != nil {
+		panic(err)
+	}
+
+	return 1
+}
+
+// password is what every hash hashes; a constant stays well inside bcrypt's
+// 72-byte limit.
+var password = []byte("stressy")
@@ -0,0 +1,326 @@
+package stress
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stopBudget bounds how long a worker may take to observe cancellation. It is
+// far looser than the ~0.2s a single hash at hashCost costs, because it has to
+// hold on a loaded CI runner under -race, where that hash measures ~1.9s. It
+// is still three orders of magnitude under the ~26 hours bcrypt.MaxCost took,
+// which is the bug it guards (#15).
+const stopBudget = 30 * time.Second
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Cfg
+		wantErr string
+	}{
+		{name: "one worker, indefinite", cfg: Cfg{Workers: 1, Timeout: 0}},
+		{name: "one worker, one second", cfg: Cfg{Workers: 1, Timeout: time.Second}},
+		{name: "many workers", cfg: Cfg{Workers: 64, Timeout: time.Minute}},
+		{name: "sub-second timeout", cfg: Cfg{Workers: 1, Timeout: 250 * time.Millisecond}},
+		// The ceiling is sync.WaitGroup's counter, so the last accepted value is
+		// the largest wg.Add can hold rather than a number anybody would run.
+		{name: "workers at the WaitGroup ceiling", cfg: Cfg{Workers: math.MaxInt32, Timeout: time.Second}},
+		{name: "contention, every mode", cfg: Cfg{Workers: 4, Timeout: time.Minute, Stressor: "contention"}},
+		{name: "contention, two modes", cfg: Cfg{Workers: 4, Stressor: "contention", Modes: []string{"padded", "unpadded"}}},
+		{name: "a count with a timeout for a cap", cfg: Cfg{Workers: 2, Count: 1000, Timeout: time.Minute}},
+		{name: "a count at the budget's ceiling", cfg: Cfg{Workers: 1, Count: math.MaxInt64}},
+		{name: "a target rate", cfg: Cfg{Workers: 2, TargetRate: 0.5}},
+		{name: "a target rate at the ceiling", cfg: Cfg{Workers: 2, TargetRate: MaxTargetRate}},
+		{name: "a negative target rate", cfg: Cfg{Workers: 2, TargetRate: -1}, wantErr: "target rate must be 0 (closed loop) or greater"},
+		{name: "a target rate of NaN", cfg: Cfg{Workers: 2, TargetRate: math.NaN()}, wantErr: "target rate must be 0 (closed loop) or greater"},
+		{name: "a target rate past the ceiling", cfg: Cfg{Workers: 2, TargetRate: 2e9}, wantErr: "target rate must be 1000000000 a second or lower"},
+		{name: "a burst", cfg: Cfg{Workers: 2, Burst: Burst{On: 10 * time.Second, Off: 50 * time.Second, Jitter: 5 * time.Second}}},
+		{name: "a burst with no off period", cfg: Cfg{Workers: 2, Burst: Burst{On: 10 * time.Second}}, wantErr: "burst on and off must both be greater than 0"},
+		{name: "a burst with a negative jitter", cfg: Cfg{Workers: 2, Burst: Burst{On: time.Second, Off: time.Second, Jitter: -time.Second}}, wantErr: "burst jitter must be 0 or greater and shorter than both 1s on and 1s off"},
+		{name: "a burst jittered as long as a period", cfg: Cfg{Workers: 2, Burst: Burst{On: 10 * time.Second, Off: 5 * time.Second, Jitter: 5 * time.Second}}, wantErr: "burst jitter must be 0 or greater and shorter than both 10s on and 5s off"},
+		{name: "a burst at a target rate", cfg: Cfg{Workers: 2, TargetRate: 50, Burst: Burst{On: time.Second, Off: time.Second}}, wantErr: "target rate and burst both say when units start; give one of them"},
+		{name: "a shape", cfg: Cfg{Workers: 2, Shape: []Segment{{Length: time.Minute, Workers: 2, Duty: 0.5}, {Length: time.Minute}}}},
+		{name: "a shape cut short", cfg: Cfg{Workers: 2, Timeout: time.Minute, Shape: []Segment{{Length: 2 * time.Minute, Workers: 1, Duty: 1}}}},
+		{name: "a shape the run outlasts", cfg: Cfg{Workers: 2, Timeout: 5 * time.Minute, Shape: []Segment{{Length: 2 * time.Minute, Workers: 1, Duty: 1}}}, wantErr: "timeout 5m0s is longer than the shape's 2m0s, so the run would outlast it"},
+		{name: "a shape segment of no length", cfg: Cfg{Workers: 2, Shape: []Segment{{Length: time.Minute}, {Workers: 1, Duty: 1}}}, wantErr: "shape segment 2: length must be greater than 0"},
+		{name: "a shape segment of more workers than the run", cfg: Cfg{Workers: 2, Shape: []Segment{{Length: time.Minute, Workers: 3, Duty: 1}}}, wantErr: "shape segment 1: workers must be from 0 to the run's 2"},
+		{name: "a shape segment over a full duty", cfg: Cfg{Workers: 2, Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: 1.5}}}, wantErr: "shape segment 1: duty must be from 0 to 1"},
+		{name: "a shape segment of NaN duty", cfg: Cfg{Workers: 2, Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: math.NaN()}}}, wantErr: "shape segment 1: duty must be from 0 to 1"},
+		{name: "a shape reporting past its end", cfg: Cfg{Workers: 2, Report: 2 * time.Minute, Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: 1}}}, wantErr: "report 2m0s is longer than timeout 1m0s, so no progress would be reported"},
+		{name: "a shape at a target rate", cfg: Cfg{Workers: 2, TargetRate: 5, Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: 1}}}, wantErr: "target rate and shape both say when units start; give one of them"},
+		{name: "a shape in bursts", cfg: Cfg{Workers: 2, Burst: Burst{On: time.Second, Off: time.Second}, Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: 1}}}, wantErr: "burst and shape both say when the workers run; give one of them"},
+		{name: "chaos", cfg: Cfg{Workers: 2, Timeout: time.Minute, Chaos: Chaos{MinWorkers: 1, MaxWorkers: 2, MinDuty: 0.2, MaxDuty: 1, MinEvery: time.Second, MaxEvery: 5 * time.Second}}},
+		{name: "chaos of one worker and one duty", cfg: Cfg{Workers: 2, Timeout: time.Minute, Chaos: Chaos{MinWorkers: 2, MaxWorkers: 2, MinDuty: 0.5, MaxDuty: 0.5, MinEvery: time.Second, MaxEvery: time.Second}}},
+		{name: "chaos without a timeout", cfg: Cfg{Workers: 2, Chaos: Chaos{MinWorkers: 1, MaxWorkers: 2, MinDuty: 0.2, MaxDuty: 1, MinEvery: time.Second, MaxEvery: 5 * time.Second}}, wantErr: "chaos needs a timeout, for the changes it draws to have an end"},
+		{name: "chaos of more workers than the run", cfg: Cfg{Workers: 1, Timeout: time.Minute, Chaos: Chaos{MinWorkers: 1, MaxWorkers: 2, MinDuty: 0.2, MaxDuty: 1, MinEvery: time.Second, MaxEvery: 5 * time.Second}}, wantErr: "chaos workers must be from 0 to the run's 1, the fewer first"},
+		{name: "chaos workers the wrong way round", cfg: Cfg{Workers: 2, Timeout: time.Minute, Chaos: Chaos{MinWorkers: 2, MaxWorkers: 1, MaxDuty: 1, MinEvery: time.Second, MaxEvery: time.Second}}, wantErr: "chaos workers must be from 0 to the run's 2, the fewer first"},
+		{name: "chaos over a full duty", cfg: Cfg{Workers: 2, Timeout: time.Minute, Chaos: Chaos{MaxWorkers: 2, MaxDuty: 1.5, MinEvery: time.Second, MaxEvery: time.Second}}, wantErr: "chaos duty must be from 0 to 1, the lower first"},
+		{name: "chaos of NaN duty", cfg: Cfg{Workers: 2, Timeout: time.Minute, Chaos: Chaos{MaxWorkers: 2, MinDuty: math.NaN(), MaxDuty: 1, MinEvery: time.Second, MaxEvery: time.Second}}, wantErr: "chaos duty must be from 0 to 1, the lower first"},
+		// A Seed alone is a Chaos nobody gave bounds to, not no Chaos.
+		{name: "chaos of a seed alone", cfg: Cfg{Workers: 2, Timeout: time.Minute, Chaos: Chaos{Seed: 42}}, wantErr: "chaos every must be greater than 0, the shorter first"},
+		{name: "chaos changing too often", cfg: Cfg{Workers: 2, Timeout: 24 * time.Hour, Chaos: Chaos{MaxWorkers: 2, MaxDuty: 1, MinEvery: time.Millisecond, MaxEvery: time.Second}}, wantErr: "chaos every 1ms would change the load up to 86400000 times in timeout 24h0m0s; give a longer every or a shorter timeout"},
+		{name: "chaos at a target rate", cfg: Cfg{Workers: 2, Timeout: time.Minute, TargetRate: 5, Chaos: Chaos{MinWorkers: 1, MaxWorkers: 2, MinDuty: 0.2, MaxDuty: 1, MinEvery: time.Second, MaxEvery: 5 * time.Second}}, wantErr: "target rate and chaos both say when units start; give one of them"},
+		{name: "chaos in bursts", cfg: Cfg{Workers: 2, Timeout: time.Minute, Burst: Burst{On: time.Second, Off: time.Second}, Chaos: Chaos{MinWorkers: 1, MaxWorkers: 2, MinDuty: 0.2, MaxDuty: 1, MinEvery: time.Second, MaxEvery: 5 * time.Second}}, wantErr: "burst and chaos both say when the workers run; give one of them"},
+		{name: "chaos and a shape", cfg: Cfg{Workers: 2, Shape: []Segment{{Length: time.Minute, Workers: 1, Duty: 1}}, Chaos: Chaos{MinWorkers: 1, MaxWorkers: 2, MinDuty: 0.2, MaxDuty: 1, MinEvery: time.Second, MaxEvery: 5 * time.Second}}, wantErr: "shape and chaos both say how many workers run; give one of them"},
+		{name: "a count past the budget's ceiling", cfg: Cfg{Workers: 1, Count: math.MaxInt64 + 1}, wantErr: "count must be 9223372036854775807 or fewer"},
+		{name: "zero workers", cfg: Cfg{Workers: 0, Timeout: 0}, wantErr: "workers must be 1 or greater"},
+		{name: "negative workers", cfg: Cfg{Workers: -1, Timeout: 0}, wantErr: "workers must be 1 or greater"},
+		// #143: wg.Add(2^31) wrapped negative and the run panicked out at exit 2.
+		{name: "workers one past the WaitGroup ceiling", cfg: Cfg{Workers: math.MaxInt32 + 1, Timeout: 0}, wantErr: "workers must be 2147483647 or fewer"},
+		{name: "negative timeout", cfg: Cfg{Workers: 1, Timeout: -time.Second}, wantErr: "timeout must be 0 (indefinite) or greater"},
+		{name: "a stressor there is none of", cfg: Cfg{Workers: 1, Stressor: "disk"}, wantErr: "stressor must be one of bcrypt, contention, cache, gc, syscall"},
+		{name: "a mode the stressor does not have", cfg: Cfg{Workers: 1, Stressor: "contention", Modes: []string{"spinlock"}}, wantErr: `stressor contention has no mode "spinlock"; want one of atomic, unpadded, padded, mutex`},
+		// bcrypt has one way to run, so a mode for it is a mistake about which stressor is on.
+		{name: "a mode for bcrypt", cfg: Cfg{Workers: 1, Modes: []string{"atomic"}}, wantErr: "stressor bcrypt has no modes"},
+		{name: "cache, the auto tiers", cfg: Cfg{Workers: 1, Stressor: "cache"}},
+		{name: "cache, two working sets", cfg: Cfg{Workers: 1, Stressor: "cache", WorkingSets: []int{32 << 10, 64 << 20}}},
+		{name: "cache, the smallest ring", cfg: Cfg{Workers: 1, Stressor: "cache", WorkingSets: []int{minWorkingSet}}},
+		{name: "a working set too small to be a ring", cfg: Cfg{Workers: 1, Stressor: "cache", WorkingSets: []int{64}}, wantErr: "working set must be 128B or larger"},
+		{name: "a working set past what a ring can count", cfg: Cfg{Workers: 1, Stressor: "cache", WorkingSets: []int{512 << 30}}, wantErr: "working set must be 256GiB or smaller"},
+		{name: "a working set for bcrypt", cfg: Cfg{Workers: 1, WorkingSets: []int{32 << 10}}, wantErr: "stressor bcrypt takes no working set"},
+		{name: "a mode for cache", cfg: Cfg{Workers: 1, Stressor: "cache", Modes: []string{"atomic"}}, wantErr: "stressor cache has no modes"},
+		{name: "a report every second", cfg: Cfg{Workers: 1, Timeout: time.Minute, Report: time.Second}},
+		// The engine has no floor of its own: a caller's Observer may want reports faster than a person reads them.
+		{name: "a report faster than a person reads", cfg: Cfg{Workers: 1, Report: time.Millisecond}},
+		{name: "a negative report", cfg: Cfg{Workers: 1, Report: -time.Second}, wantErr: "report must be 0 (off) or greater"},
+		{name: "a report longer than the run", cfg: Cfg{Workers: 1, Timeout: time.Second, Report: time.Minute}, wantErr: "report 1m0s is longer than timeout 1s, so no progress would be reported"},
+		{name: "a latency probe", cfg: Cfg{Workers: 1, Timeout: time.Minute, LatencyProbe: time.Millisecond, LatencyLocked: true}},
+		{name: "a latency probe at the floor", cfg: Cfg{Workers: 1, LatencyProbe: LatencyFloor}},
+		{name: "a latency probe under the floor", cfg: Cfg{Workers: 1, LatencyProbe: time.Microsecond}, wantErr: "latency probe must be 0 (off) or 100us or greater"},
+		{name: "a latency probe longer than the run", cfg: Cfg{Workers: 1, Timeout: time.Second, LatencyProbe: time.Minute}, wantErr: "latency probe 1m0s is longer than timeout 1s, so no wakeup would be measured"},
+		{name: "a locked thread with no probe", cfg: Cfg{Workers: 1, LatencyLocked: true}, wantErr: "latency locked is set with no latency probe to lock"},
+		{name: "a max temp", cfg: Cfg{Workers: 1, MaxTemp: 90}},
+		{name: "a negative max temp", cfg: Cfg{Workers: 1, MaxTemp: -1}, wantErr: "max temp must be 0 (none) or greater"},
+		{name: "a max temp of NaN", cfg: Cfg{Workers: 1, MaxTemp: math.NaN()}, wantErr: "max temp must be 0 (none) or greater"},
+		{name: "a stall timeout", cfg: Cfg{Workers: 1, StallTimeout: 30 * time.Second}},
+		{name: "a negative stall timeout", cfg: Cfg{Workers: 1, StallTimeout: -time.Second}, wantErr: "stall timeout must be 0 (none) or greater"},
+		{name: "gc, a rate and a heap", cfg: Cfg{Workers: 1, Stressor: "gc", AllocRate: 256 << 20, HeapTarget: 1 << 30}},
+		{name: "a negative alloc rate", cfg: Cfg{Workers: 1, Stressor: "gc", AllocRate: -1}, wantErr: "alloc rate must be 0 (unlimited) or greater"},
+		{name: "a negative heap target", cfg: Cfg{Workers: 1, Stressor: "gc", HeapTarget: -1}, wantErr: "heap target must be 0 (64MiB) or greater"},
+		{name: "an alloc rate for bcrypt", cfg: Cfg{Workers: 1, AllocRate: 1 << 20}, wantErr: "stressor bcrypt takes no alloc rate"},
+		{name: "a heap target for cache", cfg: Cfg{Workers: 1, Stressor: "cache", HeapTarget: 1 << 20}, wantErr: "stressor cache takes no heap target"},
+		{name: "a mode for gc", cfg: Cfg{Workers: 1, Stressor: "gc", Modes: []string{"atomic"}}, wantErr: "stressor gc has no modes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWorkStopsWhenCancelled is #15: the check ran once every ~26 hours.
+func TestWorkStopsWhenCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		// ctx is cancelled before the worker reads it, or mid-hash.
+		ctx func(t *testing.T) context.Context
+		// wantNoHashes is set where the count is decided rather than raced.
+		wantNoHashes bool
+	}{
+		{
+			name: "cancelled before the worker starts",
+			ctx: func(t *testing.T) context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				t.Cleanup(cancel)
+				return ctx
+			},
+			wantNoHashes: true,
+		},
+		{
+			name: "cancelled while the worker is hashing",
+			ctx: func(t *testing.T) context.Context {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				t.Cleanup(cancel)
+				return ctx
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One writer, no reader until it returns, so the read below is safe.
+			var hashes atomic.Uint64
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+
+				work(tt.ctx(t), hash, &hashes)
+			}()
+
+			select {
+			case <-done:
+				if tt.wantNoHashes && hashes.Load() != 0 {
+					t.Errorf("work published %d, want 0 from an already-cancelled context", hashes.Load())
+				}
+			case <-time.After(stopBudget):
+				t.Fatalf("work did not return within %s of cancellation", stopBudget)
+			}
+		})
+	}
+}
+
+// TestWorkPublishesAsItGoes: the count has to be readable mid-run (#70).
+func TestWorkPublishesAsItGoes(t *testing.T) {
+	var hashes atomic.Uint64
+
+	// Cancelled on a count rather than a deadline, which would be a second budget.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		work(ctx, hash, &hashes)
+	}()
+
+	deadline := time.After(stopBudget)
+
+	for hashes.Load() == 0 {
+		select {
+		case <-done:
+			t.Fatal("work returned before it published a hash, so nothing can read its count mid-run (#70)")
+		case <-deadline:
+			t.Fatalf("work published no hash within %s while still running (#70)", stopBudget)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(stopBudget):
+		t.Fatalf("work did not return within %s of cancellation", stopBudget)
+	}
+}
+
+func TestRunContextRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []Cfg{{Workers: 0}, {Workers: 1, Timeout: -time.Second}} {
+		if _, err := cfg.RunContext(context.Background()); err == nil {
+			t.Errorf("RunContext() of %+v error = nil, want a validation error", cfg)
+		}
+	}
+}
+
+// TestRunContextStopsAtTimeout: the run waits out its Timeout, says so, and
+// hands back a Result whose figures add up.
+func TestRunContextStopsAtTimeout(t *testing.T) {
+	const timeout = 10 * time.Millisecond
+
+	r, err := Cfg{Workers: 2, Timeout: timeout}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	if r.Reason != StopTimeout {
+		t.Errorf("Reason = %s, want %s", r.Reason, StopTimeout)
+	}
+
+	if r.Elapsed < timeout {
+		t.Errorf("Elapsed = %s, want at least the %s timeout", r.Elapsed, timeout)
+	}
+
+	if len(r.Groups) != 1 || r.Groups[0].Stressor != "bcrypt" || r.Groups[0].Workers != 2 {
+		t.Fatalf("Groups = %+v, want the one bcrypt group of 2 workers", r.Groups)
+	}
+
+	if r.Groups[0].Count != r.Count || r.Groups[0].Variants != nil {
+		t.Errorf("Groups[0] = %+v, want the run's count of %d and no variants", r.Groups[0], r.Count)
+	}
+
+	if r.GC != nil || r.Latency != nil {
+		t.Errorf("GC = %v, Latency = %v, want neither from a run with no probe", r.GC, r.Latency)
+	}
+}
+
+// TestRunContextStopsOnCancel is the embedder's signal: a cancelled context
+// ends an indefinite run, which drains and reports why it stopped.
+func TestRunContextStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan Result, 1)
+	go func() {
+		r, err := Cfg{Workers: 1}.RunContext(ctx)
+		if err != nil {
+			t.Errorf("RunContext() error = %v, want nil", err)
+		}
+
+		done <- r
+	}()
+
+	select {
+	case r := <-done:
+		if r.Reason != StopCanceled {
+			t.Errorf("Reason = %s, want %s", r.Reason, StopCanceled)
+		}
+	case <-time.After(stopBudget):
+		t.Fatalf("RunContext() did not return within %s of cancellation", stopBudget)
+	}
+}
+
+// TestSnapshotWhileRunning reads a run mid-way, as the command's progress
+// lines do: still going, and its count never going backwards.
+func TestSnapshotWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	run, err := Cfg{Workers: 1, Timeout: time.Minute}.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+
+	first := run.Snapshot()
+	if first.Reason != StopNone {
+		t.Errorf("Snapshot().Reason = %s, want %s", first.Reason, StopNone)
+	}
+
+	select {
+	case <-run.Done():
+		t.Error("Done() closed on a run with a minute to go")
+	default:
+	}
+
+	if second := run.Snapshot(); second.Count < first.Count || second.Elapsed < first.Elapsed {
+		t.Errorf("Snapshot() went from %+v to %+v, want neither figure to go back", first, second)
+	}
+
+	cancel()
+
+	if r := run.Wait(); r.Reason != StopCanceled {
+		t.Errorf("Wait().Reason = %s, want %s", r.Reason, StopCanceled)
+	}
+}
+
+// TestRunContextIsReusable covers #14's third item: a second call panicked on a
+// channel.
+func TestRunContextIsReusable(t *testing.T) {
+	cfg := Cfg{Workers: 1, Timeout: time.Millisecond}
+
+	for i := range 2 {
+		if _, err := cfg.RunContext(context.Background()); err != nil {
+			t.Fatalf("RunContext() call %d error = %v, want nil", i+1, err)
+		}
+	}
+}
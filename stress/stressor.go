@@ -0,0 +1,393 @@
+package stress
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// stressor is one kind of load a run can put on the machine: bcrypt, the one
+// stressy has always had, and the ones added beside it for what bcrypt cannot
+// reach. Each is a row of a table, like the flags, so naming one on the command
+// line, validating it and reporting on it all read the same row.
+type stressor struct {
+	name string // what Cfg.Stressor takes, and the command's --stressor
+
+	// label is what the startup line calls the test. bcrypt's is "CPU", which
+	// is the line every run printed before there was anything else to call it.
+	label string
+
+	// unit and units are what the lines a run prints count in: "hash" and
+	// "hashes" for bcrypt, which is the wording README.md freezes for 1.x.
+	unit, units string
+
+	// step is what a worker is in the middle of when the run ends, and so what
+	// the shutdown line says the drain is waiting for.
+	step string
+
+	// kind is what the summary calls one variant of this stressor, and
+	// variants is every one it has, in the order a run cycles through them. A
+	// stressor with one way to run has neither, and prints no line per variant;
+	// it has start instead, which is what a variant's start is.
+	kind     string
+	variants []variant
+	start    func(workers int) func(id int) uint64
+
+	// sized, where set, is what makes the variants of this stressor sizes
+	// rather than modes: one variant per working set, built by this.
+	sized func(bytes int) variant
+
+	// tuned, where set, builds the stressor's one variant from settings of its
+	// own in the Cfg — AllocRate and HeapTarget, for gc — and stands in
+	// for start.
+	tuned func(c Cfg) variant
+
+	// latency adds what one unit cost a worker to each variant's line, for a
+	// stressor whose question is how long a thing takes rather than how many.
+	latency bool
+
+	// watch, where set, is what the run measures besides its own count: a
+	// probe started with the clock and stopped once the workers have drained,
+	// whose figures go in the Result beside the count.
+	watch func() probe
+}
+
+// probe is something a run measures other than the count of what it did — the
+// collector, for gc, or LatencyProbe's wakeups — read over the run and
+// summarised after it. start is called before the clock starts, and stop once
+// the last worker is done. read puts what the probe has measured into the
+// Result it is given: so far, where the run is still going, and over the whole
+// run once stop has returned.
+type probe interface {
+	start()
+	read(r *Result)
+	stop()
+}
+
+// variant is one way a stressor can run: a contention mode, say. A run with
+// several cycles through them, a phase at a time, and reports each on its own
+// line, because a figure for two of them added together measures neither.
+type variant struct {
+	name string
+
+	// start readies the variant for a run of workers and returns the unit each
+	// worker repeats until the phase ends. id is the worker's index, from 0 to
+	// workers-1, for a variant whose workers each keep something of their own.
+	// A unit returns how many of the stressor's units it did, which is one for
+	// a hash and a whole batch for a variant too cheap to count one at a time.
+	start func(workers int) func(id int) uint64
+
+	// open stands in for start for a variant that needs something from outside
+	// the process — a directory to open files in, pipes, a binary to run — and
+	// so can fail, and has something to give back once the run is over, which
+	// is what release is for. It is given the group's Cfg, for its Workers and
+	// whatever else the variant reads of it: Exec, for exec.
+	open func(c Cfg) (unit func(id int) uint64, release func(), err error)
+
+	// step, where set, is what a worker is in the middle of in this variant in
+	// place of the stressor's: exec's unit is one process, where the rest of
+	// the syscall modes' is a batch of calls.
+	step string
+}
+
+// begin readies the variant for a run of c's workers, through whichever of
+// start and open it has. release is never nil where err is.
+func (v variant) begin(c Cfg) (unit func(id int) uint64, release func(), err error) {
+	if v.open != nil {
+		return v.open(c)
+	}
+
+	return v.start(c.Workers), func() {}, nil
+}
+
+// stressors is every stressor a run can be given, in the order the --stressor
+// help names them. The first is what a Cfg with no Stressor runs.
+var stressors = []*stressor{bcryptStressor, contentionStressor, cacheStressor, gcStressor, syscallStressor}
+
+// Stressor describes one kind of load a Cfg can name, in the words the lines of
+// the stressy command are built from.
+type Stressor struct {
+	Name  string // what Cfg.Stressor and a Group take
+	Label string // what a test of it is called: "CPU", for bcrypt
+
+	// Unit and Units are what its counts count: "hash" and "hashes" for
+	// bcrypt. Step is what a worker is in the middle of when a run ends, and
+	// so what the drain waits for.
+	Unit, Units string
+	Step        string
+
+	// Kind is what one of its variants is called — "mode", "working set" — and
+	// "" for a stressor without variants. Modes is every mode it has, in the
+	// order a run takes them, for one whose variants are modes; WorkingSets is
+	// what a run walks where Cfg.WorkingSets is empty, for one whose variants
+	// are sizes.
+	Kind        string
+	Modes       []string
+	WorkingSets []int
+
+	// ModeSteps is the Step of each of Modes, in the same order: the
+	// stressor's, or where a mode's unit is something else, the mode's own,
+	// "exec" for syscall's exec.
+	ModeSteps []string
+
+	// Latency is whether what one unit costs a worker is the question the
+	// stressor asks, rather than how many units there were.
+	Latency bool
+}
+
+// Stressors describes every stressor a Cfg can name, in the order the stressy
+// command lists them. The first is what a Cfg with no Stressor runs.
+func Stressors() []Stressor {
+	described := make([]Stressor, len(stressors))
+
+	for i, s := range stressors {
+		described[i] = Stressor{
+			Name:    s.name,
+			Label:   s.label,
+			Unit:    s.unit,
+			Units:   s.units,
+			Step:    s.step,
+			Kind:    s.kind,
+			Latency: s.latency,
+		}
+
+		for _, v := range s.variants {
+			step := v.step
+			if step == "" {
+				step = s.step
+			}
+
+			described[i].Modes = append(described[i].Modes, v.name)
+			described[i].ModeSteps = append(described[i].ModeSteps, step)
+		}
+
+		if s.sized != nil {
+			described[i].WorkingSets = slices.Clone(cacheTiers)
+		}
+	}
+
+	return described
+}
+
+// lookupStressor returns the stressor called name, and the default for "".
+func lookupStressor(name string) (*stressor, bool) {
+	if name == "" {
+		return stressors[0], true
+	}
+
+	for _, s := range stressors {
+		if s.name == name {
+			return s, true
+		}
+	}
+
+	return nil, false
+}
+
+// stressorNames lists the stressors for a message or a usage text.
+func stressorNames() string {
+	names := make([]string, len(stressors))
+	for i, s := range stressors {
+		names[i] = s.name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// pick returns the variants a run of this stressor is given: every one it has
+// where modes is empty, and those named otherwise, in the order named. It is
+// where a mode the stressor does not have is caught. A stressor with no
+// variants is given one, unnamed, which is the stressor itself.
+func (s *stressor) pick(modes []string) ([]variant, error) {
+	if len(s.variants) == 0 {
+		if len(modes) > 0 {
+			return nil, fmt.Errorf("stressor %s has no modes", s.name)
+		}
+
+		return []variant{{start: s.start}}, nil
+	}
+
+	if len(modes) == 0 {
+		return s.variants, nil
+	}
+
+	picked := make([]variant, 0, len(modes))
+
+	for _, m := range modes {
+		i := s.variantIndex(m)
+		if i < 0 {
+			return nil, fmt.Errorf("stressor %s has no mode %q; want one of %s", s.name, m, s.variantNames())
+		}
+
+		picked = append(picked, s.variants[i])
+	}
+
+	return picked, nil
+}
+
+func (s *stressor) variantIndex(name string) int {
+	for i, v := range s.variants {
+		if v.name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *stressor) variantNames() string {
+	names := make([]string, len(s.variants))
+	for i, v := range s.variants {
+		names[i] = v.name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// phase is the longest a variant runs before the next takes over. Short, so the
+// variants of a run take turns rather than one having the first half of it and
+// another the second: a turbo clock that decays a minute in, or a neighbour that
+// arrives, lands on all of them alike, and the figures stay comparable.
+const phase = time.Second
+
+// tally is what a run has done so far, one count per variant it runs. A run of a
+// stressor with no variants still has one entry, standing for the stressor.
+type tally struct {
+	// counts is atomic because a Snapshot reads it mid-run, while every worker
+	// is still adding to it.
+	counts []atomic.Uint64
+
+	// spent is how long each variant has had the workers, in nanoseconds, over
+	// the phases it has finished. Written only by load, at the end of each
+	// phase; atomic for a Snapshot's reason.
+	spent []atomic.Int64
+}
+
+func newTally(n int) *tally {
+	return &tally{counts: make([]atomic.Uint64, n), spent: make([]atomic.Int64, n)}
+}
+
+// total is the count across every variant, which is what a group's count is.
+func (t *tally) total() uint64 {
+	var n uint64
+	for i := range t.counts {
+		n += t.counts[i].Load()
+	}
+
+	return n
+}
+
+// load runs the workers until ctx is done: one phase after another, each
+// starting every worker on one variant's unit and ending once all of them have
+// finished the unit they were on. A single unit is one phase as long as the run.
+//
+// It returns once the last phase has drained, which is what Wait waits on; the
+// time that drain takes is charged to the variant it belongs to. A worker goes
+// through g before every unit, and the time the run is paused in a phase is
+// charged to nobody.
+func load(ctx context.Context, workers int, units []func(id int) uint64, turn time.Duration, t *tally, b *busy, g *gate) {
+	for i := 0; ctx.Err() == nil; i = (i + 1) % len(units) {
+		run, cancel := ctx, context.CancelFunc(func() {})
+		if len(units) > 1 {
+			run, cancel = context.WithTimeout(ctx, turn)
+		}
+
+		began := time.Now()
+		held := g.paused.paused(began)
+
+		var wg sync.WaitGroup
+
+		wg.Add(workers)
+		for id := range workers {
+			go func() {
+				defer wg.Done()
+
+				// Start checked the OS would have it, so a refusal here is
+				// one nothing could have foreseen, and costs the priority
+				// rather than the run.
+				_ = g.priority.lock()
+
+				work(run, func() uint64 {
+					// A unit the run has no time for counts nothing.
+					if !g.enter(run, id) {
+						return 0
+					}
+
+					start := g.shape.clock()
+					b.enter(id)
+					n := units[i](id)
+					b.leave(id)
+					g.leave(id, start, n)
+
+					return n
+				}, &t.counts[i])
+			}()
+		}
+
+		wg.Wait()
+		cancel()
+
+		now := time.Now()
+		t.spent[i].Add(int64(now.Sub(began) - (g.paused.paused(now) - held)))
+	}
+}
+
+// gate is what stands between a worker and its next unit: every hold the run
+// has on its workers, and every limit on how many units they start. Each part
+// is nil, or never held, where the run has no such setting, so a steady run of
+// nothing but Workers goes straight through.
+type gate struct {
+	// paused is Pause's hold and off a Burst's off periods'.
+	paused, off *pause
+
+	// dispatch is TargetRate's schedule, budget the Count, and shape the
+	// Shape's workers and duty cycle.
+	dispatch *dispatcher
+	budget   *budget
+	shape    *shaper
+
+	// priority is Nice and Sched, which each worker gives its thread before
+	// its first unit.
+	priority priority
+}
+
+// enter blocks while worker id is held, and reports whether it may start a
+// unit; false is ctx done, or the Count handed out, and the worker's cue to
+// stop. Holds come before limits, so a worker held through a pause takes no
+// slot and claims nothing it then sits on.
+func (g *gate) enter(ctx context.Context, id int) bool {
+	if g.paused.wait(ctx) || g.off.wait(ctx) || !g.shape.take(ctx, id, g.paused) {
+		return false
+	}
+
+	return g.dispatch.take(ctx, g.paused) && g.budget.claim()
+}
+
+// leave is worker id's unit done: n units, started at start.
+func (g *gate) leave(id int, start time.Time, n uint64) {
+	g.budget.spend(n)
+	g.shape.done(id, start, n)
+}
+
+// work repeats unit until ctx is cancelled, counting what each call did into
+// done as it goes. That is where the whole of the load lives; what it is made
+// of is the stressor's.
+//
+// A worker returns one unit after ctx is done — one unit, not one unit's worth
+// of seconds. Past GOMAXPROCS workers that unit is sharing a core with the rest,
+// so the wall clock it takes stretches with how many there are, and the run does
+// not end until the last worker is through (#122).
+func work(ctx context.Context, unit func() uint64, done *atomic.Uint64) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			done.Add(unit())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package stress
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStressorsAreFullyDescribed holds every row of the table to the wording the
+// lines a run prints are built from: an empty unit or step prints a line with a
+// hole in it, and nothing else would notice.
+func TestStressorsAreFullyDescribed(t *testing.T) {
+	seen := map[string]bool{}
+
+	for _, s := range stressors {
+		if s.name == "" || s.label == "" || s.unit == "" || s.units == "" || s.step == "" {
+			t.Errorf("stressor %+v leaves part of its wording empty", s)
+		}
+
+		if seen[s.name] {
+			t.Errorf("two stressors are called %q, so --stressor can reach only one", s.name)
+		}
+
+		seen[s.name] = true
+
+		// One of the four: a start for the stressor, variants with their own, a
+		// sized to build them from --working-set, or a tuned to build its one
+		// from settings of its own.
+		ways := 0
+		for _, has := range []bool{s.start != nil, len(s.variants) > 0, s.sized != nil, s.tuned != nil} {
+			if has {
+				ways++
+			}
+		}
+
+		if ways != 1 {
+			t.Errorf("stressor %s has start %t, %d variants, sized %t and tuned %t, want exactly one of the four", s.name, s.start != nil, len(s.variants), s.sized != nil, s.tuned != nil)
+		}
+
+		for _, v := range s.variants {
+			if (v.start == nil) == (v.open == nil) {
+				t.Errorf("stressor %s mode %s has start %t and open %t, want exactly one of the two", s.name, v.name, v.start != nil, v.open != nil)
+			}
+		}
+
+		if (len(s.variants) > 0 || s.sized != nil) && s.kind == "" {
+			t.Errorf("stressor %s has variants and no kind to call them by in the summary", s.name)
+		}
+	}
+
+	// bcrypt is first, which is what makes it what a bare `stressy` runs.
+	if s, _ := lookupStressor(""); s != bcryptStressor {
+		t.Errorf("lookupStressor(\"\") = %s, want bcrypt", s.name)
+	}
+}
+
+// TestStressorsDescribesTheTable: what Stressors exports is the table, in its
+// order, with the variants a run of each takes by default.
+func TestStressorsDescribesTheTable(t *testing.T) {
+	all := Stressors()
+
+	if len(all) != len(stressors) {
+		t.Fatalf("Stressors() = %d stressors, want the table's %d", len(all), len(stressors))
+	}
+
+	for i, s := range all {
+		if s.Name != stressors[i].name || s.Unit != stressors[i].unit || s.Kind != stressors[i].kind {
+			t.Errorf("Stressors()[%d] = %+v, want the table's %s", i, s, stressors[i].name)
+		}
+	}
+
+	if got := Stressors()[1]; !slices.Equal(got.Modes, []string{"atomic", "unpadded", "padded", "mutex"}) {
+		t.Errorf("Stressors()[1].Modes = %q, want contention's four", got.Modes)
+	}
+
+	// A copy, so a caller appending to it cannot change what a run walks.
+	all[2].WorkingSets[0] = 1
+	if Stressors()[2].WorkingSets[0] == 1 {
+		t.Error("Stressors() handed out cacheTiers itself, want a copy")
+	}
+}
+
+// TestPick covers which variants a run gets from --mode.
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       *stressor
+		modes   []string
+		want    []string
+		wantErr string
+	}{
+		{name: "every mode by default", s: contentionStressor, want: []string{"atomic", "unpadded", "padded", "mutex"}},
+		{name: "in the order named", s: contentionStressor, modes: []string{"mutex", "atomic"}, want: []string{"mutex", "atomic"}},
+		{name: "a stressor with no modes is one unnamed variant", s: bcryptStressor, want: []string{""}},
+		{name: "a mode there is none of", s: contentionStressor, modes: []string{"atomic", "spin"}, wantErr: `no mode "spin"`},
+		{name: "a mode for a stressor without any", s: bcryptStressor, modes: []string{"atomic"}, wantErr: "has no modes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.pick(tt.modes)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("pick(%q) error = %v, want it to contain %q", tt.modes, err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("pick(%q) error = %v, want nil", tt.modes, err)
+			}
+
+			names := make([]string, len(got))
+			for i, v := range got {
+				names[i] = v.name
+			}
+
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("pick(%q) = %q, want %q", tt.modes, names, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadTakesTurns: with several units, every one gets phases of its own and
+// is charged the time it had, so the per-variant rates divide by the right thing.
+func TestLoadTakesTurns(t *testing.T) {
+	const turn = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*turn)
+	defer cancel()
+
+	// Each unit sleeps rather than spins, so a loaded runner still gets turns in.
+	units := []func(int) uint64{
+		func(int) uint64 { time.Sleep(time.Millisecond); return 1 },
+		func(int) uint64 { time.Sleep(time.Millisecond); return 2 },
+	}
+
+	done := newTally(len(units))
+
+	start := time.Now()
+	load(ctx, 2, units, turn, done, nil, &gate{paused: &pause{}, off: &pause{}})
+	elapsed := time.Since(start)
+
+	spent := make([]time.Duration, len(units))
+
+	for i := range units {
+		if done.counts[i].Load() == 0 {
+			t.Errorf("unit %d did nothing over %s of %s turns, want it to have had some", i, elapsed, turn)
+		}
+
+		spent[i] = time.Duration(done.spent[i].Load())
+
+		if spent[i] <= 0 || spent[i] > elapsed {
+			t.Errorf("unit %d was charged %s of a %s run, want a share of it", i, spent[i], elapsed)
+		}
+	}
+
+	if got := spent[0] + spent[1]; got > elapsed {
+		t.Errorf("the units were charged %s between them, want no more than the %s load ran for", got, elapsed)
+	}
+
+	if got, want := done.total(), done.counts[0].Load()+done.counts[1].Load(); got != want {
+		t.Errorf("total() = %d, want the sum of the counts, %d", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package stress
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// The batches the syscall modes count in, one per mode because the calls cost
+// anything from a hundred nanoseconds to a millisecond: each is sized to keep a
+// unit well under a millisecond, so a worker notices the end of a phase about
+// as soon as one on any other stressor does. exec is the exception, being a
+// millisecond a call on its own.
+const (
+	cheapBatch = 1 << 10
+	openBatch  = 1 << 7
+	pipeBatch  = 1 << 6
+)
+
+// syscallStressor makes the calls into the kernel that bcrypt never does, where
+// what a seccomp filter, the audit subsystem or a Spectre mitigation costs is
+// paid, and paid on every one. Each mode is a different depth of that: a call
+// that does nothing past the entry, a path lookup, two context switches, and a
+// whole process. bcrypt on the same node is the baseline the modes are
+// compared against, user space with none of it.
+var syscallStressor = &stressor{
+	name:     "syscall",
+	label:    "syscall",
+	unit:     "call",
+	units:    "calls",
+	step:     "batch",
+	kind:     "mode",
+	variants: syscallModes(),
+}
+
+// syscallModes is every syscall mode this platform has. clock_gettime is a
+// system call only where haveClockGettime says the build makes it one; left
+// out elsewhere, Modes says so by name rather than timing something else.
+func syscallModes() []variant {
+	modes := []variant{{name: "getpid", start: startGetpid}}
+
+	if haveClockGettime {
+		modes = append(modes, variant{name: "clock_gettime", start: startClockGettime})
+	}
+
+	return append(modes,
+		variant{name: "open", open: openOpen},
+		variant{name: "pipe", open: openPipe},
+		variant{name: "exec", open: openExec, step: "exec"},
+	)
+}
+
+// startGetpid calls getpid, which does nothing in the kernel but return: what
+// it costs is the entry and the exit, and whatever filters them.
+func startGetpid(int) func(int) uint64 {
+	return func(int) uint64 {
+		for range cheapBatch {
+			syscall.Getpid()
+		}
+
+		return cheapBatch
+	}
+}
+
+// startClockGettime makes clock_gettime a system call, where time.Now answers
+// it from the vDSO without entering the kernel at all. The gap between it and
+// getpid is a call that reads a clock against one that reads a field.
+func startClockGettime(int) func(int) uint64 {
+	return func(int) uint64 {
+		for range cheapBatch {
+			clockGettime()
+		}
+
+		return cheapBatch
+	}
+}
+
+// openOpen readies a file in a directory of its own under os.TempDir, which
+// every worker opens and closes again: a path walk, a descriptor, and giving it
+// back. syscall rather than os, whose Open also registers the descriptor with
+// the poller and so makes three calls of one.
+func openOpen(Cfg) (func(int) uint64, func(), error) {
+	dir, err := os.MkdirTemp("", "stressy-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path := filepath.Join(dir, "open")
+
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, nil, err
+	}
+
+	unit := func(int) uint64 {
+		var n uint64
+
+		for range openBatch {
+			// The file is this run's and nothing else touches it, so a failure
+			// is the process out of descriptors, which is load too: it is left
+			// out of the count rather than ending the run.
+			fd, err := syscall.Open(path, syscall.O_RDONLY, 0)
+			if err != nil {
+				continue
+			}
+
+			_ = syscall.Close(fd)
+			n++
+		}
+
+		return n
+	}
+
+	return unit, func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// pipePair is one worker's half of a pipe round trip: it writes a byte to ping
+// and waits to read it back off pong, which a goroutine of its own echoes.
+type pipePair struct {
+	ping, pong *os.File
+}
+
+// openPipe gives every worker a pair of pipes and an echo at the other end. A
+// round trip is a call: two writes and two reads, and a goroutine parked and
+// woken on either side, which is what handing work to another thread through
+// the kernel costs.
+func openPipe(c Cfg) (func(int) uint64, func(), error) {
+	pairs := make([]pipePair, c.Workers)
+
+	var opened []*os.File
+
+	release := func() {
+		for _, f := range opened {
+			_ = f.Close()
+		}
+	}
+
+	for id := range pairs {
+		pingR, pingW, err := os.Pipe()
+		if err != nil {
+			release()
+
+			return nil, nil, err
+		}
+
+		pongR, pongW, err := os.Pipe()
+		if err != nil {
+			_ = pingR.Close()
+			_ = pingW.Close()
+			release()
+
+			return nil, nil, err
+		}
+
+		opened = append(opened, pingR, pingW, pongR, pongW)
+		pairs[id] = pipePair{ping: pingW, pong: pongR}
+
+		// Returns once release closes the pipes under it.
+		go func() {
+			b := make([]byte, 1)
+			for {
+				if _, err := pingR.Read(b); err != nil {
+					return
+				}
+
+				if _, err := pongW.Write(b); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	unit := func(id int) uint64 {
+		p := pairs[id]
+		b := make([]byte, 1)
+
+		for range pipeBatch {
+			// Nothing closes a pipe before the last worker has drained, so a
+			// failure here is one no count survives.
+			if _, err := p.ping.Write(b); err != nil {
+				panic(err)
+			}
+
+			if _, err := p.pong.Read(b); err != nil {
+				panic(err)
+			}
+		}
+
+		return pipeBatch
+	}
+
+	return unit, release, nil
+}
+
+// openExec runs c.Exec, or true(1) from the PATH where it is empty, once a
+// call: a fork, an exec, a process starting up and a wait for it to exit, which
+// is what a service that shells out pays every time. It is run once up front,
+// so a command that cannot start at all fails the run rather than counting
+// nothing. Past that, a call that fails — a pod at its pids limit refuses the
+// fork — is left out of the count, so the rate shows the limit rather than the
+// run dying of it.
+func openExec(c Cfg) (func(int) uint64, func(), error) {
+	argv := c.Exec
+	if len(argv) == 0 {
+		argv = []string{"true"}
+	}
+
+	run := func() error {
+		return exec.Command(argv[0], argv[1:]...).Run()
+	}
+
+	if err := run(); err != nil {
+		return nil, nil, err
+	}
+
+	unit := func(int) uint64 {
+		if err := run(); err != nil {
+			return 0
+		}
+
+		return 1
+	}
+
+	return unit, func() {}, nil
+}
@@ -0,0 +1,19 @@
+//go:build linux || freebsd
+
+package stress
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// haveClockGettime is true where clock_gettime has a system call number the
+// syscall package knows, and so can be made without the vDSO in the way.
+const haveClockGettime = true
+
+// clockGettime reads CLOCK_REALTIME, which is 0 on both, through the kernel.
+func clockGettime() {
+	var ts syscall.Timespec
+
+	_, _, _ = syscall.Syscall(syscall.SYS_CLOCK_GETTIME, 0, uintptr(unsafe.Pointer(&ts)), 0)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || freebsd)
+
+package stress
+
+// haveClockGettime is false where clock_gettime is not a system call this
+// build can make: macOS answers it in libc, and Windows has no such call.
+const haveClockGettime = false
+
+// clockGettime is never called here; the mode that would is left out.
+func clockGettime() {}
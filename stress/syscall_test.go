@@ -0,0 +1,124 @@
+package stress
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testExec is the Exec the tests give the exec mode: this test binary, running
+// no test, which starts and exits on every platform the tests run on, where
+// true(1) is not to be had on Windows.
+var testExec = []string{os.Args[0], "-test.run=^$"}
+
+// TestSyscallUnitsCountTheirCalls holds every mode to a count it did, through
+// begin and release as Run takes them: a mode that came up short would be one
+// whose calls were failing.
+func TestSyscallUnitsCountTheirCalls(t *testing.T) {
+	const workers = 2
+
+	want := map[string]uint64{
+		"getpid":        cheapBatch,
+		"clock_gettime": cheapBatch,
+		"open":          openBatch,
+		"pipe":          pipeBatch,
+		"exec":          1,
+	}
+
+	for _, v := range syscallStressor.variants {
+		t.Run(v.name, func(t *testing.T) {
+			unit, release, err := v.begin(Cfg{Workers: workers, Exec: testExec})
+			if err != nil {
+				t.Fatalf("begin() of %d workers error = %v, want nil", workers, err)
+			}
+
+			defer release()
+
+			for id := range workers {
+				if got := unit(id); got != want[v.name] {
+					t.Errorf("%s unit for worker %d = %d, want %d", v.name, id, got, want[v.name])
+				}
+			}
+		})
+	}
+}
+
+// TestClockGettimeIsAModeWhereItIsACall: where the build cannot make the call,
+// the mode is missing rather than timing the vDSO under its name.
+func TestClockGettimeIsAModeWhereItIsACall(t *testing.T) {
+	want := runtime.GOOS == "linux" || runtime.GOOS == "freebsd"
+
+	if got := syscallStressor.variantIndex("clock_gettime") >= 0; got != want {
+		t.Errorf("clock_gettime is a mode on %s: %t, want %t", runtime.GOOS, got, want)
+	}
+}
+
+// TestRunContextReportsEverySyscallMode runs the modes, exec included, which is
+// this test binary starting up and exiting again, as testExec has it.
+func TestRunContextReportsEverySyscallMode(t *testing.T) {
+	r, err := Cfg{Workers: 2, Timeout: 500 * time.Millisecond, Stressor: "syscall", Exec: testExec}.RunContext(context.Background())
+	if err != nil {
+		t.Fatalf("RunContext() error = %v, want nil", err)
+	}
+
+	variants := r.Groups[0].Variants
+
+	if len(variants) != len(syscallStressor.variants) {
+		t.Fatalf("Variants = %+v, want one for each of the %d modes", variants, len(syscallStressor.variants))
+	}
+
+	for i, v := range syscallStressor.variants {
+		if variants[i].Name != v.name {
+			t.Errorf("Variants[%d] = %s, want %s", i, variants[i].Name, v.name)
+		}
+	}
+}
+
+// TestRunContextFailsWhereAModeCannotStart is a FROM scratch image, which has no
+// /tmp to open files in: the run is refused before it starts rather than
+// measuring nothing under the open mode's name.
+func TestRunContextFailsWhereAModeCannotStart(t *testing.T) {
+	missing := t.TempDir() + "/missing"
+	t.Setenv("TMPDIR", missing)
+	t.Setenv("TMP", missing)
+
+	_, err := Cfg{Workers: 1, Timeout: time.Second, Stressor: "syscall", Modes: []string{"open"}}.RunContext(context.Background())
+
+	if err == nil || !strings.HasPrefix(err.Error(), "syscall mode open: ") {
+		t.Errorf("RunContext() error = %v, want it to name the syscall mode open", err)
+	}
+}
+
+// TestRunContextFailsWhereExecCannotStart: an Exec that is not there fails the
+// run up front rather than counting nothing under the exec mode's name.
+func TestRunContextFailsWhereExecCannotStart(t *testing.T) {
+	cfg := Cfg{Workers: 1, Timeout: time.Second, Stressor: "syscall", Modes: []string{"exec"}, Exec: []string{t.TempDir() + "/missing"}}
+
+	if _, err := cfg.RunContext(context.Background()); err == nil || !strings.HasPrefix(err.Error(), "syscall mode exec: ") {
+		t.Errorf("RunContext() error = %v, want it to name the syscall mode exec", err)
+	}
+}
+
+// TestExecIsAStepOfItsOwn: one exec unit is a whole process, not a batch, and
+// the drain is described as waiting on one.
+func TestExecIsAStepOfItsOwn(t *testing.T) {
+	for _, s := range Stressors() {
+		if s.Name != "syscall" {
+			continue
+		}
+
+		for i, mode := range s.Modes {
+			want := "batch"
+			if mode == "exec" {
+				want = "exec"
+			}
+
+			if s.ModeSteps[i] != want {
+				t.Errorf("the step of syscall mode %s = %q, want %q", mode, s.ModeSteps[i], want)
+			}
+		}
+	}
+}
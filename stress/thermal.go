@@ -0,0 +1,224 @@
+package stress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tempEvery is how often a run with a MaxTemp reads its thermal zones: often
+// enough that a fan that stops is caught a second or two past the limit, and
+// seldom enough that reading them is nothing against the load.
+const tempEvery = time.Second
+
+// TempError is the cause of a run its MaxTemp ended: the zone that reached it
+// and how hot it was then.
+type TempError struct {
+	Zone string // the zone's directory, and its type where it has one: "thermal_zone0 (x86_pkg_temp)"
+	Temp float64
+	Max  float64
+}
+
+func (e *TempError) Error() string {
+	return fmt.Sprintf("%s at %.1fC, at or past the max temp of %gC", e.Zone, e.Temp, e.Max)
+}
+
+// ThermalStats is what the machine's CPUs ran at and how hot it got, sampled
+// at the start of a run, at every read while it goes and at its end, which for
+// a run an Observer watches is every progress report. A frequency that falls
+// as the temperature climbs is a CPU throttling itself to keep cool, and a
+// rate that falls with it is that, not the load.
+//
+// Freq is every CPU's frequency over the samples, and Temp the hottest any
+// thermal zone was at any of them, in degrees Celsius. NowFreq and NowTemp are
+// the same at the last sample that had them. CPUs is how many CPUs had a
+// frequency to read, and Zones how many thermal zones a temperature, either 0
+// where the machine has none, and its figures with it.
+type ThermalStats struct {
+	Samples     int
+	CPUs, Zones int
+
+	Freq, NowFreq FreqStats
+	Temp, NowTemp float64
+}
+
+// FreqStats is CPU frequencies in MHz: the lowest, the average and the highest.
+// Over a run, Avg is the average of every sample's.
+type FreqStats struct {
+	Min, Avg, Max float64
+}
+
+// thermalProbe samples cpufreq and the thermal zones under /sys at every read.
+// It is every run's on a machine with either.
+type thermalProbe struct {
+	cpus, zones []string
+
+	// freqs is the samples that had a frequency, and sum their averages
+	// added up.
+	mu      sync.Mutex
+	stats   ThermalStats
+	freqs   int
+	sum     float64
+	stopped bool
+}
+
+// newThermalProbe is the probe of the CPUs' frequencies and the thermal zones
+// under root, or nil where there are neither: a VM, mostly, whose CPUs are the
+// host's to clock.
+func newThermalProbe(root string) probe {
+	cpus, _ := filepath.Glob(filepath.Join(root, "sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_cur_freq"))
+	zones := thermalZones(root)
+
+	if len(cpus) == 0 && len(zones) == 0 {
+		return nil
+	}
+
+	return &thermalProbe{cpus: cpus, zones: zones}
+}
+
+func (p *thermalProbe) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sample()
+}
+
+func (p *thermalProbe) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sample()
+	p.stopped = true
+}
+
+func (p *thermalProbe) read(r *Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.stopped {
+		p.sample()
+	}
+
+	s := p.stats
+	if p.freqs > 0 {
+		s.Freq.Avg = p.sum / float64(p.freqs)
+	}
+
+	r.Thermal = &s
+}
+
+// sample reads every CPU's frequency and every zone's temperature once, and
+// folds them into the run's. A file that cannot be read this time, as a CPU
+// gone offline's cannot, is left out of this sample alone.
+func (p *thermalProbe) sample() {
+	freq, cpus := FreqStats{Min: math.Inf(1), Max: math.Inf(-1)}, 0
+
+	for _, path := range p.cpus {
+		// In kHz.
+		khz, ok := readSysfsNumber(path)
+		if !ok {
+			continue
+		}
+
+		mhz := khz / 1000
+		freq.Min, freq.Max, freq.Avg = min(freq.Min, mhz), max(freq.Max, mhz), freq.Avg+mhz
+		cpus++
+	}
+
+	temp, zones := math.Inf(-1), 0
+
+	for _, path := range p.zones {
+		// In millidegrees.
+		milli, ok := readSysfsNumber(path)
+		if !ok {
+			continue
+		}
+
+		temp = max(temp, milli/1000)
+		zones++
+	}
+
+	s := &p.stats
+	s.Samples++
+
+	if cpus > 0 {
+		freq.Avg /= float64(cpus)
+
+		if p.freqs == 0 {
+			s.Freq = freq
+		}
+
+		s.Freq.Min, s.Freq.Max = min(s.Freq.Min, freq.Min), max(s.Freq.Max, freq.Max)
+		s.CPUs, s.NowFreq = max(s.CPUs, cpus), freq
+
+		p.freqs++
+		p.sum += freq.Avg
+	}
+
+	if zones > 0 {
+		if s.Zones == 0 {
+			s.Temp = temp
+		}
+
+		s.Temp = max(s.Temp, temp)
+		s.Zones, s.NowTemp = max(s.Zones, zones), temp
+	}
+}
+
+// thermalZones is the temp file of every thermal zone under root.
+func thermalZones(root string) []string {
+	zones, _ := filepath.Glob(filepath.Join(root, "sys/class/thermal/thermal_zone*/temp"))
+
+	return zones
+}
+
+// watchTemp reads zones every tempEvery until ctx is done, and calls stop with
+// a *TempError for the first zone it finds at limit or past it. A zone that
+// cannot be read is passed over that time, and nothing else: the run is not
+// ended for a sensor that hiccups.
+func watchTemp(ctx context.Context, zones []string, limit float64, stop func(error)) {
+	tick := time.NewTicker(tempEvery)
+	defer tick.Stop()
+
+	for {
+		for _, path := range zones {
+			milli, ok := readSysfsNumber(path)
+			if !ok || milli/1000 < limit {
+				continue
+			}
+
+			zone := filepath.Base(filepath.Dir(path))
+			if b, err := os.ReadFile(filepath.Join(filepath.Dir(path), "type")); err == nil {
+				zone += " (" + strings.TrimSpace(string(b)) + ")"
+			}
+
+			stop(&TempError{Zone: zone, Temp: milli / 1000, Max: limit})
+
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// readSysfsNumber reads a sysfs file that holds one number.
+func readSysfsNumber(path string) (float64, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+
+	return n, err == nil
+}
@@ -0,0 +1,153 @@
+package stress
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// thermalTree is the files of a machine with two CPUs and two thermal zones,
+// at the frequencies, in kHz, and temperatures, in millidegrees, given.
+func thermalTree(cpu0, cpu1, zone0, zone1 string) map[string]string {
+	return map[string]string{
+		"sys/devices/system/cpu/cpu0/cpufreq/scaling_cur_freq": cpu0 + "\n",
+		"sys/devices/system/cpu/cpu1/cpufreq/scaling_cur_freq": cpu1 + "\n",
+		"sys/class/thermal/thermal_zone0/temp":                 zone0 + "\n",
+		"sys/class/thermal/thermal_zone1/temp":                 zone1 + "\n",
+	}
+}
+
+// TestThermalProbeRead covers the samples a run takes: the run's lowest and
+// highest frequency and hottest zone, the average of every sample's average
+// frequency, the last sample beside them, and nothing read after stop.
+func TestThermalProbeRead(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, thermalTree("3400000", "3000000", "55000", "41000"))
+
+	p := newThermalProbe(root)
+	if p == nil {
+		t.Fatal("newThermalProbe() = nil, want the probe of the tree's CPUs and zones")
+	}
+
+	p.start()
+
+	// Hotter, and clocked down for it.
+	writeTree(t, root, thermalTree("2200000", "1800000", "88500", "43000"))
+
+	var r Result
+
+	p.read(&r)
+
+	want := ThermalStats{
+		Samples: 2, CPUs: 2, Zones: 2,
+		Freq:    FreqStats{Min: 1800, Avg: 2600, Max: 3400},
+		NowFreq: FreqStats{Min: 1800, Avg: 2000, Max: 2200},
+		Temp:    88.5, NowTemp: 88.5,
+	}
+	if r.Thermal == nil || *r.Thermal != want {
+		t.Errorf("read() = %+v, want %+v", r.Thermal, want)
+	}
+
+	// A CPU gone offline is left out of the samples it cannot be read in.
+	writeTree(t, root, thermalTree("2600000", "", "70000", "40000"))
+	p.stop()
+
+	writeTree(t, root, thermalTree("100000", "100000", "99000", "99000"))
+	p.read(&r)
+
+	want = ThermalStats{
+		Samples: 3, CPUs: 2, Zones: 2,
+		Freq:    FreqStats{Min: 1800, Avg: 2600, Max: 3400},
+		NowFreq: FreqStats{Min: 2600, Avg: 2600, Max: 2600},
+		Temp:    88.5, NowTemp: 70,
+	}
+	if *r.Thermal != want {
+		t.Errorf("read() after stop = %+v, want %+v", *r.Thermal, want)
+	}
+}
+
+// TestThermalProbeReadsWhatThereIs covers a machine with one of the two: the
+// other's figures are left at 0, with its count.
+func TestThermalProbeReadsWhatThereIs(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"sys/class/thermal/thermal_zone0/temp": "-5000\n"})
+
+	p := newThermalProbe(root)
+	if p == nil {
+		t.Fatal("newThermalProbe() = nil, want the probe of the tree's zone")
+	}
+
+	p.start()
+	p.stop()
+
+	var r Result
+
+	p.read(&r)
+
+	if want := (ThermalStats{Samples: 2, Zones: 1, Temp: -5, NowTemp: -5}); *r.Thermal != want {
+		t.Errorf("read() = %+v, want %+v", *r.Thermal, want)
+	}
+
+	if newThermalProbe(t.TempDir()) != nil {
+		t.Error("newThermalProbe() of a tree with neither is a probe, want nil")
+	}
+}
+
+// TestMaxTempEndsTheRun covers a run with a MaxTemp: a zone already past it
+// ends the run at once, one that reaches it later ends it at the next read,
+// and the shutdown names the zone.
+func TestMaxTempEndsTheRun(t *testing.T) {
+	for _, already := range []bool{true, false} {
+		root := t.TempDir()
+
+		zone := map[string]string{
+			"sys/class/thermal/thermal_zone0/temp": "40000\n",
+			"sys/class/thermal/thermal_zone1/temp": "45000\n",
+			"sys/class/thermal/thermal_zone1/type": "x86_pkg_temp\n",
+		}
+
+		hot := map[string]string{"sys/class/thermal/thermal_zone1/temp": "91500\n"}
+
+		writeTree(t, root, zone)
+
+		if already {
+			writeTree(t, root, hot)
+		}
+
+		obs := &recorder{}
+
+		run, err := Cfg{Workers: 1, Timeout: time.Minute, Stressor: "contention", Modes: []string{"atomic"}, MaxTemp: 90, Root: root, Observer: obs}.Start(context.Background())
+		if err != nil {
+			t.Fatalf("Start() error = %v, want nil", err)
+		}
+
+		if !already {
+			time.Sleep(100 * time.Millisecond)
+			writeTree(t, root, hot)
+		}
+
+		r := run.Wait()
+
+		if r.Reason != StopTemp || r.Elapsed > 10*tempEvery {
+			t.Errorf("Reason, Elapsed = %s, %s; want the temperature to end the run within a read or two", r.Reason, r.Elapsed)
+		}
+
+		var hotErr *TempError
+		if !errors.As(obs.shutdown.Cause, &hotErr) || *hotErr != (TempError{Zone: "thermal_zone1 (x86_pkg_temp)", Temp: 91.5, Max: 90}) {
+			t.Errorf("OnShutdown() cause = %v, want thermal_zone1's TempError", obs.shutdown.Cause)
+		}
+	}
+}
+
+// TestMaxTempNeedsAZone: a limit on a machine with nothing to read it from
+// fails the run before it starts, rather than promising what it cannot keep.
+func TestMaxTempNeedsAZone(t *testing.T) {
+	root := t.TempDir()
+
+	_, err := Cfg{Workers: 1, Timeout: time.Second, MaxTemp: 90, Root: root}.RunContext(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "max temp has no thermal zone to read under") {
+		t.Errorf("RunContext() error = %v, want one saying there is no zone", err)
+	}
+}